@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"errors"
+	"land/dao/mysql"
+	"land/logic"
+	"land/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+// @Summary 访问令牌列表
+// @Description 列出当前用户的个人访问令牌，包括权限范围、过期时间与最近使用情况
+// @Tags 访问令牌
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户token"
+// @Success 200 {object} controllers.RespData "访问令牌列表"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/tokens [get]
+func AccessTokenListHandler(c *gin.Context) {
+	userID, err := GetCurrentUserID(c)
+	if err != nil {
+		ResError(c, CodeNeedLogin)
+		return
+	}
+
+	tokens, err := logic.GetAccessTokenList(userID)
+	if err != nil {
+		zap.L().Error("logic.GetAccessTokenList() failed", zap.Error(err))
+		ResError(c, CodeServerBusy)
+		return
+	}
+	ResSuccess(c, tokens)
+}
+
+// @Summary 创建访问令牌
+// @Description 创建个人访问令牌，供脚本和集成以 Authorization: Bearer land_pat_... 调用API；令牌明文只返回这一次
+// @Tags 访问令牌
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户token"
+// @Param data body models.ParamCreateAccessToken true "名称、权限范围与有效天数"
+// @Success 200 {object} controllers.RespData "令牌信息与明文"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/tokens [post]
+func CreateAccessTokenHandler(c *gin.Context) {
+	userID, err := GetCurrentUserID(c)
+	if err != nil {
+		ResError(c, CodeNeedLogin)
+		return
+	}
+
+	p := new(models.ParamCreateAccessToken)
+	if err := c.ShouldBindJSON(p); err != nil {
+		zap.L().Error("创建访问令牌参数无效", zap.Error(err))
+		errs, ok := err.(validator.ValidationErrors)
+		if !ok {
+			ResError(c, CodeInvalidParams)
+			return
+		}
+		ResErrorWithMsg(c, CodeInvalidParams, removeStructName(errs.Translate(trans)))
+		return
+	}
+
+	token, raw, err := logic.CreateAccessToken(userID, p)
+	if err != nil {
+		zap.L().Error("logic.CreateAccessToken() failed", zap.Error(err))
+		switch {
+		case errors.Is(err, logic.ErrorAccessTokenLimit):
+			ResErrorWithMsg(c, CodeInvalidParams, err.Error())
+		case errors.Is(err, logic.ErrorScopeNotAllowed):
+			ResError(c, CodeUnauthorized)
+		default:
+			ResError(c, CodeServerBusy)
+		}
+		return
+	}
+	ResSuccess(c, gin.H{
+		"token":      raw,
+		"token_info": token,
+	})
+}
+
+// @Summary 吊销访问令牌
+// @Description 吊销当前用户的指定个人访问令牌，立即失效
+// @Tags 访问令牌
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户token"
+// @Param id path string true "令牌ID"
+// @Success 200 {object} controllers.RespData "吊销成功"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/tokens/{id} [delete]
+func RevokeAccessTokenHandler(c *gin.Context) {
+	userID, err := GetCurrentUserID(c)
+	if err != nil {
+		ResError(c, CodeNeedLogin)
+		return
+	}
+
+	tokenID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		ResError(c, CodeInvalidParams)
+		return
+	}
+
+	if err = logic.RevokeAccessToken(userID, tokenID); err != nil {
+		zap.L().Error("logic.RevokeAccessToken() failed", zap.Error(err))
+		if errors.Is(err, mysql.ErrorAccessTokenNotExist) {
+			ResError(c, CodeNotFound)
+			return
+		}
+		ResError(c, CodeServerBusy)
+		return
+	}
+	ResSuccess(c, nil)
+}
@@ -0,0 +1,161 @@
+package controllers
+
+import (
+	"errors"
+	"land/logic"
+	"land/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// @Summary 拉黑用户
+// @Description 拉黑后对方不能评论你的帖子、回复你的评论或@你，双方关注关系解除，对方的内容也不再出现在你的列表中
+// @Tags 拉黑屏蔽
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户token"
+// @Param id path int true "用户ID"
+// @Success 200 {object} controllers.RespData "拉黑成功"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/users/{id}/block [post]
+func BlockHandler(c *gin.Context) {
+	setUserBlock(c, models.RelationBlock)
+}
+
+// @Summary 解除拉黑
+// @Description 解除拉黑，之前解除的关注关系不会恢复
+// @Tags 拉黑屏蔽
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户token"
+// @Param id path int true "用户ID"
+// @Success 200 {object} controllers.RespData "解除成功"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/users/{id}/block [delete]
+func UnblockHandler(c *gin.Context) {
+	unsetUserBlock(c, models.RelationBlock)
+}
+
+// @Summary 屏蔽用户
+// @Description 屏蔽后不再看到对方的帖子和评论，对方不受影响
+// @Tags 拉黑屏蔽
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户token"
+// @Param id path int true "用户ID"
+// @Success 200 {object} controllers.RespData "屏蔽成功"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/users/{id}/mute [post]
+func MuteHandler(c *gin.Context) {
+	setUserBlock(c, models.RelationMute)
+}
+
+// @Summary 解除屏蔽
+// @Description 解除对指定用户的屏蔽
+// @Tags 拉黑屏蔽
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户token"
+// @Param id path int true "用户ID"
+// @Success 200 {object} controllers.RespData "解除成功"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/users/{id}/mute [delete]
+func UnmuteHandler(c *gin.Context) {
+	unsetUserBlock(c, models.RelationMute)
+}
+
+// @Summary 拉黑列表
+// @Description 分页查看自己拉黑的用户
+// @Tags 拉黑屏蔽
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户token"
+// @Param page query int false "页码，默认为1"
+// @Param size query int false "每页大小，默认为50，最大100"
+// @Success 200 {object} controllers.RespData "用户列表"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/users/me/blocks [get]
+func BlockListHandler(c *gin.Context) {
+	userBlockList(c, models.RelationBlock)
+}
+
+// @Summary 屏蔽列表
+// @Description 分页查看自己屏蔽的用户
+// @Tags 拉黑屏蔽
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户token"
+// @Param page query int false "页码，默认为1"
+// @Param size query int false "每页大小，默认为50，最大100"
+// @Success 200 {object} controllers.RespData "用户列表"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/users/me/mutes [get]
+func MuteListHandler(c *gin.Context) {
+	userBlockList(c, models.RelationMute)
+}
+
+// setUserBlock 拉黑或屏蔽路径中的用户
+func setUserBlock(c *gin.Context, kind uint8) {
+	userID, err := GetCurrentUserID(c)
+	if err != nil {
+		ResError(c, CodeNeedLogin)
+		return
+	}
+	targetID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		ResError(c, CodeInvalidParams)
+		return
+	}
+
+	if err = logic.BlockUser(userID, targetID, kind); err != nil {
+		if errors.Is(err, logic.ErrorBlockSelf) {
+			ResErrorWithMsg(c, CodeInvalidParams, err.Error())
+			return
+		}
+		zap.L().Error("logic.BlockUser() failed", zap.Error(err))
+		resProfileError(c, err)
+		return
+	}
+	ResSuccess(c, nil)
+}
+
+// unsetUserBlock 解除对路径中用户的拉黑或屏蔽
+func unsetUserBlock(c *gin.Context, kind uint8) {
+	userID, err := GetCurrentUserID(c)
+	if err != nil {
+		ResError(c, CodeNeedLogin)
+		return
+	}
+	targetID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		ResError(c, CodeInvalidParams)
+		return
+	}
+
+	if err = logic.UnblockUser(userID, targetID, kind); err != nil {
+		zap.L().Error("logic.UnblockUser() failed", zap.Error(err))
+		ResError(c, CodeServerBusy)
+		return
+	}
+	ResSuccess(c, nil)
+}
+
+// userBlockList 分页返回当前用户的拉黑或屏蔽列表
+func userBlockList(c *gin.Context, kind uint8) {
+	userID, err := GetCurrentUserID(c)
+	if err != nil {
+		ResError(c, CodeNeedLogin)
+		return
+	}
+	page, size := GetPageInfo(c)
+
+	data, err := logic.GetBlockList(userID, kind, page, size)
+	if err != nil {
+		zap.L().Error("logic.GetBlockList() failed", zap.Error(err))
+		ResError(c, CodeServerBusy)
+		return
+	}
+	ResSuccess(c, data)
+}
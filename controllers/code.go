@@ -15,6 +15,24 @@ const (
 
 	CodeNeedLogin    // 需要登录
 	CodeInvalidToken // 无效的token
+	CodeTokenReused  // refresh token被重复使用
+
+	CodeEmailNotVerified // 邮箱未验证
+	CodeTooManyRequests  // 请求过于频繁
+	CodeLoginLocked      // 登录失败次数过多，已临时锁定
+
+	CodeInvalidOTP          // 两步验证码错误
+	CodeTwoFactorEnabled    // 已开启两步验证
+	CodeTwoFactorNotEnabled // 未开启两步验证
+
+	CodeOIDCFailed       // 第三方登录失败
+	CodeIdentityConflict // 第三方账号已绑定其他用户或邮箱已注册
+
+	CodeInsufficientScope // 访问令牌权限范围不足
+
+	CodeBlocked // 被对方拉黑，不能评论、回复或@
+
+	CodeEmailTaken // 邮箱已被其他用户验证
 )
 
 var (
@@ -31,6 +49,24 @@ var (
 
 		CodeNeedLogin:    "需要登录",
 		CodeInvalidToken: "无效的token",
+		CodeTokenReused:  "登录凭证已被使用，请重新登录",
+
+		CodeEmailNotVerified: "邮箱未验证",
+		CodeTooManyRequests:  "请求过于频繁",
+		CodeLoginLocked:      "登录失败次数过多，已临时锁定",
+
+		CodeInvalidOTP:          "验证码错误",
+		CodeTwoFactorEnabled:    "已开启两步验证",
+		CodeTwoFactorNotEnabled: "未开启两步验证",
+
+		CodeOIDCFailed:       "第三方登录失败",
+		CodeIdentityConflict: "第三方账号冲突",
+
+		CodeInsufficientScope: "访问令牌权限不足",
+
+		CodeBlocked: "你已被对方拉黑",
+
+		CodeEmailTaken: "邮箱已被其他用户验证",
 	}
 )
 
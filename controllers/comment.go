@@ -1,17 +1,18 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
 	"land/dao/mysql"
+	"land/logic"
 	"land/models"
-	"land/pkg/snowflake"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
 // @Summary 创建评论
-// @Description 创建评论，需登录
+// @Description 创建评论，需登录；被帖子作者或被回复评论的作者拉黑时不能评论，也不能@拉黑了自己的用户
 // @Tags 评论相关
 // @Accept json
 // @Produce json
@@ -27,9 +28,6 @@ func CommentHandler(c *gin.Context) {
 		return
 	}
 
-	// 生成评论ID
-	commentID := snowflake.GetID()
-
 	// 获取作者ID，当前请求的UserID
 	userID, err := GetCurrentUserID(c)
 	if err != nil {
@@ -37,20 +35,28 @@ func CommentHandler(c *gin.Context) {
 		ResError(c, CodeNeedLogin)
 		return
 	}
-	comment.CommentID = commentID
 	comment.AuthorID = userID
 
 	// 创建评论
-	if err := mysql.CreateComment(&comment); err != nil {
-		zap.L().Error("mysql.CreateComment(&comment) failed", zap.Error(err))
-		ResError(c, CodeServerBusy)
+	if err := logic.CreateComment(&comment); err != nil {
+		zap.L().Error("logic.CreateComment(&comment) failed", zap.Error(err))
+		switch {
+		case errors.Is(err, logic.ErrorBlocked):
+			ResError(c, CodeBlocked)
+		case errors.Is(err, logic.ErrorTooManyMentions):
+			ResErrorWithMsg(c, CodeInvalidParams, err.Error())
+		case errors.Is(err, mysql.ErrorInvalidID):
+			ResError(c, CodeInvalidParams)
+		default:
+			ResError(c, CodeServerBusy)
+		}
 		return
 	}
 	ResSuccess(c, nil)
 }
 
 // @Summary 评论列表
-// @Description 批量获取评论列表
+// @Description 批量获取评论列表，登录用户看不到自己屏蔽和拉黑的人的评论
 // @Tags 评论相关
 // @Accept json
 // @Produce json
@@ -64,7 +70,8 @@ func CommentListHandler(c *gin.Context) {
 		ResError(c, CodeInvalidParams)
 		return
 	}
-	posts, err := mysql.GetCommentListByIDs(ids)
+	viewerID, _ := GetCurrentUserID(c)
+	posts, err := logic.GetCommentListByIDs(ids, viewerID)
 	if err != nil {
 		ResError(c, CodeServerBusy)
 		return
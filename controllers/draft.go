@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"errors"
+	"land/dao/mysql"
+	"land/logic"
+	"land/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+// resDraftError 将草稿相关错误转换为响应
+func resDraftError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, mysql.ErrorInvalidID):
+		ResError(c, CodeNotFound)
+	case errors.Is(err, logic.ErrorInvalidPublishTime), errors.Is(err, logic.ErrorTooManyMentions), isTagError(err):
+		ResErrorWithMsg(c, CodeInvalidParams, err.Error())
+	case errors.Is(err, logic.ErrorBlocked):
+		ResError(c, CodeBlocked)
+	default:
+		ResError(c, CodeServerBusy)
+	}
+}
+
+// @Summary 草稿列表
+// @Description 分页查看当前用户的草稿与定时帖子，按最后修改时间倒序
+// @Tags 帖子相关
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户token"
+// @Param page query int false "页码，默认为1"
+// @Param size query int false "每页大小，默认为50，最大100"
+// @Success 200 {object} controllers.RespData "草稿列表"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/drafts [get]
+func DraftListHandler(c *gin.Context) {
+	userID, err := GetCurrentUserID(c)
+	if err != nil {
+		ResError(c, CodeNeedLogin)
+		return
+	}
+	page, size := GetPageInfo(c)
+
+	data, err := logic.GetDraftList(userID, page, size)
+	if err != nil {
+		zap.L().Error("logic.GetDraftList() failed", zap.Error(err))
+		ResError(c, CodeServerBusy)
+		return
+	}
+	ResSuccess(c, data)
+}
+
+// @Summary 修改草稿
+// @Description 修改草稿或定时帖子，指定publish_time时转为定时发布，不指定则保存为草稿
+// @Tags 帖子相关
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户token"
+// @Param id path int true "帖子ID"
+// @Param data body models.ParamUpdateDraft true "草稿内容"
+// @Success 200 {object} controllers.RespData "修改成功"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/drafts/{id} [put]
+func UpdateDraftHandler(c *gin.Context) {
+	userID, err := GetCurrentUserID(c)
+	if err != nil {
+		ResError(c, CodeNeedLogin)
+		return
+	}
+	postID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		ResError(c, CodeInvalidParams)
+		return
+	}
+	p := new(models.ParamUpdateDraft)
+	if err := c.ShouldBindJSON(p); err != nil {
+		zap.L().Error("修改草稿参数无效", zap.Error(err))
+		errs, ok := err.(validator.ValidationErrors)
+		if !ok {
+			ResError(c, CodeInvalidParams)
+			return
+		}
+		ResErrorWithMsg(c, CodeInvalidParams, removeStructName(errs.Translate(trans)))
+		return
+	}
+
+	if err = logic.UpdateDraft(userID, postID, p); err != nil {
+		zap.L().Error("logic.UpdateDraft() failed", zap.Int64("post_id", int64(postID)), zap.Error(err))
+		resDraftError(c, err)
+		return
+	}
+	ResSuccess(c, nil)
+}
+
+// @Summary 删除草稿
+// @Description 删除草稿或定时帖子，已发布的帖子请使用删除帖子接口
+// @Tags 帖子相关
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户token"
+// @Param id path int true "帖子ID"
+// @Success 200 {object} controllers.RespData "删除成功"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/drafts/{id} [delete]
+func DeleteDraftHandler(c *gin.Context) {
+	userID, err := GetCurrentUserID(c)
+	if err != nil {
+		ResError(c, CodeNeedLogin)
+		return
+	}
+	postID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		ResError(c, CodeInvalidParams)
+		return
+	}
+
+	if err = logic.DeleteDraft(userID, postID); err != nil {
+		zap.L().Error("logic.DeleteDraft() failed", zap.Int64("post_id", int64(postID)), zap.Error(err))
+		resDraftError(c, err)
+		return
+	}
+	ResSuccess(c, nil)
+}
+
+// @Summary 发布草稿
+// @Description 立即发布草稿或定时帖子
+// @Tags 帖子相关
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户token"
+// @Param id path int true "帖子ID"
+// @Success 200 {object} controllers.RespData "发布成功"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/drafts/{id}/publish [post]
+func PublishDraftHandler(c *gin.Context) {
+	userID, err := GetCurrentUserID(c)
+	if err != nil {
+		ResError(c, CodeNeedLogin)
+		return
+	}
+	postID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		ResError(c, CodeInvalidParams)
+		return
+	}
+
+	if err = logic.PublishDraft(userID, postID); err != nil {
+		zap.L().Error("logic.PublishDraft() failed", zap.Int64("post_id", int64(postID)), zap.Error(err))
+		resDraftError(c, err)
+		return
+	}
+	ResSuccess(c, gin.H{"post_id": postID})
+}
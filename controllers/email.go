@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"errors"
+	"land/logic"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// @Summary 验证邮箱
+// @Description 打开验证邮件中的链接完成邮箱验证
+// @Tags 用户相关
+// @Accept json
+// @Produce json
+// @Param token query string true "验证令牌"
+// @Success 200 {object} controllers.RespData "验证成功"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /auth/verify [get]
+func VerifyEmailHandler(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		ResError(c, CodeInvalidParams)
+		return
+	}
+
+	if err := logic.VerifyEmail(token); err != nil {
+		zap.L().Error("logic.VerifyEmail() failed", zap.Error(err))
+		if errors.Is(err, logic.ErrorVerifyTokenInvalid) {
+			ResError(c, CodeInvalidToken)
+			return
+		}
+		if errors.Is(err, logic.ErrorEmailTaken) {
+			ResError(c, CodeEmailTaken)
+			return
+		}
+		ResError(c, CodeServerBusy)
+		return
+	}
+	ResSuccess(c, gin.H{"message": "邮箱验证成功"})
+}
+
+// @Summary 重发验证邮件
+// @Description 重新发送邮箱验证邮件，60秒内只能发送一次
+// @Tags 用户相关
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户token"
+// @Success 200 {object} controllers.RespData "已发送"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /auth/verify/resend [post]
+func ResendVerifyEmailHandler(c *gin.Context) {
+	userID, err := GetCurrentUserID(c)
+	if err != nil {
+		ResError(c, CodeNeedLogin)
+		return
+	}
+
+	if err := logic.ResendVerificationEmail(userID); err != nil {
+		zap.L().Error("logic.ResendVerificationEmail() failed", zap.Error(err))
+		switch {
+		case errors.Is(err, logic.ErrorEmailAlreadyVerified):
+			ResSuccess(c, gin.H{"message": "邮箱已验证"})
+		case errors.Is(err, logic.ErrorSendTooFrequent):
+			ResError(c, CodeTooManyRequests)
+		default:
+			ResError(c, CodeServerBusy)
+		}
+		return
+	}
+	ResSuccess(c, gin.H{"message": "验证邮件已发送"})
+}
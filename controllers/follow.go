@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"errors"
+	"land/logic"
+	"land/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// @Summary 关注用户
+// @Description 关注指定用户，重复关注视为成功
+// @Tags 关注相关
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户token"
+// @Param id path int true "用户ID"
+// @Success 200 {object} controllers.RespData "关注成功"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/users/{id}/follow [post]
+func FollowHandler(c *gin.Context) {
+	userID, err := GetCurrentUserID(c)
+	if err != nil {
+		ResError(c, CodeNeedLogin)
+		return
+	}
+	followeeID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		ResError(c, CodeInvalidParams)
+		return
+	}
+
+	if err = logic.FollowUser(userID, followeeID); err != nil {
+		if errors.Is(err, logic.ErrorFollowSelf) {
+			ResErrorWithMsg(c, CodeInvalidParams, err.Error())
+			return
+		}
+		if errors.Is(err, logic.ErrorBlocked) {
+			ResError(c, CodeBlocked)
+			return
+		}
+		zap.L().Error("logic.FollowUser() failed", zap.Error(err))
+		resProfileError(c, err)
+		return
+	}
+	ResSuccess(c, nil)
+}
+
+// @Summary 取消关注
+// @Description 取消关注指定用户，未关注时视为成功
+// @Tags 关注相关
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户token"
+// @Param id path int true "用户ID"
+// @Success 200 {object} controllers.RespData "取消成功"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/users/{id}/follow [delete]
+func UnfollowHandler(c *gin.Context) {
+	userID, err := GetCurrentUserID(c)
+	if err != nil {
+		ResError(c, CodeNeedLogin)
+		return
+	}
+	followeeID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		ResError(c, CodeInvalidParams)
+		return
+	}
+
+	if err = logic.UnfollowUser(userID, followeeID); err != nil {
+		zap.L().Error("logic.UnfollowUser() failed", zap.Error(err))
+		ResError(c, CodeServerBusy)
+		return
+	}
+	ResSuccess(c, nil)
+}
+
+// @Summary 粉丝列表
+// @Description 分页查看用户的粉丝，按关注时间倒序
+// @Tags 关注相关
+// @Accept json
+// @Produce json
+// @Param id path int true "用户ID"
+// @Param page query int false "页码，默认为1"
+// @Param size query int false "每页大小，默认为50，最大100"
+// @Success 200 {object} controllers.RespData "粉丝列表"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/users/{id}/followers [get]
+func FollowerListHandler(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		ResError(c, CodeInvalidParams)
+		return
+	}
+	page, size := GetPageInfo(c)
+
+	data, err := logic.GetFollowerList(userID, page, size)
+	if err != nil {
+		zap.L().Error("logic.GetFollowerList() failed", zap.Error(err))
+		resProfileError(c, err)
+		return
+	}
+	ResSuccess(c, data)
+}
+
+// @Summary 关注列表
+// @Description 分页查看用户关注的人，按关注时间倒序
+// @Tags 关注相关
+// @Accept json
+// @Produce json
+// @Param id path int true "用户ID"
+// @Param page query int false "页码，默认为1"
+// @Param size query int false "每页大小，默认为50，最大100"
+// @Success 200 {object} controllers.RespData "关注列表"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/users/{id}/following [get]
+func FollowingListHandler(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		ResError(c, CodeInvalidParams)
+		return
+	}
+	page, size := GetPageInfo(c)
+
+	data, err := logic.GetFollowingList(userID, page, size)
+	if err != nil {
+		zap.L().Error("logic.GetFollowingList() failed", zap.Error(err))
+		resProfileError(c, err)
+		return
+	}
+	ResSuccess(c, data)
+}
+
+// @Summary 关注时间线
+// @Description 按发布时间倒序获取关注的人发布的帖子，只保留每人最近的一部分
+// @Tags 关注相关
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户token"
+// @Param page query int false "页码，默认为1"
+// @Param size query int false "每页大小，默认为50，最大100"
+// @Param cursor query string false "上一页返回的next_cursor，指定后忽略page"
+// @Success 200 {object} controllers.RespData "帖子列表、分页信息（total为时间线中的帖子数）和下一页的游标next_cursor"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/feed [get]
+func FollowingFeedHandler(c *gin.Context) {
+	userID, err := GetCurrentUserID(c)
+	if err != nil {
+		ResError(c, CodeNeedLogin)
+		return
+	}
+	p := &models.ParamPostList{
+		Page:  1,
+		Size:  50,
+		Order: models.OrderTime,
+	}
+	if err := c.ShouldBindQuery(p); err != nil {
+		zap.L().Error("FollowingFeedHandler with invalid params", zap.Error(err))
+		ResError(c, CodeInvalidParams)
+		return
+	}
+	p.ViewerID = userID
+
+	data, err := logic.GetFollowingFeed(userID, p)
+	if err != nil {
+		zap.L().Error("logic.GetFollowingFeed() failed", zap.Error(err))
+		if errors.Is(err, logic.ErrorInvalidCursor) {
+			ResErrorWithMsg(c, CodeInvalidParams, err.Error())
+			return
+		}
+		ResError(c, CodeServerBusy)
+		return
+	}
+	ResSuccess(c, data)
+}
@@ -0,0 +1,40 @@
+package controllers
+
+import (
+	"land/logic"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// @Summary JWKS公钥集合
+// @Description 返回验证access token所需的全部公钥（RFC 7517格式），其他服务可据此离线验证令牌；按令牌头部的kid选择公钥
+// @Tags 认证相关
+// @Produce json
+// @Success 200 {object} jwt.JSONWebKeySet "公钥集合"
+// @Router /.well-known/jwks.json [get]
+func JWKSHandler(c *gin.Context) {
+	// 允许验证方短时间缓存，遇到未知kid时应重新获取
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, logic.GetJWKS())
+}
+
+// @Summary 轮换签名密钥
+// @Description 立即生成新的签名密钥，旧密钥在其签发的access token过期前仍保留在JWKS中，仅管理员可用
+// @Tags 管理相关
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户token"
+// @Success 200 {object} controllers.RespData "新密钥的kid"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/admin/keys/rotate [post]
+func RotateSigningKeyHandler(c *gin.Context) {
+	kid, err := logic.RotateSigningKey()
+	if err != nil {
+		zap.L().Error("logic.RotateSigningKey() failed", zap.Error(err))
+		ResError(c, CodeServerBusy)
+		return
+	}
+	ResSuccess(c, gin.H{"kid": kid})
+}
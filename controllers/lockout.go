@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"land/logic"
+	"land/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+// @Summary 登录锁定列表
+// @Description 查看当前因登录失败过多被锁定的用户名和IP，仅管理员可用
+// @Tags 管理相关
+// @Accept json
+// @Produce json
+// @Success 200 {object} controllers.RespData "锁定列表"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/admin/lockouts [get]
+func LoginLockListHandler(c *gin.Context) {
+	locks, err := logic.GetLoginLocks()
+	if err != nil {
+		zap.L().Error("logic.GetLoginLocks() failed", zap.Error(err))
+		ResError(c, CodeServerBusy)
+		return
+	}
+	ResSuccess(c, locks)
+}
+
+// @Summary 解除登录锁定
+// @Description 解除指定用户名或IP的登录锁定并清零失败计数，仅管理员可用
+// @Tags 管理相关
+// @Accept json
+// @Produce json
+// @Param data body models.ParamUnlockLogin true "解锁参数"
+// @Success 200 {object} controllers.RespData "解锁成功"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/admin/lockouts/unlock [post]
+func UnlockLoginHandler(c *gin.Context) {
+	p := new(models.ParamUnlockLogin)
+	if err := c.ShouldBindJSON(p); err != nil {
+		errs, ok := err.(validator.ValidationErrors)
+		if !ok {
+			ResError(c, CodeInvalidParams)
+			return
+		}
+		ResErrorWithMsg(c, CodeInvalidParams, removeStructName(errs.Translate(trans)))
+		return
+	}
+
+	if err := logic.UnlockLogin(p.Type, p.Target); err != nil {
+		zap.L().Error("logic.UnlockLogin() failed", zap.Error(err))
+		ResError(c, CodeServerBusy)
+		return
+	}
+	ResSuccess(c, nil)
+}
@@ -0,0 +1,202 @@
+package controllers
+
+import (
+	"errors"
+	"land/logic"
+	"land/models"
+	"land/pkg/oidc"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	oidcStateCookie     = "oidc_state" // 保存state哈希的cookie，把回调绑定到发起流程的浏览器
+	oidcStateCookiePath = "/auth/oidc/"
+)
+
+// setOIDCStateCookie 写入或清除（value为空）state绑定cookie
+// SameSite=Lax保证从提供方跳转回来的GET请求会带上cookie，其他站点发起的子请求不会
+func setOIDCStateCookie(c *gin.Context, value string) {
+	maxAge := int(logic.OIDCStateTTL.Seconds())
+	if value == "" {
+		maxAge = -1
+	}
+	secure := c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, value, maxAge, oidcStateCookiePath, "", secure, true)
+}
+
+// @Summary 第三方登录提供方
+// @Description 列出已配置的OpenID Connect登录提供方
+// @Tags 第三方登录
+// @Accept json
+// @Produce json
+// @Success 200 {object} controllers.RespData "提供方名称列表"
+// @Router /auth/oidc/providers [get]
+func OIDCProvidersHandler(c *gin.Context) {
+	ResSuccess(c, logic.GetOIDCProviders())
+}
+
+// @Summary 发起第三方登录
+// @Description 生成state、nonce与PKCE参数，返回提供方授权地址，前端跳转到该地址完成登录
+// @Tags 第三方登录
+// @Accept json
+// @Produce json
+// @Param provider path string true "提供方名称"
+// @Param device query string false "设备名"
+// @Success 200 {object} controllers.RespData "授权地址"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /auth/oidc/{provider}/login [get]
+func OIDCLoginHandler(c *gin.Context) {
+	authURL, binding, err := logic.StartOIDCLogin(c.Param("provider"), c.Query("device"), 0)
+	if err != nil {
+		zap.L().Error("logic.StartOIDCLogin() failed", zap.String("provider", c.Param("provider")), zap.Error(err))
+		resOIDCError(c, err)
+		return
+	}
+	setOIDCStateCookie(c, binding)
+	ResSuccess(c, gin.H{"auth_url": authURL})
+}
+
+// @Summary 第三方登录回调
+// @Description 提供方回调地址，兑换授权码并校验ID Token；首次登录自动注册，返回与密码登录相同的令牌（开启两步验证时返回登录挑战）；绑定流程返回绑定结果
+// @Tags 第三方登录
+// @Accept json
+// @Produce json
+// @Param provider path string true "提供方名称"
+// @Param code query string true "授权码"
+// @Param state query string true "发起登录时的state"
+// @Success 200 {object} controllers.RespData "登录成功，返回token"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /auth/oidc/{provider}/callback [get]
+func OIDCCallbackHandler(c *gin.Context) {
+	// 用户在提供方拒绝授权时只带回error参数
+	if e := c.Query("error"); e != "" {
+		zap.L().Info("OIDC authorization denied", zap.String("error", e), zap.String("description", c.Query("error_description")))
+		ResErrorWithMsg(c, CodeOIDCFailed, e)
+		return
+	}
+
+	p := new(models.ParamOIDCCallback)
+	if err := c.ShouldBindQuery(p); err != nil {
+		zap.L().Error("第三方登录回调参数无效", zap.Error(err))
+		ResError(c, CodeInvalidParams)
+		return
+	}
+
+	// state只能使用一次，无论成功与否都清除cookie
+	binding, _ := c.Cookie(oidcStateCookie)
+	setOIDCStateCookie(c, "")
+
+	user, state, err := logic.FinishOIDCLogin(c.Param("provider"), p, binding)
+	if err != nil {
+		zap.L().Error("logic.FinishOIDCLogin() failed", zap.String("provider", c.Param("provider")), zap.Error(err))
+		resOIDCError(c, err)
+		return
+	}
+
+	if state.LinkUserID != 0 {
+		ResSuccess(c, gin.H{"linked": true, "provider": state.Provider})
+		return
+	}
+	resLogin(c, user, state.Device)
+}
+
+// @Summary 已绑定的第三方账号
+// @Description 列出当前用户绑定的第三方身份
+// @Tags 第三方登录
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户token"
+// @Success 200 {object} controllers.RespData "第三方身份列表"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/oidc/identities [get]
+func OIDCIdentitiesHandler(c *gin.Context) {
+	userID, err := GetCurrentUserID(c)
+	if err != nil {
+		ResError(c, CodeNeedLogin)
+		return
+	}
+
+	identities, err := logic.GetUserIdentities(userID)
+	if err != nil {
+		zap.L().Error("logic.GetUserIdentities() failed", zap.Error(err))
+		ResError(c, CodeServerBusy)
+		return
+	}
+	ResSuccess(c, identities)
+}
+
+// @Summary 绑定第三方账号
+// @Description 已登录用户发起绑定，返回提供方授权地址，回调成功后该第三方账号可直接登录当前用户
+// @Tags 第三方登录
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户token"
+// @Param provider path string true "提供方名称"
+// @Success 200 {object} controllers.RespData "授权地址"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/oidc/{provider}/link [post]
+func OIDCLinkHandler(c *gin.Context) {
+	userID, err := GetCurrentUserID(c)
+	if err != nil {
+		ResError(c, CodeNeedLogin)
+		return
+	}
+
+	authURL, binding, err := logic.StartOIDCLogin(c.Param("provider"), "", userID)
+	if err != nil {
+		zap.L().Error("logic.StartOIDCLogin() failed", zap.String("provider", c.Param("provider")), zap.Error(err))
+		resOIDCError(c, err)
+		return
+	}
+	setOIDCStateCookie(c, binding)
+	ResSuccess(c, gin.H{"auth_url": authURL})
+}
+
+// @Summary 解绑第三方账号
+// @Description 解绑当前用户在指定提供方的身份；未设置密码的用户不能解绑最后一个第三方账号
+// @Tags 第三方登录
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户token"
+// @Param provider path string true "提供方名称"
+// @Success 200 {object} controllers.RespData "解绑成功"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/oidc/{provider} [delete]
+func OIDCUnlinkHandler(c *gin.Context) {
+	userID, err := GetCurrentUserID(c)
+	if err != nil {
+		ResError(c, CodeNeedLogin)
+		return
+	}
+
+	if err = logic.UnlinkIdentity(userID, c.Param("provider")); err != nil {
+		zap.L().Error("logic.UnlinkIdentity() failed", zap.Error(err))
+		resOIDCError(c, err)
+		return
+	}
+	ResSuccess(c, gin.H{"message": "解绑成功"})
+}
+
+// resOIDCError 将第三方登录相关的错误转换为响应码
+func resOIDCError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, oidc.ErrProviderNotFound):
+		ResError(c, CodeNotFound)
+	case errors.Is(err, logic.ErrorOIDCStateInvalid):
+		ResError(c, CodeInvalidToken)
+	case errors.Is(err, oidc.ErrExchangeFailed), errors.Is(err, oidc.ErrInvalidIDToken):
+		ResError(c, CodeOIDCFailed)
+	case errors.Is(err, logic.ErrorOIDCEmailConflict), errors.Is(err, logic.ErrorIdentityLinked):
+		ResErrorWithMsg(c, CodeIdentityConflict, err.Error())
+	case errors.Is(err, logic.ErrorIdentityNotLinked):
+		ResError(c, CodeNotFound)
+	case errors.Is(err, logic.ErrorLastLoginMethod):
+		ResErrorWithMsg(c, CodeInvalidParams, err.Error())
+	default:
+		ResError(c, CodeServerBusy)
+	}
+}
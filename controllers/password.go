@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"errors"
+	"land/logic"
+	"land/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+// @Summary 忘记密码
+// @Description 向注册邮箱发送一次性密码重置链接；无论邮箱是否注册均返回成功
+// @Tags 用户相关
+// @Accept json
+// @Produce json
+// @Param data body models.ParamForgotPassword true "邮箱"
+// @Success 200 {object} controllers.RespData "已发送"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /auth/password/forgot [post]
+func ForgotPasswordHandler(c *gin.Context) {
+	p := new(models.ParamForgotPassword)
+	if err := c.ShouldBindJSON(p); err != nil {
+		zap.L().Error("忘记密码参数无效", zap.Error(err))
+		errs, ok := err.(validator.ValidationErrors)
+		if !ok {
+			ResError(c, CodeInvalidParams)
+			return
+		}
+		ResErrorWithMsg(c, CodeInvalidParams, removeStructName(errs.Translate(trans)))
+		return
+	}
+
+	if err := logic.ForgotPassword(p.Email); err != nil {
+		zap.L().Error("logic.ForgotPassword() failed", zap.Error(err))
+		ResError(c, CodeServerBusy)
+		return
+	}
+	ResSuccess(c, gin.H{"message": "如果该邮箱已注册，重置链接已发送"})
+}
+
+// @Summary 重置密码
+// @Description 使用邮件中的一次性令牌设置新密码，成功后所有设备需重新登录
+// @Tags 用户相关
+// @Accept json
+// @Produce json
+// @Param data body models.ParamResetPassword true "重置参数"
+// @Success 200 {object} controllers.RespData "重置成功"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /auth/password/reset [post]
+func ResetPasswordHandler(c *gin.Context) {
+	p := new(models.ParamResetPassword)
+	if err := c.ShouldBindJSON(p); err != nil {
+		zap.L().Error("重置密码参数无效", zap.Error(err))
+		errs, ok := err.(validator.ValidationErrors)
+		if !ok {
+			ResError(c, CodeInvalidParams)
+			return
+		}
+		ResErrorWithMsg(c, CodeInvalidParams, removeStructName(errs.Translate(trans)))
+		return
+	}
+
+	if err := logic.ResetPassword(p.Token, p.Password); err != nil {
+		zap.L().Error("logic.ResetPassword() failed", zap.Error(err))
+		if errors.Is(err, logic.ErrorResetTokenInvalid) {
+			ResError(c, CodeInvalidToken)
+			return
+		}
+		ResError(c, CodeServerBusy)
+		return
+	}
+	ResSuccess(c, gin.H{"message": "密码已重置，请重新登录"})
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"land/dao/mysql"
 	"land/dao/redis"
 	"land/logic"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
 	"go.uber.org/zap"
 )
 
@@ -19,7 +21,7 @@ import (
 // @Produce json
 // @Param page query int false "页码，默认为1"
 // @Param size query int false "每页大小，默认为50，最大100"
-// @Success 200 {object} controllers.RespData "帖子列表"
+// @Success 200 {object} controllers.RespData "帖子列表及分页信息"
 // @Failure 400 {object} controllers.RespData "请求参数错误"
 // @Router /api/v1/posts [get]
 func GetPostListController(c *gin.Context) {
@@ -70,16 +72,16 @@ func PostDetailController(c *gin.Context) {
 }
 
 // @Summary 创建帖子
-// @Description 创建新帖子，需登录
+// @Description 创建新帖子，需登录；draft为true时保存为草稿，指定publish_time时定时发布
 // @Tags 帖子相关
 // @Accept json
 // @Produce json
-// @Param data body models.Post true "帖子内容"
+// @Param data body models.ParamCreatePost true "帖子内容"
 // @Success 200 {object} controllers.RespData "创建成功"
 // @Failure 400 {object} controllers.RespData "请求参数错误"
 // @Router /api/v1/post [post]
 func CreatePostController(c *gin.Context) {
-	p := new(models.Post)
+	p := new(models.ParamCreatePost)
 	if err := c.ShouldBindJSON(&p); err != nil {
 		zap.L().Debug("c.ShouldBindJSON(&p) failed", zap.Error(err))
 		zap.L().Error("create post failed", zap.Error(err))
@@ -92,29 +94,86 @@ func CreatePostController(c *gin.Context) {
 		ResError(c, CodeNeedLogin)
 		return
 	}
-	p.AuthorID = userID
+	post := &models.Post{
+		AuthorID:    userID,
+		Title:       p.Title,
+		Content:     p.Content,
+		CommunityID: p.CommunityID,
+		Tags:        p.Tags,
+	}
+	if p.Draft {
+		post.Status = models.PostStatusDraft
+	} else if p.PublishTime != nil {
+		post.Status = models.PostStatusScheduled
+		post.PublishTime = p.PublishTime
+	}
 
-	if err = logic.CreatePost(p); err != nil {
+	if err = logic.CreatePost(post); err != nil {
 		zap.L().Error("logic.CreatePost(p) failed", zap.Error(err))
+		if errors.Is(err, logic.ErrorBlocked) {
+			ResError(c, CodeBlocked)
+			return
+		}
+		if errors.Is(err, logic.ErrorInvalidPublishTime) || errors.Is(err, logic.ErrorTooManyMentions) || isTagError(err) {
+			ResErrorWithMsg(c, CodeInvalidParams, err.Error())
+			return
+		}
 		ResError(c, CodeServerBusy)
 		return
 	}
 
-	ResSuccess(c, nil)
+	ResSuccess(c, gin.H{
+		"post_id":      post.PostID,
+		"status":       post.Status,
+		"publish_time": post.PublishTime,
+	})
+}
+
+// @Summary 预览帖子内容
+// @Description 将Markdown渲染为过滤后的HTML，不保存
+// @Tags 帖子相关
+// @Accept json
+// @Produce json
+// @Param data body models.ParamPreview true "Markdown原文"
+// @Success 200 {object} controllers.RespData "渲染结果"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/post/preview [post]
+func PreviewPostHandler(c *gin.Context) {
+	p := new(models.ParamPreview)
+	if err := c.ShouldBindJSON(p); err != nil {
+		zap.L().Error("预览参数无效", zap.Error(err))
+		errs, ok := err.(validator.ValidationErrors)
+		if !ok {
+			ResError(c, CodeInvalidParams)
+			return
+		}
+		ResErrorWithMsg(c, CodeInvalidParams, removeStructName(errs.Translate(trans)))
+		return
+	}
+
+	html, err := logic.PreviewContent(p.Content)
+	if err != nil {
+		zap.L().Error("logic.PreviewContent() failed", zap.Error(err))
+		ResError(c, CodeServerBusy)
+		return
+	}
+	ResSuccess(c, gin.H{"content_html": html})
 }
 
 // @Summary 升级版帖子列表
-// @Description 可按社区、时间、分数、访问量排序，支持分页、搜索、MySQL索引优化
+// @Description 可按社区、标签过滤，按时间、分数、访问量排序，支持分页、游标翻页、搜索、MySQL索引优化
 // @Tags 帖子相关
 // @Accept json
 // @Produce json
 // @Param page query int false "页码，默认为1"
 // @Param size query int false "每页大小，默认为50，最大100"
-// @Param order query string false "排序方式：time(时间倒序), score(分数倒序), view(访问量倒序)"
+// @Param order query string false "排序方式：time(时间倒序), score(分数倒序), view(访问量倒序), relevance(相关度，仅搜索时有效), hot(热门), best(最佳), controversial(争议), top(最多赞)"
 // @Param community_id query int false "社区ID，可选"
-// @Param search query string false "搜索关键词，可选"
+// @Param search query string false "搜索关键词，匹配标题和内容，可选；未指定order时按相关度排序"
+// @Param tag query string false "标签，可选"
+// @Param cursor query string false "上一页返回的next_cursor，指定后忽略page，排序方式需与上一页一致"
 // @Param use_index query bool false "是否使用MySQL索引优化，默认true"
-// @Success 200 {object} controllers.RespData "帖子列表"
+// @Success 200 {object} controllers.RespData "帖子列表、分页信息（total为符合条件的帖子数）和下一页的游标next_cursor"
 // @Failure 400 {object} controllers.RespData "请求参数错误"
 // @Router /api/v1/posts2 [get]
 func GetPostListHandler2(c *gin.Context) {
@@ -130,6 +189,11 @@ func GetPostListHandler2(c *gin.Context) {
 		ResError(c, CodeInvalidParams)
 		return
 	}
+	p.ViewerID, _ = GetCurrentUserID(c)
+	// 搜索时默认按相关度排序
+	if p.Search != "" && c.Query("order") == "" {
+		p.Order = models.OrderRelevance
+	}
 
 	// 设置默认值和限制
 	if p.Page < 1 {
@@ -146,6 +210,10 @@ func GetPostListHandler2(c *gin.Context) {
 	// 获取数据
 	if err != nil {
 		zap.L().Error("logic.GetPostListByOrder() failed", zap.Error(err))
+		if errors.Is(err, logic.ErrorInvalidTag) || errors.Is(err, logic.ErrorInvalidCursor) {
+			ResErrorWithMsg(c, CodeInvalidParams, err.Error())
+			return
+		}
 		ResError(c, CodeServerBusy)
 		return
 	}
@@ -156,7 +224,7 @@ func GetPostListHandler2(c *gin.Context) {
 }
 
 // @Summary 手动同步访问量
-// @Description 手动触发Redis访问量数据同步到MySQL
+// @Description 手动触发Redis访问量数据同步到MySQL，仅管理员可用
 // @Tags 帖子相关
 // @Accept json
 // @Produce json
@@ -164,13 +232,6 @@ func GetPostListHandler2(c *gin.Context) {
 // @Failure 400 {object} controllers.RespData "请求参数错误"
 // @Router /api/v1/posts/sync_view [post]
 func SyncViewCountsHandler(c *gin.Context) {
-	// 这里可以添加权限检查，只有管理员才能调用，这里暂且不做权限检查
-	// userID, err := GetCurrentUserID(c)
-	// if err != nil {
-	//     ResError(c, CodeNeedLogin)
-	//     return
-	// }
-
 	syncService := logic.NewViewCountSyncService(0)
 	err := syncService.ManualSync()
 	if err != nil {
@@ -185,7 +246,7 @@ func SyncViewCountsHandler(c *gin.Context) {
 }
 
 // @Summary 清除帖子缓存
-// @Description 手动清除指定帖子的缓存
+// @Description 手动清除指定帖子的缓存，仅管理员和版主可用
 // @Tags 帖子相关
 // @Accept json
 // @Produce json
@@ -229,7 +290,7 @@ func ClearPostCacheHandler(c *gin.Context) {
 }
 
 // @Summary 清除所有帖子缓存
-// @Description 手动清除所有帖子的缓存
+// @Description 手动清除所有帖子的缓存，仅管理员和版主可用
 // @Tags 帖子相关
 // @Accept json
 // @Produce json
@@ -276,6 +337,10 @@ func UpdatePostController(c *gin.Context) {
 		zap.L().Error("logic.UpdatePost() failed", zap.Error(err))
 		if err == mysql.ErrorInvalidID {
 			ResError(c, CodeUnauthorized)
+		} else if errors.Is(err, logic.ErrorBlocked) {
+			ResError(c, CodeBlocked)
+		} else if errors.Is(err, logic.ErrorTooManyMentions) || isTagError(err) {
+			ResErrorWithMsg(c, CodeInvalidParams, err.Error())
 		} else {
 			ResError(c, CodeServerBusy)
 		}
@@ -319,6 +384,10 @@ func UpdatePostWithConsistencyController(c *gin.Context) {
 		zap.L().Error("logic.UpdatePostWithCacheConsistency() failed", zap.Error(err))
 		if err == mysql.ErrorInvalidID {
 			ResError(c, CodeUnauthorized)
+		} else if errors.Is(err, logic.ErrorBlocked) {
+			ResError(c, CodeBlocked)
+		} else if errors.Is(err, logic.ErrorTooManyMentions) || isTagError(err) {
+			ResErrorWithMsg(c, CodeInvalidParams, err.Error())
 		} else {
 			ResError(c, CodeServerBusy)
 		}
@@ -331,8 +400,66 @@ func UpdatePostWithConsistencyController(c *gin.Context) {
 	})
 }
 
+// @Summary 删除帖子
+// @Description 删除帖子，作者、管理员和版主可用，保留期内可以恢复
+// @Tags 帖子相关
+// @Accept json
+// @Produce json
+// @Param id path int true "帖子ID"
+// @Success 200 {object} controllers.RespData "删除成功"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/post/{id} [delete]
+func DeletePostHandler(c *gin.Context) {
+	setPostDeleted(c, logic.DeletePost, "帖子已删除")
+}
+
+// @Summary 恢复帖子
+// @Description 恢复已删除的帖子，作者只能恢复自己删除的帖子，管理员和版主可以恢复任意帖子
+// @Tags 帖子相关
+// @Accept json
+// @Produce json
+// @Param id path int true "帖子ID"
+// @Success 200 {object} controllers.RespData "恢复成功"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/post/{id}/restore [post]
+func RestorePostHandler(c *gin.Context) {
+	setPostDeleted(c, logic.RestorePost, "帖子已恢复")
+}
+
+// setPostDeleted 删除与恢复帖子的公共处理
+func setPostDeleted(c *gin.Context, op func(pid, operatorID uint64, moderator bool) error, msg string) {
+	userID, err := GetCurrentUserID(c)
+	if err != nil {
+		ResError(c, CodeNeedLogin)
+		return
+	}
+	postID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		ResError(c, CodeInvalidParams)
+		return
+	}
+
+	if err = op(postID, userID, HasRole(c, models.RoleAdmin, models.RoleModerator)); err != nil {
+		zap.L().Error("delete or restore post failed", zap.Int64("post_id", int64(postID)), zap.Error(err))
+		switch {
+		case errors.Is(err, mysql.ErrorInvalidID):
+			ResError(c, CodeNotFound)
+		case errors.Is(err, logic.ErrorPostForbidden):
+			ResError(c, CodeUnauthorized)
+		default:
+			ResError(c, CodeServerBusy)
+		}
+		return
+	}
+
+	ResSuccess(c, gin.H{
+		"message": msg,
+		"post_id": postID,
+	})
+}
+
 // @Summary 初始化访问量排序
-// @Description 手动初始化Redis中的帖子访问量有序集合
+// @Description 手动初始化Redis中的帖子访问量有序集合，仅管理员可用
 // @Tags 帖子相关
 // @Accept json
 // @Produce json
@@ -340,13 +467,6 @@ func UpdatePostWithConsistencyController(c *gin.Context) {
 // @Failure 400 {object} controllers.RespData "请求参数错误"
 // @Router /api/v1/posts/init_view_zset [post]
 func InitPostViewZSetHandler(c *gin.Context) {
-	// 这里可以添加权限检查，只有管理员才能调用
-	// userID, err := GetCurrentUserID(c)
-	// if err != nil {
-	//     ResError(c, CodeNeedLogin)
-	//     return
-	// }
-
 	// 获取所有访问量数据
 	viewCounts, err := redis.GetAllPostViewCounts()
 	if err != nil {
@@ -369,8 +489,52 @@ func InitPostViewZSetHandler(c *gin.Context) {
 	})
 }
 
+// @Summary 重建搜索索引
+// @Description 按数据库中已发布的帖子重建搜索索引，用于首次启用搜索或Redis数据丢失后，仅管理员可用
+// @Tags 帖子相关
+// @Accept json
+// @Produce json
+// @Success 200 {object} controllers.RespData "重建结果"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/init/search [post]
+func RebuildSearchIndexHandler(c *gin.Context) {
+	n, err := logic.RebuildSearchIndex()
+	if err != nil {
+		zap.L().Error("logic.RebuildSearchIndex() failed", zap.Int("indexed", n), zap.Error(err))
+		ResError(c, CodeServerBusy)
+		return
+	}
+
+	ResSuccess(c, gin.H{
+		"message":     "搜索索引重建完成",
+		"posts_count": n,
+	})
+}
+
+// @Summary 重算排序分数
+// @Description 按投票记录和当前的排序配置重算所有已发布帖子的hot/best/controversial/top分数，用于修改排序参数后或首次启用，仅管理员可用
+// @Tags 帖子相关
+// @Accept json
+// @Produce json
+// @Success 200 {object} controllers.RespData "重算结果"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/init/ranking [post]
+func RebuildRankingHandler(c *gin.Context) {
+	n, err := logic.RebuildRanking()
+	if err != nil {
+		zap.L().Error("logic.RebuildRanking() failed", zap.Int("ranked", n), zap.Error(err))
+		ResError(c, CodeServerBusy)
+		return
+	}
+
+	ResSuccess(c, gin.H{
+		"message":     "排序分数重算完成",
+		"posts_count": n,
+	})
+}
+
 // @Summary 测试随机TTL
-// @Description 测试随机TTL生成功能，验证缓存雪崩防护
+// @Description 测试随机TTL生成功能，验证缓存雪崩防护，仅管理员可用
 // @Tags 帖子相关
 // @Accept json
 // @Produce json
@@ -0,0 +1,256 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"land/dao/mysql"
+	"land/logic"
+	"land/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+// resProfileError 将资料相关错误转换为响应
+func resProfileError(c *gin.Context, err error) {
+	if errors.Is(err, mysql.ErrorUserNotExist) {
+		ResError(c, CodeUserNotFound)
+		return
+	}
+	ResError(c, CodeServerBusy)
+}
+
+// @Summary 用户资料
+// @Description 查看用户公开资料，不包含密码；本人查看时额外返回邮箱
+// @Tags 用户资料
+// @Accept json
+// @Produce json
+// @Param id path int true "用户ID"
+// @Success 200 {object} controllers.RespData "用户资料"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/users/{id} [get]
+func UserProfileHandler(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		ResError(c, CodeInvalidParams)
+		return
+	}
+	currentID, _ := GetCurrentUserID(c)
+
+	profile, err := logic.GetUserProfile(userID, currentID == userID)
+	if err != nil {
+		zap.L().Error("logic.GetUserProfile() failed", zap.Error(err))
+		resProfileError(c, err)
+		return
+	}
+	ResSuccess(c, profile)
+}
+
+// @Summary 我的资料
+// @Description 查看当前登录用户的资料
+// @Tags 用户资料
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户token"
+// @Success 200 {object} controllers.RespData "用户资料"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/users/me [get]
+func MyProfileHandler(c *gin.Context) {
+	userID, err := GetCurrentUserID(c)
+	if err != nil {
+		ResError(c, CodeNeedLogin)
+		return
+	}
+
+	profile, err := logic.GetUserProfile(userID, true)
+	if err != nil {
+		zap.L().Error("logic.GetUserProfile() failed", zap.Error(err))
+		resProfileError(c, err)
+		return
+	}
+	ResSuccess(c, profile)
+}
+
+// @Summary 修改资料
+// @Description 修改当前用户的昵称、简介、性别和头像，未传的字段保持不变
+// @Tags 用户资料
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户token"
+// @Param data body models.ParamUpdateProfile true "资料"
+// @Success 200 {object} controllers.RespData "更新后的资料"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/users/me [put]
+func UpdateProfileHandler(c *gin.Context) {
+	userID, err := GetCurrentUserID(c)
+	if err != nil {
+		ResError(c, CodeNeedLogin)
+		return
+	}
+
+	p := new(models.ParamUpdateProfile)
+	if err := c.ShouldBindJSON(p); err != nil {
+		zap.L().Error("修改资料参数无效", zap.Error(err))
+		errs, ok := err.(validator.ValidationErrors)
+		if !ok {
+			ResError(c, CodeInvalidParams)
+			return
+		}
+		ResErrorWithMsg(c, CodeInvalidParams, removeStructName(errs.Translate(trans)))
+		return
+	}
+
+	profile, err := logic.UpdateUserProfile(userID, p)
+	if err != nil {
+		if errors.Is(err, logic.ErrorInvalidAvatar) {
+			ResErrorWithMsg(c, CodeInvalidParams, err.Error())
+			return
+		}
+		zap.L().Error("logic.UpdateUserProfile() failed", zap.Error(err))
+		resProfileError(c, err)
+		return
+	}
+	ResSuccess(c, profile)
+}
+
+// @Summary 用户帖子
+// @Description 分页查看用户发布的帖子
+// @Tags 用户资料
+// @Accept json
+// @Produce json
+// @Param id path int true "用户ID"
+// @Param page query int false "页码，默认为1"
+// @Param size query int false "每页大小，默认为50，最大100"
+// @Success 200 {object} controllers.RespData "帖子列表"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/users/{id}/posts [get]
+func UserPostListHandler(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		ResError(c, CodeInvalidParams)
+		return
+	}
+	page, size := GetPageInfo(c)
+
+	data, err := logic.GetUserPostList(userID, page, size)
+	if err != nil {
+		zap.L().Error("logic.GetUserPostList() failed", zap.Error(err))
+		resProfileError(c, err)
+		return
+	}
+	ResSuccess(c, data)
+}
+
+// @Summary 用户评论
+// @Description 分页查看用户发表的评论
+// @Tags 用户资料
+// @Accept json
+// @Produce json
+// @Param id path int true "用户ID"
+// @Param page query int false "页码，默认为1"
+// @Param size query int false "每页大小，默认为50，最大100"
+// @Success 200 {object} controllers.RespData "评论列表"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/users/{id}/comments [get]
+func UserCommentListHandler(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		ResError(c, CodeInvalidParams)
+		return
+	}
+	page, size := GetPageInfo(c)
+
+	data, err := logic.GetUserCommentList(userID, page, size)
+	if err != nil {
+		zap.L().Error("logic.GetUserCommentList() failed", zap.Error(err))
+		resProfileError(c, err)
+		return
+	}
+	ResSuccess(c, data)
+}
+
+// @Summary 导出个人数据
+// @Description 导出当前用户的资料、第三方身份、帖子、评论和投票记录，format=zip时以附件形式下载
+// @Tags 用户资料
+// @Accept json
+// @Produce json
+// @Produce application/zip
+// @Param Authorization header string true "Bearer 用户token"
+// @Param format query string false "导出格式：json（默认）/zip"
+// @Success 200 {object} controllers.RespData "个人数据"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/users/me/export [get]
+func ExportUserDataHandler(c *gin.Context) {
+	userID, err := GetCurrentUserID(c)
+	if err != nil {
+		ResError(c, CodeNeedLogin)
+		return
+	}
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "zip" {
+		ResError(c, CodeInvalidParams)
+		return
+	}
+
+	data, err := logic.ExportUserData(userID)
+	if err != nil {
+		zap.L().Error("logic.ExportUserData() failed", zap.Error(err))
+		resProfileError(c, err)
+		return
+	}
+	if format == "json" {
+		ResSuccess(c, data)
+		return
+	}
+
+	archive, err := logic.BuildExportArchive(data)
+	if err != nil {
+		zap.L().Error("logic.BuildExportArchive() failed", zap.Error(err))
+		ResError(c, CodeServerBusy)
+		return
+	}
+	filename := fmt.Sprintf("land-export-%d-%s.zip", userID, data.ExportTime.Format("20060102"))
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Header("Cache-Control", "no-store")
+	c.Data(200, "application/zip", archive)
+}
+
+// @Summary 注销账号
+// @Description 注销当前账号：帖子与评论内容替换为[deleted]，投票与访问记录、会话和访问令牌立即清除，个人信息在宽限期满后彻底删除
+// @Tags 用户资料
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户token"
+// @Param data body models.ParamDeleteAccount false "当前密码，设置过密码的账号必填"
+// @Success 200 {object} controllers.RespData "个人信息彻底删除的时间"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/users/me [delete]
+func DeleteAccountHandler(c *gin.Context) {
+	userID, err := GetCurrentUserID(c)
+	if err != nil {
+		ResError(c, CodeNeedLogin)
+		return
+	}
+
+	p := new(models.ParamDeleteAccount)
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(p); err != nil {
+			ResError(c, CodeInvalidParams)
+			return
+		}
+	}
+
+	res, err := logic.DeleteAccount(userID, p)
+	if err != nil {
+		if errors.Is(err, mysql.ErrorInvalidPassword) {
+			ResError(c, CodeUserPasswordError)
+			return
+		}
+		zap.L().Error("logic.DeleteAccount() failed", zap.Error(err))
+		resProfileError(c, err)
+		return
+	}
+	ResSuccess(c, res)
+}
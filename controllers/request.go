@@ -8,7 +8,10 @@ import (
 )
 
 const (
-	ContextUserIDKey = "userID"
+	ContextUserIDKey    = "userID"
+	ContextSessionIDKey = "sessionID"
+	ContextRolesKey     = "roles"
+	ContextScopesKey    = "scopes" // 仅个人访问令牌认证时设置
 )
 
 var (
@@ -36,6 +39,74 @@ func GetCurrentUserID(c *gin.Context) (userID uint64, err error) {
 	return
 }
 
+// GetCurrentUserRoles 获取当前登录用户的角色
+// 参数:
+//   - c: gin的上下文
+//
+// 返回值:
+//   - []string: 角色列表，未登录时为空
+func GetCurrentUserRoles(c *gin.Context) []string {
+	roles, ok := c.Get(ContextRolesKey)
+	if !ok {
+		return nil
+	}
+	list, _ := roles.([]string)
+	return list
+}
+
+// HasRole 判断当前登录用户是否拥有任一指定角色
+// 参数:
+//   - c: gin的上下文
+//   - roles: 候选角色
+//
+// 返回值:
+//   - bool: 是否拥有
+func HasRole(c *gin.Context, roles ...string) bool {
+	for _, have := range GetCurrentUserRoles(c) {
+		for _, want := range roles {
+			if have == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsAccessTokenAuth 判断当前请求是否通过个人访问令牌认证
+// 参数:
+//   - c: gin的上下文
+//
+// 返回值:
+//   - bool: 使用个人访问令牌时返回true，使用登录会话时返回false
+func IsAccessTokenAuth(c *gin.Context) bool {
+	_, ok := c.Get(ContextScopesKey)
+	return ok
+}
+
+// HasScope 判断当前请求是否拥有任一指定权限范围
+// 登录会话不受权限范围限制；个人访问令牌需包含其中之一，未指定scopes时一律拒绝
+// 参数:
+//   - c: gin的上下文
+//   - scopes: 候选权限范围
+//
+// 返回值:
+//   - bool: 是否拥有
+func HasScope(c *gin.Context, scopes ...string) bool {
+	v, ok := c.Get(ContextScopesKey)
+	if !ok {
+		return true
+	}
+	have, _ := v.([]string)
+	for _, h := range have {
+		for _, w := range scopes {
+			if h == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // GetPageInfo 从请求中获取分页信息
 // 参数:
 //   - c: gin的上下文
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"errors"
+	"land/dao/mysql"
+	"land/logic"
+	"land/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+// resRevisionError 将修订记录相关错误转换为响应
+func resRevisionError(c *gin.Context, err error) {
+	if errors.Is(err, mysql.ErrorInvalidID) {
+		ResError(c, CodeNotFound)
+		return
+	}
+	ResError(c, CodeServerBusy)
+}
+
+// @Summary 帖子修订记录
+// @Description 分页查看帖子的编辑历史，按版本号倒序；已删除帖子的修订记录仅管理员和版主可见
+// @Tags 帖子相关
+// @Accept json
+// @Produce json
+// @Param id path int true "帖子ID"
+// @Param page query int false "页码，默认为1"
+// @Param size query int false "每页大小，默认为50，最大100"
+// @Success 200 {object} controllers.RespData "修订记录"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/post/{id}/revisions [get]
+func PostRevisionListHandler(c *gin.Context) {
+	postID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		ResError(c, CodeInvalidParams)
+		return
+	}
+	page, size := GetPageInfo(c)
+
+	data, err := logic.GetPostRevisions(postID, page, size, HasRole(c, models.RoleAdmin, models.RoleModerator))
+	if err != nil {
+		zap.L().Error("logic.GetPostRevisions() failed", zap.Int64("post_id", int64(postID)), zap.Error(err))
+		resRevisionError(c, err)
+		return
+	}
+	ResSuccess(c, data)
+}
+
+// @Summary 比较帖子版本
+// @Description 按行比较帖子的任意两个版本，返回标题和内容的差异
+// @Tags 帖子相关
+// @Accept json
+// @Produce json
+// @Param id path int true "帖子ID"
+// @Param from query int true "旧版本号"
+// @Param to query int true "新版本号"
+// @Success 200 {object} controllers.RespData "比较结果"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/post/{id}/revisions/compare [get]
+func ComparePostRevisionHandler(c *gin.Context) {
+	postID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		ResError(c, CodeInvalidParams)
+		return
+	}
+	p := new(models.ParamCompareRevision)
+	if err := c.ShouldBindQuery(p); err != nil {
+		zap.L().Error("比较版本参数无效", zap.Error(err))
+		errs, ok := err.(validator.ValidationErrors)
+		if !ok {
+			ResError(c, CodeInvalidParams)
+			return
+		}
+		ResErrorWithMsg(c, CodeInvalidParams, removeStructName(errs.Translate(trans)))
+		return
+	}
+
+	data, err := logic.ComparePostRevisions(postID, p, HasRole(c, models.RoleAdmin, models.RoleModerator))
+	if err != nil {
+		zap.L().Error("logic.ComparePostRevisions() failed", zap.Int64("post_id", int64(postID)), zap.Error(err))
+		resRevisionError(c, err)
+		return
+	}
+	ResSuccess(c, data)
+}
@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"errors"
+	"land/dao/mysql"
+	"land/logic"
+	"land/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+// @Summary 用户角色列表
+// @Description 查看指定用户的角色，仅管理员可用
+// @Tags 管理相关
+// @Accept json
+// @Produce json
+// @Param id path int true "用户ID"
+// @Success 200 {object} controllers.RespData "角色列表"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/admin/users/{id}/roles [get]
+func UserRolesHandler(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		ResError(c, CodeInvalidParams)
+		return
+	}
+
+	roles, err := logic.GetUserRoles(userID)
+	if err != nil {
+		zap.L().Error("logic.GetUserRoles() failed", zap.Error(err))
+		ResError(c, CodeServerBusy)
+		return
+	}
+	ResSuccess(c, gin.H{
+		"user_id": strconv.FormatUint(userID, 10),
+		"roles":   roles,
+	})
+}
+
+// @Summary 授予角色
+// @Description 为指定用户授予admin或moderator角色，用户下次登录或刷新令牌后生效，仅管理员可用
+// @Tags 管理相关
+// @Accept json
+// @Produce json
+// @Param id path int true "用户ID"
+// @Param data body models.ParamRole true "角色"
+// @Success 200 {object} controllers.RespData "授予成功"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/admin/users/{id}/roles [post]
+func GrantRoleHandler(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		ResError(c, CodeInvalidParams)
+		return
+	}
+
+	p := new(models.ParamRole)
+	if err := c.ShouldBindJSON(p); err != nil {
+		errs, ok := err.(validator.ValidationErrors)
+		if !ok {
+			ResError(c, CodeInvalidParams)
+			return
+		}
+		ResErrorWithMsg(c, CodeInvalidParams, removeStructName(errs.Translate(trans)))
+		return
+	}
+
+	if err := logic.GrantRole(userID, p.Role); err != nil {
+		zap.L().Error("logic.GrantRole() failed", zap.Error(err))
+		if errors.Is(err, mysql.ErrorUserNotExist) {
+			ResError(c, CodeUserNotFound)
+			return
+		}
+		ResError(c, CodeServerBusy)
+		return
+	}
+	ResSuccess(c, nil)
+}
+
+// @Summary 回收角色
+// @Description 回收指定用户的角色，仅管理员可用
+// @Tags 管理相关
+// @Accept json
+// @Produce json
+// @Param id path int true "用户ID"
+// @Param role path string true "角色：admin/moderator"
+// @Success 200 {object} controllers.RespData "回收成功"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/admin/users/{id}/roles/{role} [delete]
+func RevokeRoleHandler(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		ResError(c, CodeInvalidParams)
+		return
+	}
+
+	role := c.Param("role")
+	if role != models.RoleAdmin && role != models.RoleModerator {
+		ResError(c, CodeInvalidParams)
+		return
+	}
+
+	if err := logic.RevokeRole(userID, role); err != nil {
+		zap.L().Error("logic.RevokeRole() failed", zap.Error(err))
+		ResError(c, CodeServerBusy)
+		return
+	}
+	ResSuccess(c, nil)
+}
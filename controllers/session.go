@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"errors"
+	"land/dao/redis"
+	"land/logic"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// @Summary 会话列表
+// @Description 列出当前用户所有登录设备的会话，current为true的是发起请求的会话
+// @Tags 会话相关
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户token"
+// @Success 200 {object} controllers.RespData "会话列表"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/sessions [get]
+func SessionListHandler(c *gin.Context) {
+	userID, err := GetCurrentUserID(c)
+	if err != nil {
+		ResError(c, CodeNeedLogin)
+		return
+	}
+
+	sessions, err := logic.GetSessionList(userID, c.GetString(ContextSessionIDKey))
+	if err != nil {
+		zap.L().Error("logic.GetSessionList() failed", zap.Error(err))
+		ResError(c, CodeServerBusy)
+		return
+	}
+	ResSuccess(c, sessions)
+}
+
+// @Summary 吊销会话
+// @Description 吊销当前用户的指定会话，对应设备需要重新登录
+// @Tags 会话相关
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户token"
+// @Param id path string true "会话ID"
+// @Success 200 {object} controllers.RespData "吊销成功"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/sessions/{id} [delete]
+func RevokeSessionHandler(c *gin.Context) {
+	userID, err := GetCurrentUserID(c)
+	if err != nil {
+		ResError(c, CodeNeedLogin)
+		return
+	}
+
+	sessionID := c.Param("id")
+	if sessionID == "" {
+		ResError(c, CodeInvalidParams)
+		return
+	}
+
+	if err := logic.RevokeSession(userID, sessionID); err != nil {
+		zap.L().Error("logic.RevokeSession() failed", zap.String("session_id", sessionID), zap.Error(err))
+		if errors.Is(err, redis.ErrSessionNotFound) {
+			ResError(c, CodeNotFound)
+			return
+		}
+		ResError(c, CodeServerBusy)
+		return
+	}
+	ResSuccess(c, gin.H{"message": "会话已吊销"})
+}
+
+// @Summary 退出所有设备
+// @Description 吊销当前用户的全部会话，包括发起请求的会话
+// @Tags 会话相关
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户token"
+// @Success 200 {object} controllers.RespData "已退出所有设备"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/sessions [delete]
+func LogoutAllHandler(c *gin.Context) {
+	userID, err := GetCurrentUserID(c)
+	if err != nil {
+		ResError(c, CodeNeedLogin)
+		return
+	}
+
+	if err := logic.LogoutAll(userID); err != nil {
+		zap.L().Error("logic.LogoutAll() failed", zap.Error(err))
+		ResError(c, CodeServerBusy)
+		return
+	}
+	ResSuccess(c, gin.H{"message": "已退出所有设备"})
+}
@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"errors"
+	"land/logic"
+	"land/models"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// isTagError 判断是否为标签校验错误
+func isTagError(err error) bool {
+	return errors.Is(err, logic.ErrorInvalidTag) || errors.Is(err, logic.ErrorTooManyTags)
+}
+
+// @Summary 标签下的帖子
+// @Description 按时间、分数或访问量排序分页获取标签下的帖子
+// @Tags 标签相关
+// @Accept json
+// @Produce json
+// @Param name path string true "标签名"
+// @Param page query int false "页码，默认为1"
+// @Param size query int false "每页大小，默认为20，最大100"
+// @Param order query string false "排序方式：time(时间倒序), score(分数倒序), view(访问量倒序), hot/best/controversial/top"
+// @Param cursor query string false "上一页返回的next_cursor，指定后忽略page"
+// @Success 200 {object} controllers.RespData "帖子列表，total为标签下的帖子数，next_cursor为下一页的游标"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/tags/{name} [get]
+func TagPostListHandler(c *gin.Context) {
+	p := &models.ParamPostList{
+		Page:  1,
+		Size:  20,
+		Order: models.OrderTime,
+	}
+	if err := c.ShouldBindQuery(p); err != nil {
+		zap.L().Error("TagPostListHandler with invalid params", zap.Error(err))
+		ResError(c, CodeInvalidParams)
+		return
+	}
+	p.CommunityID = 0
+	p.ViewerID, _ = GetCurrentUserID(c)
+
+	data, err := logic.GetTagPostList(c.Param("name"), p)
+	if err != nil {
+		zap.L().Error("logic.GetTagPostList() failed", zap.String("tag", c.Param("name")), zap.Error(err))
+		if errors.Is(err, logic.ErrorTagNotExist) {
+			ResError(c, CodeNotFound)
+			return
+		}
+		if errors.Is(err, logic.ErrorInvalidCursor) {
+			ResErrorWithMsg(c, CodeInvalidParams, err.Error())
+			return
+		}
+		ResError(c, CodeServerBusy)
+		return
+	}
+	ResSuccess(c, data)
+}
+
+// @Summary 标签自动补全
+// @Description 按前缀匹配标签，已发布帖子多的在前，不返回没有已发布帖子的标签
+// @Tags 标签相关
+// @Accept json
+// @Produce json
+// @Param q query string true "标签前缀"
+// @Param limit query int false "返回数量，默认10，最大20"
+// @Success 200 {object} controllers.RespData "标签及帖子数"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/tags [get]
+func SuggestTagsHandler(c *gin.Context) {
+	p := new(models.ParamTagSuggest)
+	if err := c.ShouldBindQuery(p); err != nil {
+		zap.L().Error("SuggestTagsHandler with invalid params", zap.Error(err))
+		ResError(c, CodeInvalidParams)
+		return
+	}
+
+	data, err := logic.SuggestTags(p)
+	if err != nil {
+		zap.L().Error("logic.SuggestTags() failed", zap.Error(err))
+		ResError(c, CodeServerBusy)
+		return
+	}
+	ResSuccess(c, data)
+}
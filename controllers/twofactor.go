@@ -0,0 +1,209 @@
+package controllers
+
+import (
+	"errors"
+	"land/logic"
+	"land/models"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// @Summary 两步验证登录
+// @Description 开启两步验证的用户在密码校验通过后，提交登录挑战与认证器验证码（或恢复码）完成登录
+// @Tags 用户相关
+// @Accept json
+// @Produce json
+// @Param data body models.ParamTwoFactorLogin true "挑战令牌与验证码"
+// @Success 200 {object} controllers.RespData "登录成功，返回token"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /auth/login/2fa [post]
+func TwoFactorLoginHandler(c *gin.Context) {
+	p := new(models.ParamTwoFactorLogin)
+	if err := c.ShouldBindJSON(p); err != nil {
+		zap.L().Error("两步验证登录参数无效", zap.Error(err))
+		ResError(c, CodeInvalidParams)
+		return
+	}
+
+	user, tokens, err := logic.CompleteTwoFactorLogin(p, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		zap.L().Error("logic.CompleteTwoFactorLogin() failed", zap.Error(err))
+		if resLoginLocked(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, logic.ErrorChallengeInvalid), errors.Is(err, logic.ErrorTwoFactorNotEnabled):
+			ResError(c, CodeInvalidToken)
+		case errors.Is(err, logic.ErrorInvalidOTP):
+			ResError(c, CodeInvalidOTP)
+		default:
+			ResError(c, CodeServerBusy)
+		}
+		return
+	}
+
+	resTokens(c, user, tokens)
+}
+
+// @Summary 两步验证状态
+// @Description 查询当前用户是否开启两步验证及剩余恢复码数量
+// @Tags 两步验证
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户token"
+// @Success 200 {object} controllers.RespData "两步验证状态"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/2fa [get]
+func TwoFactorStatusHandler(c *gin.Context) {
+	userID, err := GetCurrentUserID(c)
+	if err != nil {
+		ResError(c, CodeNeedLogin)
+		return
+	}
+
+	status, err := logic.GetTwoFactorStatus(userID)
+	if err != nil {
+		zap.L().Error("logic.GetTwoFactorStatus() failed", zap.Error(err))
+		ResError(c, CodeServerBusy)
+		return
+	}
+	ResSuccess(c, status)
+}
+
+// @Summary 绑定认证器
+// @Description 生成TOTP密钥与otpauth链接，用认证器App扫码后调用确认接口才会启用
+// @Tags 两步验证
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户token"
+// @Success 200 {object} controllers.RespData "密钥与otpauth链接"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/2fa/enroll [post]
+func TwoFactorEnrollHandler(c *gin.Context) {
+	userID, err := GetCurrentUserID(c)
+	if err != nil {
+		ResError(c, CodeNeedLogin)
+		return
+	}
+
+	enrollment, err := logic.EnrollTwoFactor(userID)
+	if err != nil {
+		zap.L().Error("logic.EnrollTwoFactor() failed", zap.Error(err))
+		if errors.Is(err, logic.ErrorTwoFactorEnabled) {
+			ResError(c, CodeTwoFactorEnabled)
+			return
+		}
+		ResError(c, CodeServerBusy)
+		return
+	}
+	ResSuccess(c, enrollment)
+}
+
+// @Summary 确认绑定
+// @Description 提交认证器生成的验证码启用两步验证，返回的恢复码只显示这一次
+// @Tags 两步验证
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户token"
+// @Param data body models.ParamTwoFactorCode true "验证码"
+// @Success 200 {object} controllers.RespData "恢复码"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/2fa/confirm [post]
+func TwoFactorConfirmHandler(c *gin.Context) {
+	userID, p, ok := bindTwoFactorCode(c)
+	if !ok {
+		return
+	}
+
+	codes, err := logic.ConfirmTwoFactor(userID, p.Code)
+	if err != nil {
+		zap.L().Error("logic.ConfirmTwoFactor() failed", zap.Error(err))
+		resTwoFactorError(c, err)
+		return
+	}
+	ResSuccess(c, gin.H{"recovery_codes": codes})
+}
+
+// @Summary 关闭两步验证
+// @Description 提交认证器验证码或恢复码关闭两步验证
+// @Tags 两步验证
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户token"
+// @Param data body models.ParamTwoFactorCode true "验证码或恢复码"
+// @Success 200 {object} controllers.RespData "关闭成功"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/2fa/disable [post]
+func TwoFactorDisableHandler(c *gin.Context) {
+	userID, p, ok := bindTwoFactorCode(c)
+	if !ok {
+		return
+	}
+
+	if err := logic.DisableTwoFactor(userID, p.Code, c.ClientIP()); err != nil {
+		zap.L().Error("logic.DisableTwoFactor() failed", zap.Error(err))
+		resTwoFactorError(c, err)
+		return
+	}
+	ResSuccess(c, gin.H{"message": "已关闭两步验证"})
+}
+
+// @Summary 重新生成恢复码
+// @Description 作废所有旧恢复码并生成新的一组，返回的恢复码只显示这一次
+// @Tags 两步验证
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer 用户token"
+// @Param data body models.ParamTwoFactorCode true "验证码或恢复码"
+// @Success 200 {object} controllers.RespData "新的恢复码"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /api/v1/2fa/recovery-codes [post]
+func RegenerateRecoveryCodesHandler(c *gin.Context) {
+	userID, p, ok := bindTwoFactorCode(c)
+	if !ok {
+		return
+	}
+
+	codes, err := logic.RegenerateRecoveryCodes(userID, p.Code, c.ClientIP())
+	if err != nil {
+		zap.L().Error("logic.RegenerateRecoveryCodes() failed", zap.Error(err))
+		resTwoFactorError(c, err)
+		return
+	}
+	ResSuccess(c, gin.H{"recovery_codes": codes})
+}
+
+// bindTwoFactorCode 获取当前用户并解析验证码参数，失败时已写入响应
+func bindTwoFactorCode(c *gin.Context) (uint64, *models.ParamTwoFactorCode, bool) {
+	userID, err := GetCurrentUserID(c)
+	if err != nil {
+		ResError(c, CodeNeedLogin)
+		return 0, nil, false
+	}
+
+	p := new(models.ParamTwoFactorCode)
+	if err = c.ShouldBindJSON(p); err != nil {
+		zap.L().Error("两步验证参数无效", zap.Error(err))
+		ResError(c, CodeInvalidParams)
+		return 0, nil, false
+	}
+	return userID, p, true
+}
+
+// resTwoFactorError 将两步验证相关的错误转换为响应码
+func resTwoFactorError(c *gin.Context, err error) {
+	if resLoginLocked(c, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, logic.ErrorInvalidOTP):
+		ResError(c, CodeInvalidOTP)
+	case errors.Is(err, logic.ErrorTwoFactorEnabled):
+		ResError(c, CodeTwoFactorEnabled)
+	case errors.Is(err, logic.ErrorTwoFactorNotEnabled):
+		ResError(c, CodeTwoFactorNotEnabled)
+	default:
+		ResError(c, CodeServerBusy)
+	}
+}
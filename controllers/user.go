@@ -4,15 +4,13 @@ import (
 	"errors"
 	"fmt"
 	"land/dao/mysql"
-	"land/dao/redis"
 	"land/logic"
 	"land/models"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 
-	"time"
-
 	"go.uber.org/zap"
 )
 
@@ -66,7 +64,7 @@ func SignUpHandler(c *gin.Context) {
 }
 
 // @Summary 用户登录
-// @Description 用户登录接口，登录成功返回token，失败返回错误信息
+// @Description 用户登录接口，登录成功返回token，失败返回错误信息；开启两步验证的用户返回two_factor_required与challenge_token
 // @Tags 用户相关
 // @Accept json
 // @Produce json
@@ -89,9 +87,12 @@ func LoginHandler(c *gin.Context) {
 	}
 
 	// 登录逻辑
-	user, err := logic.Login(p)
+	user, err := logic.Login(p, c.ClientIP())
 	if err != nil {
 		zap.L().Error("登录逻辑处理失败", zap.Error(err))
+		if resLoginLocked(c, err) {
+			return
+		}
 		if errors.Is(err, mysql.ErrorUserNotExist) {
 			ResError(c, CodeUserNotFound)
 			return
@@ -100,34 +101,103 @@ func LoginHandler(c *gin.Context) {
 		return
 	}
 
-	// 幂等：先查Redis是否有token且有效，有则直接返回
-	redisToken, err := redis.GetJWTToken(int64(user.UserID))
-	if err == nil && redisToken != "" {
-		ResSuccess(c, gin.H{
-			"user_id":   fmt.Sprintf("%d", user.UserID),
-			"user_name": user.Username,
-			"token":     redisToken,
-		})
+	resLogin(c, user, p.Device)
+}
+
+// resLogin 密码或第三方校验通过后的统一响应
+// 开启两步验证的用户只返回登录挑战，需调用/auth/login/2fa完成登录，否则直接签发令牌
+func resLogin(c *gin.Context, user *models.User, device string) {
+	enabled, err := logic.TwoFactorEnabled(user.UserID)
+	if err != nil {
+		zap.L().Error("logic.TwoFactorEnabled() failed", zap.Error(err))
+		ResError(c, CodeServerBusy)
+		return
+	}
+	if enabled {
+		challenge, err := logic.CreateLoginChallenge(user, device)
+		if err != nil {
+			zap.L().Error("logic.CreateLoginChallenge() failed", zap.Error(err))
+			if resLoginLocked(c, err) {
+				return
+			}
+			ResError(c, CodeServerBusy)
+			return
+		}
+		ResSuccess(c, challenge)
 		return
 	}
 
-	// Redis无token或token失效，生成新token并存入
-	err = redis.SetJWTToken(int64(user.UserID), user.Token, 24*time.Hour)
+	// 签发access token与refresh token
+	tokens, err := logic.IssueTokenPair(user, device, c.ClientIP(), c.Request.UserAgent())
 	if err != nil {
-		zap.L().Error("SetJWTToken to redis failed", zap.Error(err))
+		zap.L().Error("logic.IssueTokenPair() failed", zap.Error(err))
 		ResError(c, CodeServerBusy)
 		return
 	}
+	resTokens(c, user, tokens)
+}
+
+// resLoginLocked 登录被锁定时返回剩余锁定秒数
+// 返回值:
+//   - bool: err是否为锁定错误，是则已写入响应
+func resLoginLocked(c *gin.Context, err error) bool {
+	var lockErr *logic.LoginLockedError
+	if !errors.As(err, &lockErr) {
+		return false
+	}
+	c.JSON(http.StatusOK, Res(CodeLoginLocked, CodeLoginLocked.Msg(), gin.H{
+		"retry_after": int64(lockErr.RetryAfter.Seconds()),
+	}))
+	return true
+}
 
+// resTokens 返回登录成功的用户信息与令牌对
+func resTokens(c *gin.Context, user *models.User, tokens *models.TokenPair) {
 	ResSuccess(c, gin.H{
-		"user_id":   fmt.Sprintf("%d", user.UserID),
-		"user_name": user.Username,
-		"token":     user.Token,
+		"user_id":       fmt.Sprintf("%d", user.UserID),
+		"user_name":     user.Username,
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
 	})
 }
 
+// @Summary 刷新令牌
+// @Description 使用refresh token换取新的access token与refresh token，旧的refresh token随即失效；重复使用已失效的refresh token会吊销整个会话
+// @Tags 用户相关
+// @Accept json
+// @Produce json
+// @Param data body models.ParamRefreshToken true "刷新参数"
+// @Success 200 {object} controllers.RespData "刷新成功，返回新的令牌对"
+// @Failure 400 {object} controllers.RespData "请求参数错误"
+// @Router /auth/refresh [post]
+func RefreshTokenHandler(c *gin.Context) {
+	p := new(models.ParamRefreshToken)
+	if err := c.ShouldBindJSON(p); err != nil {
+		zap.L().Error("刷新参数无效", zap.Error(err))
+		ResError(c, CodeInvalidParams)
+		return
+	}
+
+	tokens, err := logic.RefreshTokenPair(p.RefreshToken)
+	if err != nil {
+		zap.L().Error("logic.RefreshTokenPair() failed", zap.Error(err))
+		switch {
+		case errors.Is(err, logic.ErrorRefreshTokenReused):
+			ResError(c, CodeTokenReused)
+		case errors.Is(err, logic.ErrorRefreshTokenInvalid):
+			ResError(c, CodeInvalidToken)
+		default:
+			ResError(c, CodeServerBusy)
+		}
+		return
+	}
+
+	ResSuccess(c, tokens)
+}
+
 // @Summary 用户登出
-// @Description 用户登出接口，清除Redis中的JWT
+// @Description 用户登出接口，吊销当前会话
 // @Tags 用户相关
 // @Accept json
 // @Produce json
@@ -141,7 +211,7 @@ func LogoutHandler(c *gin.Context) {
 		ResError(c, CodeNeedLogin)
 		return
 	}
-	err = redis.DelJWTToken(int64(userID))
+	err = logic.Logout(userID, c.GetString(ContextSessionIDKey))
 	if err != nil {
 		ResError(c, CodeServerBusy)
 		return
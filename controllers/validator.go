@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"land/models"
 	"reflect"
+	"regexp"
 	"strings"
 
 	"github.com/gin-gonic/gin/binding"
@@ -18,6 +19,8 @@ import (
 // 翻译器
 var (
 	trans ut.Translator
+
+	usernameRegexp = regexp.MustCompile(`^[` + models.UsernameCharset + `]+$`)
 )
 
 // 初始化翻译器
@@ -37,6 +40,12 @@ func TransInit(locale string) (err error) {
 
 		// 为SignUpParams结构体注册自定义校验方法
 		v.RegisterStructValidation(SignUpParamsValidation, models.SignUpForm{})
+		// 用户名只能使用@提及可以匹配的字符
+		if err = v.RegisterValidation("username", func(fl validator.FieldLevel) bool {
+			return usernameRegexp.MatchString(fl.Field().String())
+		}); err != nil {
+			return err
+		}
 
 		// 翻译
 		var ok bool
@@ -1,6 +1,8 @@
 package controllers
 
 import (
+	"errors"
+	"land/dao/mysql"
 	"land/logic"
 	"land/models"
 
@@ -39,6 +41,10 @@ func PostVoteController(c *gin.Context) {
 	// 具体投票的业务逻辑
 	if err := logic.VoteForPost(userID, p); err != nil {
 		zap.L().Error("logic.VoteForPost() failed", zap.Error(err))
+		if errors.Is(err, mysql.ErrorInvalidID) {
+			ResError(c, CodeNotFound)
+			return
+		}
 		ResError(c, CodeServerBusy)
 		return
 	}
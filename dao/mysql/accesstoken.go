@@ -0,0 +1,106 @@
+package mysql
+
+import (
+	"errors"
+	"land/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// InsertAccessToken 保存个人访问令牌
+// 参数:
+//   - token: 访问令牌
+//
+// 返回值:
+//   - err: 可能的错误
+func InsertAccessToken(token *models.AccessToken) error {
+	return db.Create(token).Error
+}
+
+// GetAccessTokenByHash 根据令牌哈希获取个人访问令牌
+// 参数:
+//   - tokenHash: 令牌哈希
+//
+// 返回值:
+//   - token: 访问令牌
+//   - err: 不存在时返回ErrorAccessTokenNotExist
+func GetAccessTokenByHash(tokenHash string) (token *models.AccessToken, err error) {
+	token = &models.AccessToken{}
+	err = db.Where("token_hash = ?", tokenHash).First(token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrorAccessTokenNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// GetAccessToken 获取用户的指定个人访问令牌
+// 参数:
+//   - userID: 用户ID
+//   - tokenID: 令牌ID
+//
+// 返回值:
+//   - token: 访问令牌
+//   - err: 不存在或不属于该用户时返回ErrorAccessTokenNotExist
+func GetAccessToken(userID, tokenID uint64) (token *models.AccessToken, err error) {
+	token = &models.AccessToken{}
+	err = db.Where("id = ? AND user_id = ?", tokenID, userID).First(token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrorAccessTokenNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// GetUserAccessTokens 获取用户的全部个人访问令牌
+// 参数:
+//   - userID: 用户ID
+//
+// 返回值:
+//   - tokens: 访问令牌列表，按创建时间倒序
+//   - err: 可能的错误
+func GetUserAccessTokens(userID uint64) (tokens []*models.AccessToken, err error) {
+	err = db.Where("user_id = ?", userID).Order("create_time DESC").Find(&tokens).Error
+	return
+}
+
+// CountUserAccessTokens 统计用户的个人访问令牌数量
+// 参数:
+//   - userID: 用户ID
+//
+// 返回值:
+//   - count: 数量
+//   - err: 可能的错误
+func CountUserAccessTokens(userID uint64) (count int64, err error) {
+	err = db.Model(&models.AccessToken{}).Where("user_id = ?", userID).Count(&count).Error
+	return
+}
+
+// DeleteAccessToken 删除（吊销）个人访问令牌
+// 参数:
+//   - userID: 用户ID
+//   - tokenID: 令牌ID
+//
+// 返回值:
+//   - err: 可能的错误
+func DeleteAccessToken(userID, tokenID uint64) error {
+	return db.Where("id = ? AND user_id = ?", tokenID, userID).Delete(&models.AccessToken{}).Error
+}
+
+// TouchAccessToken 记录个人访问令牌的最近使用时间与IP
+// 参数:
+//   - tokenID: 令牌ID
+//   - ip: 客户端IP
+//
+// 返回值:
+//   - err: 可能的错误
+func TouchAccessToken(tokenID uint64, ip string) error {
+	return db.Model(&models.AccessToken{}).
+		Where("id = ?", tokenID).
+		Updates(map[string]interface{}{"last_used_time": time.Now(), "last_used_ip": ip}).Error
+}
@@ -0,0 +1,146 @@
+package mysql
+
+import (
+	"land/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GetAllPostsByAuthor 获取用户发布的全部帖子，用于数据导出与注销
+// 参数:
+//   - authorID: 作者ID
+//
+// 返回值:
+//   - posts: 帖子列表
+//   - err: 可能的错误
+func GetAllPostsByAuthor(authorID uint64) (posts []*models.Post, err error) {
+	err = db.Where("author_id = ?", authorID).Order("create_time").Find(&posts).Error
+	return
+}
+
+// GetAllCommentsByAuthor 获取用户发表的全部评论，用于数据导出
+// 参数:
+//   - authorID: 作者ID
+//
+// 返回值:
+//   - comments: 评论列表
+//   - err: 可能的错误
+func GetAllCommentsByAuthor(authorID uint64) (comments []*models.Comment, err error) {
+	err = db.Where("author_id = ?", authorID).Order("create_time").Find(&comments).Error
+	return
+}
+
+// MarkUserDeleted 注销用户：标记注销时间，删除草稿，匿名化帖子与评论并删除帖子的修订记录，删除关注、拉黑关系与登录凭据
+// 用户行在宽限期满后由PurgeUser删除
+// 参数:
+//   - userID: 用户ID
+//   - now: 注销时间
+//
+// 返回值:
+//   - err: 可能的错误
+func MarkUserDeleted(userID uint64, now time.Time) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.User{}).
+			Where("user_id = ? AND delete_time IS NULL", userID).
+			Updates(map[string]interface{}{
+				"delete_time": now,
+				"update_time": now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrorUserNotExist
+		}
+
+		if err := tx.Model(&models.Post{}).
+			Where("author_id = ?", userID).
+			Updates(map[string]interface{}{
+				"title":        models.AnonymizedContent,
+				"content":      models.AnonymizedContent,
+				"content_html": "", // 读取时按匿名内容重新渲染
+				"update_time":  now,
+			}).Error; err != nil {
+			return err
+		}
+		// 未发布的草稿与定时帖子直接删除，避免注销后被定时发布
+		if err := tx.Where("post_id IN (?)",
+			tx.Model(&models.Post{}).Select("post_id").Where("author_id = ? AND status IN ?", userID, unpublishedStatus)).
+			Delete(&models.PostTag{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("author_id = ? AND status IN ?", userID, unpublishedStatus).
+			Delete(&models.Post{}).Error; err != nil {
+			return err
+		}
+		// 修订记录保存着帖子的历史内容，一并删除
+		if err := tx.Where("post_id IN (?)",
+			tx.Model(&models.Post{}).Select("post_id").Where("author_id = ?", userID)).
+			Delete(&models.PostRevision{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Comment{}).
+			Where("author_id = ?", userID).
+			Updates(map[string]interface{}{
+				"content":      models.AnonymizedContent,
+				"content_html": "",
+				"update_time":  now,
+			}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("follower_id = ? OR followee_id = ?", userID, userID).
+			Delete(&models.UserFollow{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ? OR target_id = ?", userID, userID).
+			Delete(&models.UserBlock{}).Error; err != nil {
+			return err
+		}
+
+		// 登录凭据立即删除，保证注销后无法再以任何方式登录
+		for _, m := range []interface{}{
+			&models.UserIdentity{},
+			&models.AccessToken{},
+			&models.UserTwoFactor{},
+			&models.RecoveryCode{},
+		} {
+			if err := tx.Where("user_id = ?", userID).Delete(m).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetUsersToPurge 获取注销时间早于指定时间的用户ID
+// 参数:
+//   - before: 截止时间
+//   - limit: 最多返回的数量
+//
+// 返回值:
+//   - ids: 用户ID列表
+//   - err: 可能的错误
+func GetUsersToPurge(before time.Time, limit int) (ids []uint64, err error) {
+	err = db.Model(&models.User{}).
+		Where("delete_time IS NOT NULL AND delete_time < ?", before).
+		Limit(limit).
+		Pluck("user_id", &ids).Error
+	return
+}
+
+// PurgeUser 彻底删除已注销用户的个人信息
+// 参数:
+//   - userID: 用户ID
+//
+// 返回值:
+//   - err: 可能的错误
+func PurgeUser(userID uint64) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserRole{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ? AND delete_time IS NOT NULL", userID).Delete(&models.User{}).Error
+	})
+}
@@ -0,0 +1,89 @@
+package mysql
+
+import (
+	"land/models"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// InsertUserBlock 添加拉黑/屏蔽关系，已存在时不做任何修改
+// 参数:
+//   - userID: 操作者ID
+//   - targetID: 对象ID
+//   - kind: 关系类型
+//
+// 返回值:
+//   - err: 可能的错误
+func InsertUserBlock(userID, targetID uint64, kind uint8) error {
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.UserBlock{
+		UserID:     userID,
+		TargetID:   targetID,
+		Kind:       kind,
+		CreateTime: time.Now(),
+	}).Error
+}
+
+// DeleteUserBlock 解除拉黑/屏蔽
+// 参数:
+//   - userID: 操作者ID
+//   - targetID: 对象ID
+//   - kind: 关系类型
+//
+// 返回值:
+//   - err: 可能的错误
+func DeleteUserBlock(userID, targetID uint64, kind uint8) error {
+	return db.Where("user_id = ? AND target_id = ? AND kind = ?", userID, targetID, kind).
+		Delete(&models.UserBlock{}).Error
+}
+
+// GetUserBlockTargets 获取用户拉黑/屏蔽的全部用户ID
+// 参数:
+//   - userID: 操作者ID
+//   - kind: 关系类型
+//
+// 返回值:
+//   - ids: 对象ID列表
+//   - err: 可能的错误
+func GetUserBlockTargets(userID uint64, kind uint8) (ids []uint64, err error) {
+	err = db.Model(&models.UserBlock{}).
+		Where("user_id = ? AND kind = ?", userID, kind).
+		Pluck("target_id", &ids).Error
+	return
+}
+
+// GetUserBlockList 分页获取拉黑/屏蔽列表，按操作时间倒序
+// 参数:
+//   - userID: 操作者ID
+//   - kind: 关系类型
+//   - page: 页码
+//   - size: 每页数量
+//
+// 返回值:
+//   - users: 用户列表
+//   - err: 可能的错误
+func GetUserBlockList(userID uint64, kind uint8, page, size int64) (users []*models.UserBrief, err error) {
+	users = make([]*models.UserBrief, 0, size)
+	err = db.Table("user_block AS b").
+		Select("u.user_id, u.username, u.display_name, u.avatar").
+		Joins("JOIN user AS u ON u.user_id = b.target_id AND u.delete_time IS NULL").
+		Where("b.user_id = ? AND b.kind = ?", userID, kind).
+		Order("b.create_time DESC").
+		Offset(int((page - 1) * size)).
+		Limit(int(size)).
+		Scan(&users).Error
+	return
+}
+
+// CountUserBlocks 获取拉黑/屏蔽的人数
+// 参数:
+//   - userID: 操作者ID
+//   - kind: 关系类型
+//
+// 返回值:
+//   - count: 人数
+//   - err: 可能的错误
+func CountUserBlocks(userID uint64, kind uint8) (count int64, err error) {
+	err = db.Model(&models.UserBlock{}).Where("user_id = ? AND kind = ?", userID, kind).Count(&count).Error
+	return
+}
@@ -18,4 +18,13 @@ var (
 
 	// ErrorInsertFailed 表示插入数据失败的错误
 	ErrorInsertFailed = errors.New("插入数据失败")
+
+	// ErrorTwoFactorNotEnrolled 表示用户未绑定两步验证的错误
+	ErrorTwoFactorNotEnrolled = errors.New("未绑定两步验证")
+
+	// ErrorIdentityNotExist 表示第三方身份未绑定任何用户的错误
+	ErrorIdentityNotExist = errors.New("第三方身份未绑定")
+
+	// ErrorAccessTokenNotExist 表示个人访问令牌不存在的错误
+	ErrorAccessTokenNotExist = errors.New("访问令牌不存在")
 )
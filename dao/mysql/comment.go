@@ -1,10 +1,12 @@
 package mysql
 
 import (
+	"errors"
 	"land/models"
 	"time"
 
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 func CreateComment(comment *models.Comment) error {
@@ -19,11 +21,72 @@ func CreateComment(comment *models.Comment) error {
 	return nil
 }
 
+// publishedComments 只保留所属帖子已发布的评论，帖子删除后其评论随之隐藏
+func publishedComments() *gorm.DB {
+	return db.Table("comment AS c").
+		Joins("JOIN post AS p ON p.post_id = c.post_id AND p.status = ?", models.PostStatusPublished)
+}
+
 func GetCommentListByIDs(ids []string) ([]*models.Comment, error) {
 	commentList := make([]*models.Comment, 0)
-	if err := db.Where("comment_id IN ?", ids).Find(&commentList).Error; err != nil {
+	if err := publishedComments().Select("c.*").Where("c.comment_id IN ?", ids).Find(&commentList).Error; err != nil {
 		zap.L().Error("failed to get comment list", zap.Error(err))
 		return nil, err
 	}
 	return commentList, nil
 }
+
+// GetCommentListByAuthor 获取指定用户在已发布帖子下发表的评论，按时间倒序
+// 参数:
+//   - authorID: 作者ID
+//   - page: 页码
+//   - size: 每页数量
+//
+// 返回值:
+//   - comments: 评论列表
+//   - err: 可能的错误
+func GetCommentListByAuthor(authorID uint64, page, size int64) ([]*models.Comment, error) {
+	comments := make([]*models.Comment, 0, size)
+	err := publishedComments().Select("c.*").
+		Where("c.author_id = ?", authorID).
+		Order("c.create_time DESC").
+		Offset(int((page - 1) * size)).
+		Limit(int(size)).
+		Find(&comments).Error
+	if err != nil {
+		zap.L().Error("failed to get comment list by author", zap.Error(err))
+		return nil, err
+	}
+	return comments, nil
+}
+
+// GetCommentCountByAuthor 获取指定用户在已发布帖子下发表的评论数量
+// 参数:
+//   - authorID: 作者ID
+//
+// 返回值:
+//   - count: 评论数量
+//   - err: 可能的错误
+func GetCommentCountByAuthor(authorID uint64) (count int64, err error) {
+	err = publishedComments().Where("c.author_id = ?", authorID).Count(&count).Error
+	return
+}
+
+// GetCommentByID 根据评论ID获取评论
+// 参数:
+//   - commentID: 评论ID
+//
+// 返回值:
+//   - comment: 评论
+//   - err: 评论不存在时返回ErrorInvalidID
+func GetCommentByID(commentID uint64) (*models.Comment, error) {
+	comment := new(models.Comment)
+	err := db.Where("comment_id = ?", commentID).First(comment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrorInvalidID
+	}
+	if err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
@@ -0,0 +1,132 @@
+package mysql
+
+import (
+	"land/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 草稿与定时发布的帖子都还未公开
+var unpublishedStatus = []uint8{models.PostStatusDraft, models.PostStatusScheduled}
+
+// GetDraftsByAuthor 分页获取作者的草稿与定时帖子，按最后修改时间倒序
+// 参数:
+//   - authorID: 作者ID
+//   - page: 页码
+//   - size: 每页数量
+//
+// 返回值:
+//   - posts: 帖子列表
+//   - err: 可能的错误
+func GetDraftsByAuthor(authorID uint64, page, size int64) (posts []*models.Post, err error) {
+	posts = make([]*models.Post, 0, size)
+	err = db.Where("author_id = ? AND status IN ?", authorID, unpublishedStatus).
+		Order("update_time DESC").
+		Offset(int((page - 1) * size)).
+		Limit(int(size)).
+		Find(&posts).Error
+	return
+}
+
+// CountDraftsByAuthor 获取作者的草稿与定时帖子数量
+// 参数:
+//   - authorID: 作者ID
+//
+// 返回值:
+//   - count: 数量
+//   - err: 可能的错误
+func CountDraftsByAuthor(authorID uint64) (count int64, err error) {
+	err = db.Model(&models.Post{}).
+		Where("author_id = ? AND status IN ?", authorID, unpublishedStatus).
+		Count(&count).Error
+	return
+}
+
+// UpdateDraft 修改作者的草稿或定时帖子
+// 参数:
+//   - post: 帖子信息，需要post_id、author_id、标题、内容、社区、状态和定时发布时间
+//
+// 返回值:
+//   - err: 帖子不存在、不属于该作者或已发布时返回ErrorInvalidID
+func UpdateDraft(post *models.Post) error {
+	result := db.Model(&models.Post{}).
+		Where("post_id = ? AND author_id = ? AND status IN ?", post.PostID, post.AuthorID, unpublishedStatus).
+		Updates(map[string]interface{}{
+			"title":        post.Title,
+			"content":      post.Content,
+			"content_html": post.ContentHTML,
+			"community_id": post.CommunityID,
+			"status":       post.Status,
+			"publish_time": post.PublishTime,
+			"update_time":  time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrorInvalidID
+	}
+	return nil
+}
+
+// DeleteDraft 删除作者的草稿或定时帖子，未发布过的帖子直接删除
+// 参数:
+//   - postID: 帖子ID
+//   - authorID: 作者ID
+//
+// 返回值:
+//   - err: 帖子不存在、不属于该作者或已发布时返回ErrorInvalidID
+func DeleteDraft(postID, authorID uint64) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("post_id = ? AND author_id = ? AND status IN ?", postID, authorID, unpublishedStatus).
+			Delete(&models.Post{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrorInvalidID
+		}
+		return tx.Where("post_id = ?", postID).Delete(&models.PostTag{}).Error
+	})
+}
+
+// PublishPost 发布草稿或定时帖子，发布时间同时作为帖子的create_time，列表排序与投票期限都以此为准
+// 多实例同时发布同一篇帖子时只有一个会成功
+// 参数:
+//   - postID: 帖子ID
+//   - now: 发布时间
+//
+// 返回值:
+//   - bool: 是否由本次调用发布
+//   - err: 可能的错误
+func PublishPost(postID uint64, now time.Time) (bool, error) {
+	result := db.Model(&models.Post{}).
+		Where("post_id = ? AND status IN ?", postID, unpublishedStatus).
+		Updates(map[string]interface{}{
+			"status":       models.PostStatusPublished,
+			"create_time":  now,
+			"update_time":  now,
+			"publish_time": now,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetDuePosts 获取发布时间已到的定时帖子
+// 参数:
+//   - now: 当前时间
+//   - limit: 最多返回的数量
+//
+// 返回值:
+//   - posts: 帖子列表
+//   - err: 可能的错误
+func GetDuePosts(now time.Time, limit int) (posts []*models.Post, err error) {
+	err = db.Where("status = ? AND publish_time <= ?", models.PostStatusScheduled, now).
+		Order("publish_time").
+		Limit(limit).
+		Find(&posts).Error
+	return
+}
@@ -0,0 +1,160 @@
+package mysql
+
+import (
+	"land/models"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// InsertFollow 添加关注关系，已关注时不做任何修改
+// 参数:
+//   - followerID: 关注者ID
+//   - followeeID: 被关注者ID
+//
+// 返回值:
+//   - bool: 是否新增了关注关系
+//   - err: 可能的错误
+func InsertFollow(followerID, followeeID uint64) (bool, error) {
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.UserFollow{
+		FollowerID: followerID,
+		FolloweeID: followeeID,
+		CreateTime: time.Now(),
+	})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// DeleteFollow 取消关注
+// 参数:
+//   - followerID: 关注者ID
+//   - followeeID: 被关注者ID
+//
+// 返回值:
+//   - bool: 是否有记录被删除
+//   - err: 可能的错误
+func DeleteFollow(followerID, followeeID uint64) (bool, error) {
+	result := db.Where("follower_id = ? AND followee_id = ?", followerID, followeeID).Delete(&models.UserFollow{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// CountFollowers 获取粉丝数
+// 参数:
+//   - userID: 用户ID
+//
+// 返回值:
+//   - count: 粉丝数
+//   - err: 可能的错误
+func CountFollowers(userID uint64) (count int64, err error) {
+	err = db.Model(&models.UserFollow{}).Where("followee_id = ?", userID).Count(&count).Error
+	return
+}
+
+// CountFollowing 获取关注数
+// 参数:
+//   - userID: 用户ID
+//
+// 返回值:
+//   - count: 关注数
+//   - err: 可能的错误
+func CountFollowing(userID uint64) (count int64, err error) {
+	err = db.Model(&models.UserFollow{}).Where("follower_id = ?", userID).Count(&count).Error
+	return
+}
+
+// GetFollowingIDs 获取用户关注的全部用户ID
+// 参数:
+//   - userID: 用户ID
+//
+// 返回值:
+//   - ids: 被关注者ID列表
+//   - err: 可能的错误
+func GetFollowingIDs(userID uint64) (ids []uint64, err error) {
+	err = db.Model(&models.UserFollow{}).Where("follower_id = ?", userID).Pluck("followee_id", &ids).Error
+	return
+}
+
+// GetFollowerIDsAfter 按follower_id升序分批获取粉丝ID，用于推送时间线
+// 参数:
+//   - userID: 被关注者ID
+//   - afterID: 上一批最后一个粉丝ID，首批传0
+//   - limit: 每批数量
+//
+// 返回值:
+//   - ids: 粉丝ID列表
+//   - err: 可能的错误
+func GetFollowerIDsAfter(userID, afterID uint64, limit int) (ids []uint64, err error) {
+	err = db.Model(&models.UserFollow{}).
+		Where("followee_id = ? AND follower_id > ?", userID, afterID).
+		Order("follower_id").
+		Limit(limit).
+		Pluck("follower_id", &ids).Error
+	return
+}
+
+// GetFollowerList 分页获取粉丝，按关注时间倒序
+// 参数:
+//   - userID: 被关注者ID
+//   - page: 页码
+//   - size: 每页数量
+//
+// 返回值:
+//   - users: 粉丝列表
+//   - err: 可能的错误
+func GetFollowerList(userID uint64, page, size int64) (users []*models.UserBrief, err error) {
+	users = make([]*models.UserBrief, 0, size)
+	err = db.Table("user_follow AS f").
+		Select("u.user_id, u.username, u.display_name, u.avatar").
+		Joins("JOIN user AS u ON u.user_id = f.follower_id AND u.delete_time IS NULL").
+		Where("f.followee_id = ?", userID).
+		Order("f.create_time DESC").
+		Offset(int((page - 1) * size)).
+		Limit(int(size)).
+		Scan(&users).Error
+	return
+}
+
+// GetFollowingList 分页获取关注的用户，按关注时间倒序
+// 参数:
+//   - userID: 关注者ID
+//   - page: 页码
+//   - size: 每页数量
+//
+// 返回值:
+//   - users: 被关注者列表
+//   - err: 可能的错误
+func GetFollowingList(userID uint64, page, size int64) (users []*models.UserBrief, err error) {
+	users = make([]*models.UserBrief, 0, size)
+	err = db.Table("user_follow AS f").
+		Select("u.user_id, u.username, u.display_name, u.avatar").
+		Joins("JOIN user AS u ON u.user_id = f.followee_id AND u.delete_time IS NULL").
+		Where("f.follower_id = ?", userID).
+		Order("f.create_time DESC").
+		Offset(int((page - 1) * size)).
+		Limit(int(size)).
+		Scan(&users).Error
+	return
+}
+
+// GetRecentPostsByAuthor 获取作者最近发布的帖子ID与发布时间，用于重建时间线
+// 参数:
+//   - authorID: 作者ID
+//   - limit: 最多返回的数量
+//
+// 返回值:
+//   - posts: 只包含post_id与create_time的帖子列表
+//   - err: 可能的错误
+func GetRecentPostsByAuthor(authorID uint64, limit int) (posts []*models.Post, err error) {
+	err = db.Model(&models.Post{}).
+		Select("post_id, create_time").
+		Where("author_id = ? AND status = ?", authorID, models.PostStatusPublished).
+		Order("create_time DESC").
+		Limit(limit).
+		Find(&posts).Error
+	return
+}
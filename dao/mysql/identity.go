@@ -0,0 +1,86 @@
+package mysql
+
+import (
+	"errors"
+	"land/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GetIdentity 根据提供方与subject获取第三方身份
+// 参数:
+//   - provider: 提供方名称
+//   - subject: 提供方内的用户标识
+//
+// 返回值:
+//   - identity: 第三方身份
+//   - err: 未绑定时返回ErrorIdentityNotExist
+func GetIdentity(provider, subject string) (identity *models.UserIdentity, err error) {
+	identity = &models.UserIdentity{}
+	err = db.Where("provider = ? AND subject = ?", provider, subject).First(identity).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrorIdentityNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+// GetUserIdentities 获取用户绑定的全部第三方身份
+// 参数:
+//   - userID: 用户ID
+//
+// 返回值:
+//   - identities: 第三方身份列表
+//   - err: 可能的错误
+func GetUserIdentities(userID uint64) (identities []*models.UserIdentity, err error) {
+	err = db.Where("user_id = ?", userID).Order("id").Find(&identities).Error
+	return
+}
+
+// InsertIdentity 绑定第三方身份
+// 参数:
+//   - identity: 第三方身份
+//
+// 返回值:
+//   - err: 可能的错误
+func InsertIdentity(identity *models.UserIdentity) error {
+	return db.Create(identity).Error
+}
+
+// InsertUserWithIdentity 在同一事务中创建用户并绑定第三方身份
+// 参数:
+//   - user: 新用户
+//   - identity: 第三方身份
+//
+// 返回值:
+//   - err: 可能的错误
+func InsertUserWithIdentity(user *models.User, identity *models.UserIdentity) error {
+	user.CreateTime = time.Now()
+	user.UpdateTime = user.CreateTime
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		identity.UserID = user.UserID
+		return tx.Create(identity).Error
+	})
+}
+
+// DeleteIdentity 解绑用户在指定提供方的身份
+// 参数:
+//   - userID: 用户ID
+//   - provider: 提供方名称
+//
+// 返回值:
+//   - bool: 是否有记录被删除
+//   - err: 可能的错误
+func DeleteIdentity(userID uint64, provider string) (bool, error) {
+	result := db.Where("user_id = ? AND provider = ?", userID, provider).Delete(&models.UserIdentity{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -6,16 +6,24 @@ import (
 	"time"
 
 	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-// CreatePost 创建新帖子
+// CreatePost 创建新帖子，同时写入标签
 // 参数:
-//   - p: 帖子信息
+//   - p: 帖子信息，tags为规范化后的标签
 //
 // 返回值:
 //   - err: 可能的错误
 func CreatePost(p *models.Post) error {
-	if err := db.Create(p).Error; err != nil {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(p).Error; err != nil {
+			return err
+		}
+		return insertPostTags(tx, p.PostID, p.Tags)
+	})
+	if err != nil {
 		zap.L().Error("CreatePost failed", zap.Error(err))
 		return err
 	}
@@ -31,7 +39,7 @@ func CreatePost(p *models.Post) error {
 //   - err: 可能的错误
 func GetPostByID(pid uint64) (post *models.Post, err error) {
 	post = &models.Post{}
-	if err = db.Where("post_id = ?", pid).First(post).Error; err != nil {
+	if err = db.Where("post_id = ? AND status = ?", pid, models.PostStatusPublished).First(post).Error; err != nil {
 		// 检查是否是记录不存在的错误
 		if err.Error() == "record not found" {
 			return nil, ErrorInvalidID
@@ -54,7 +62,8 @@ func GetPostList(page, size int64) (posts []*models.Post, err error) {
 	offset := (page - 1) * size
 
 	err = db.Model(&models.Post{}).
-		Select("post_id, title, content, author_id, community_id, create_time").
+		Select("post_id, title, content, content_html, author_id, community_id, create_time").
+		Where("status = ?", models.PostStatusPublished).
 		Order("create_time DESC").
 		Offset(int(offset)).
 		Limit(int(size)).
@@ -68,35 +77,45 @@ func GetPostList(page, size int64) (posts []*models.Post, err error) {
 }
 
 // GetPostListByOrder 根据排序方式获取帖子列表（利用数据库索引）
+// 指定after时按 (排序字段, post_id) 从游标位置之后开始查询，走索引定位而不用OFFSET
 // 参数:
 //   - order: 排序方式 (time/view)
-//   - page: 页码
+//   - page: 页码，指定after时忽略
 //   - size: 每页数量
 //   - communityID: 社区ID（可选，0表示所有社区）
+//   - after: 上一页最后一个帖子的位置（可选）
 //
 // 返回值:
 //   - posts: 帖子列表
 //   - err: 可能的错误
-func GetPostListByOrder(order string, page, size int64, communityID uint64) (posts []*models.Post, err error) {
+func GetPostListByOrder(order string, page, size int64, communityID uint64, after *models.PostCursor) (posts []*models.Post, err error) {
 	posts = make([]*models.Post, 0, size)
 	offset := (page - 1) * size
 
 	query := db.Model(&models.Post{}).
-		Select("post_id, title, content, author_id, community_id, create_time, view_count")
+		Select("post_id, title, content, content_html, author_id, community_id, create_time, view_count").
+		Where("status = ?", models.PostStatusPublished)
 
 	// 如果指定了社区ID，添加社区筛选条件
 	if communityID > 0 {
 		query = query.Where("community_id = ?", communityID)
 	}
 
-	// 根据排序方式设置排序字段，利用数据库索引
-	switch order {
-	case "time":
-		query = query.Order("create_time DESC")
-	case "view":
-		query = query.Order("view_count DESC, create_time DESC") // 访问量相同时按时间排序
-	default:
-		query = query.Order("create_time DESC") // 默认按时间排序
+	// 根据排序方式设置排序字段，利用数据库索引，排序字段相同时按帖子ID排序保证顺序稳定
+	sortColumn := "create_time"
+	if order == "view" {
+		sortColumn = "view_count"
+	}
+	query = query.Order(sortColumn + " DESC, post_id DESC")
+
+	if after != nil {
+		var sortValue interface{} = time.Unix(int64(after.Sort), 0)
+		if order == "view" {
+			sortValue = int64(after.Sort)
+		}
+		query = query.Where("("+sortColumn+" < ? OR ("+sortColumn+" = ? AND post_id < ?))",
+			sortValue, sortValue, after.PostID)
+		offset = 0
 	}
 
 	err = query.Offset(int(offset)).Limit(int(size)).Find(&posts).Error
@@ -121,7 +140,7 @@ func GetPostListByOrder(order string, page, size int64, communityID uint64) (pos
 //   - count: 帖子总数
 //   - err: 可能的错误
 func GetPostCount(communityID uint64) (count int64, err error) {
-	query := db.Model(&models.Post{})
+	query := db.Model(&models.Post{}).Where("status = ?", models.PostStatusPublished)
 
 	if communityID > 0 {
 		query = query.Where("community_id = ?", communityID)
@@ -138,6 +157,49 @@ func GetPostCount(communityID uint64) (count int64, err error) {
 	return count, nil
 }
 
+// GetPostListByAuthor 获取指定用户发布的帖子，按发布时间倒序
+// 参数:
+//   - authorID: 作者ID
+//   - page: 页码
+//   - size: 每页数量
+//
+// 返回值:
+//   - posts: 帖子列表
+//   - err: 可能的错误
+func GetPostListByAuthor(authorID uint64, page, size int64) (posts []*models.Post, err error) {
+	posts = make([]*models.Post, 0, size)
+	offset := (page - 1) * size
+
+	err = db.Model(&models.Post{}).
+		Select("post_id, title, content, content_html, author_id, community_id, create_time, view_count").
+		Where("author_id = ? AND status = ?", authorID, models.PostStatusPublished).
+		Order("create_time DESC").
+		Offset(int(offset)).
+		Limit(int(size)).
+		Find(&posts).Error
+	if err != nil {
+		zap.L().Error("GetPostListByAuthor failed",
+			zap.Int64("author_id", int64(authorID)),
+			zap.Error(err))
+		return nil, err
+	}
+	return posts, nil
+}
+
+// GetPostIDsByAuthor 获取指定用户已发布的全部帖子ID，用于构建Redis中的作者帖子集合
+// 参数:
+//   - authorID: 作者ID
+//
+// 返回值:
+//   - ids: 帖子ID列表
+//   - err: 可能的错误
+func GetPostIDsByAuthor(authorID uint64) (ids []uint64, err error) {
+	err = db.Model(&models.Post{}).
+		Where("author_id = ? AND status = ?", authorID, models.PostStatusPublished).
+		Pluck("post_id", &ids).Error
+	return
+}
+
 // GetPostListByIDs 根据帖子ID列表获取帖子信息
 // 参数:
 //   - ids: 帖子ID列表
@@ -150,11 +212,11 @@ func GetPostListByIDs(ids []string) (postList []*models.Post, err error) {
 	postList = make([]*models.Post, 0, 2)
 
 	err = db.Raw(`
-        SELECT post_id, title, content, author_id, community_id, create_time
+        SELECT post_id, title, content, content_html, author_id, community_id, create_time
         FROM post
-        WHERE post_id IN (?)
+        WHERE post_id IN (?) AND status = ?
         ORDER BY FIND_IN_SET(post_id, ?)
-    `, ids, idStr).Scan(&postList).Error
+    `, ids, models.PostStatusPublished, idStr).Scan(&postList).Error
 
 	if err != nil {
 		return nil, err
@@ -199,31 +261,89 @@ func BatchUpdatePostViewCounts(viewCounts map[uint64]int64) error {
 	return nil
 }
 
-// UpdatePost 更新帖子信息
+// UpdatePost 更新帖子信息，并写入修订记录
+// 第一次编辑时先把原始内容补记为第1版，内容没有变化时不做修改
 // 参数:
 //   - post: 帖子信息
+//   - editorID: 编辑人ID
+//   - reason: 编辑原因
 //
 // 返回值:
-//   - err: 可能的错误
-func UpdatePost(post *models.Post) error {
-	// 更新帖子信息，只更新允许修改的字段
-	err := db.Model(&models.Post{}).
-		Where("post_id = ?", post.PostID).
-		Updates(map[string]interface{}{
-			"title":        post.Title,
-			"content":      post.Content,
-			"community_id": post.CommunityID,
-			"update_time":  time.Now(),
+//   - old: 修改前的帖子，包含post_id、author_id、community_id、title、content和create_time
+//   - err: 帖子不存在或已删除时返回ErrorInvalidID
+func UpdatePost(post *models.Post, editorID uint64, reason string) (old *models.Post, err error) {
+	old = &models.Post{}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// 锁住帖子行，保证并发编辑时版本号连续
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("post_id, author_id, community_id, title, content, create_time").
+			Where("post_id = ? AND status = ?", post.PostID, models.PostStatusPublished).
+			First(old).Error
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return ErrorInvalidID
+			}
+			return err
+		}
+		if old.Title == post.Title && old.Content == post.Content && old.CommunityID == post.CommunityID {
+			return nil
+		}
+
+		var version int
+		if err = tx.Model(&models.PostRevision{}).
+			Select("COALESCE(MAX(version), 0)").
+			Where("post_id = ?", post.PostID).
+			Scan(&version).Error; err != nil {
+			return err
+		}
+		if version == 0 {
+			version = 1
+			if err = tx.Create(&models.PostRevision{
+				PostID:      old.PostID,
+				Version:     version,
+				Title:       old.Title,
+				Content:     old.Content,
+				CommunityID: old.CommunityID,
+				EditorID:    old.AuthorID,
+				CreateTime:  old.CreateTime,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		if err = tx.Model(&models.Post{}).
+			Where("post_id = ?", post.PostID).
+			Updates(map[string]interface{}{
+				"title":        post.Title,
+				"content":      post.Content,
+				"content_html": post.ContentHTML,
+				"community_id": post.CommunityID,
+				"update_time":  now,
+				"edit_time":    now,
+			}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.PostRevision{
+			PostID:      post.PostID,
+			Version:     version + 1,
+			Title:       post.Title,
+			Content:     post.Content,
+			CommunityID: post.CommunityID,
+			EditorID:    editorID,
+			Reason:      reason,
+			CreateTime:  now,
 		}).Error
+	})
 
 	if err != nil {
 		zap.L().Error("UpdatePost failed",
 			zap.Int64("post_id", int64(post.PostID)),
 			zap.Error(err))
-		return err
+		return nil, err
 	}
 
-	return nil
+	return old, nil
 }
 
 // GetPostAuthorID 获取帖子作者ID
@@ -235,9 +355,131 @@ func UpdatePost(post *models.Post) error {
 //   - err: 可能的错误
 func GetPostAuthorID(postID uint64) (authorID uint64, err error) {
 	var post models.Post
-	err = db.Select("author_id").Where("post_id = ?", postID).First(&post).Error
+	err = db.Select("author_id").
+		Where("post_id = ? AND status = ?", postID, models.PostStatusPublished).
+		First(&post).Error
 	if err != nil {
 		return 0, err
 	}
 	return post.AuthorID, nil
 }
+
+// GetPostByIDAnyStatus 根据帖子ID获取帖子，包括已删除的帖子，用于删除与恢复
+// 参数:
+//   - pid: 帖子ID
+//
+// 返回值:
+//   - post: 帖子信息
+//   - err: 帖子不存在时返回ErrorInvalidID
+func GetPostByIDAnyStatus(pid uint64) (post *models.Post, err error) {
+	post = &models.Post{}
+	if err = db.Where("post_id = ?", pid).First(post).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrorInvalidID
+		}
+		return nil, err
+	}
+	return post, nil
+}
+
+// SetPostDeleted 将帖子标记为已删除
+// 参数:
+//   - pid: 帖子ID
+//   - operatorID: 删除人ID
+//   - now: 删除时间
+//
+// 返回值:
+//   - bool: 是否有帖子被删除，帖子已删除时为false
+//   - err: 可能的错误
+func SetPostDeleted(pid, operatorID uint64, now time.Time) (bool, error) {
+	result := db.Model(&models.Post{}).
+		Where("post_id = ? AND status = ?", pid, models.PostStatusPublished).
+		Updates(map[string]interface{}{
+			"status":      models.PostStatusDeleted,
+			"delete_time": now,
+			"delete_by":   operatorID,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// RestorePost 恢复已删除的帖子
+// 参数:
+//   - pid: 帖子ID
+//
+// 返回值:
+//   - bool: 是否有帖子被恢复，帖子未删除时为false
+//   - err: 可能的错误
+func RestorePost(pid uint64) (bool, error) {
+	result := db.Model(&models.Post{}).
+		Where("post_id = ? AND status = ?", pid, models.PostStatusDeleted).
+		Updates(map[string]interface{}{
+			"status":      models.PostStatusPublished,
+			"delete_time": nil,
+			"delete_by":   0,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetPublishedPostsAfter 按帖子ID顺序分批获取已发布的帖子，用于重建搜索索引和重算排序分数
+// 参数:
+//   - afterID: 上一批最后一个帖子ID，第一批传0
+//   - limit: 每批数量
+//
+// 返回值:
+//   - posts: 包含post_id、title、content、content_html、community_id与create_time的帖子列表
+//   - err: 可能的错误
+func GetPublishedPostsAfter(afterID uint64, limit int) (posts []*models.Post, err error) {
+	err = db.Model(&models.Post{}).
+		Select("post_id, title, content, content_html, community_id, create_time").
+		Where("status = ? AND post_id > ?", models.PostStatusPublished, afterID).
+		Order("post_id").
+		Limit(limit).
+		Find(&posts).Error
+	return
+}
+
+// GetPostsToPurge 获取删除时间早于指定时间的帖子
+// 参数:
+//   - before: 截止时间
+//   - limit: 最多返回的数量
+//
+// 返回值:
+//   - posts: 只包含post_id与author_id的帖子列表
+//   - err: 可能的错误
+func GetPostsToPurge(before time.Time, limit int) (posts []*models.Post, err error) {
+	err = db.Model(&models.Post{}).
+		Select("post_id, author_id").
+		Where("status = ? AND delete_time < ?", models.PostStatusDeleted, before).
+		Limit(limit).
+		Find(&posts).Error
+	return
+}
+
+// PurgePost 彻底删除已删除的帖子及其修订记录、评论
+// 参数:
+//   - pid: 帖子ID
+//
+// 返回值:
+//   - err: 可能的错误
+func PurgePost(pid uint64) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("post_id = ? AND status = ?", pid, models.PostStatusDeleted).Delete(&models.Post{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			// 期间被恢复的帖子不再清除
+			return result.Error
+		}
+		if err := tx.Where("post_id = ?", pid).Delete(&models.PostRevision{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("post_id = ?", pid).Delete(&models.PostTag{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("post_id = ?", pid).Delete(&models.Comment{}).Error
+	})
+}
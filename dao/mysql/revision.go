@@ -0,0 +1,58 @@
+package mysql
+
+import (
+	"land/models"
+
+	"gorm.io/gorm"
+)
+
+// GetPostRevisions 分页获取帖子的修订记录，按版本号倒序
+// 参数:
+//   - postID: 帖子ID
+//   - page: 页码
+//   - size: 每页数量
+//
+// 返回值:
+//   - revisions: 修订记录
+//   - err: 可能的错误
+func GetPostRevisions(postID uint64, page, size int64) (revisions []*models.PostRevision, err error) {
+	revisions = make([]*models.PostRevision, 0, size)
+	err = db.Where("post_id = ?", postID).
+		Order("version DESC").
+		Offset(int((page - 1) * size)).
+		Limit(int(size)).
+		Find(&revisions).Error
+	return
+}
+
+// CountPostRevisions 获取帖子的修订记录数
+// 参数:
+//   - postID: 帖子ID
+//
+// 返回值:
+//   - count: 修订记录数，未编辑过的帖子为0
+//   - err: 可能的错误
+func CountPostRevisions(postID uint64) (count int64, err error) {
+	err = db.Model(&models.PostRevision{}).Where("post_id = ?", postID).Count(&count).Error
+	return
+}
+
+// GetPostRevision 获取帖子的指定版本
+// 参数:
+//   - postID: 帖子ID
+//   - version: 版本号
+//
+// 返回值:
+//   - *models.PostRevision: 修订记录
+//   - error: 版本不存在时返回ErrorInvalidID
+func GetPostRevision(postID uint64, version int) (*models.PostRevision, error) {
+	revision := new(models.PostRevision)
+	err := db.Where("post_id = ? AND version = ?", postID, version).First(revision).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, ErrorInvalidID
+	}
+	if err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
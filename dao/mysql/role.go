@@ -0,0 +1,66 @@
+package mysql
+
+import (
+	"land/models"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// GetUserRoles 获取用户在库中的角色列表
+// 参数:
+//   - userID: 用户ID
+//
+// 返回值:
+//   - roles: 角色列表（不含默认的user角色）
+//   - err: 可能的错误
+func GetUserRoles(userID uint64) (roles []string, err error) {
+	roles = make([]string, 0)
+	err = db.Model(&models.UserRole{}).
+		Where("user_id = ?", userID).
+		Pluck("role", &roles).Error
+	if err != nil {
+		zap.L().Error("GetUserRoles failed", zap.Int64("user_id", int64(userID)), zap.Error(err))
+		return nil, err
+	}
+	return roles, nil
+}
+
+// AddUserRole 为用户添加角色，已存在时不重复插入
+// 参数:
+//   - userID: 用户ID
+//   - role: 角色
+//
+// 返回值:
+//   - err: 可能的错误
+func AddUserRole(userID uint64, role string) error {
+	r := &models.UserRole{UserID: userID, Role: role, CreateTime: time.Now()}
+	err := db.Where("user_id = ? AND role = ?", userID, role).FirstOrCreate(r).Error
+	if err != nil {
+		zap.L().Error("AddUserRole failed",
+			zap.Int64("user_id", int64(userID)),
+			zap.String("role", role),
+			zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// DeleteUserRole 移除用户的角色
+// 参数:
+//   - userID: 用户ID
+//   - role: 角色
+//
+// 返回值:
+//   - err: 可能的错误
+func DeleteUserRole(userID uint64, role string) error {
+	err := db.Where("user_id = ? AND role = ?", userID, role).Delete(&models.UserRole{}).Error
+	if err != nil {
+		zap.L().Error("DeleteUserRole failed",
+			zap.Int64("user_id", int64(userID)),
+			zap.String("role", role),
+			zap.Error(err))
+		return err
+	}
+	return nil
+}
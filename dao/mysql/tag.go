@@ -0,0 +1,191 @@
+package mysql
+
+import (
+	"land/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ensureTags 获取标签ID，不存在的标签先创建
+// 参数:
+//   - tx: 事务
+//   - names: 规范化后的标签名
+//
+// 返回值:
+//   - map[string]uint64: 标签名到ID的映射
+//   - err: 可能的错误
+func ensureTags(tx *gorm.DB, names []string) (map[string]uint64, error) {
+	ids := make(map[string]uint64, len(names))
+	if len(names) == 0 {
+		return ids, nil
+	}
+	now := time.Now()
+	tags := make([]*models.Tag, 0, len(names))
+	for _, name := range names {
+		tags = append(tags, &models.Tag{Name: name, CreateTime: now})
+	}
+	// 并发创建同名标签时由唯一索引去重
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error; err != nil {
+		return nil, err
+	}
+
+	existing := make([]*models.Tag, 0, len(names))
+	if err := tx.Select("id, name").Where("name IN ?", names).Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	for _, t := range existing {
+		ids[t.Name] = t.ID
+	}
+	return ids, nil
+}
+
+// insertPostTags 按顺序写入帖子与标签的关联
+func insertPostTags(tx *gorm.DB, postID uint64, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	ids, err := ensureTags(tx, names)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	links := make([]*models.PostTag, 0, len(names))
+	for _, name := range names {
+		links = append(links, &models.PostTag{PostID: postID, TagID: ids[name], CreateTime: now})
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&links).Error
+}
+
+// getPostTags 获取帖子的标签，按作者填写的顺序
+func getPostTags(tx *gorm.DB, postID uint64) (names []string, err error) {
+	err = tx.Table("post_tag").
+		Select("tag.name").
+		Joins("JOIN tag ON tag.id = post_tag.tag_id").
+		Where("post_tag.post_id = ?", postID).
+		Order("post_tag.id").
+		Pluck("tag.name", &names).Error
+	return
+}
+
+// GetPostTags 获取帖子的标签
+// 参数:
+//   - postID: 帖子ID
+//
+// 返回值:
+//   - names: 标签名，按作者填写的顺序
+//   - err: 可能的错误
+func GetPostTags(postID uint64) ([]string, error) {
+	return getPostTags(db, postID)
+}
+
+// GetTagsByPostIDs 批量获取帖子的标签
+// 参数:
+//   - postIDs: 帖子ID列表
+//
+// 返回值:
+//   - map[uint64][]string: 帖子ID到标签名的映射，没有标签的帖子不在其中
+//   - err: 可能的错误
+func GetTagsByPostIDs(postIDs []uint64) (map[uint64][]string, error) {
+	tags := make(map[uint64][]string)
+	if len(postIDs) == 0 {
+		return tags, nil
+	}
+	var rows []struct {
+		PostID uint64
+		Name   string
+	}
+	err := db.Table("post_tag").
+		Select("post_tag.post_id, tag.name").
+		Joins("JOIN tag ON tag.id = post_tag.tag_id").
+		Where("post_tag.post_id IN ?", postIDs).
+		Order("post_tag.id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		tags[r.PostID] = append(tags[r.PostID], r.Name)
+	}
+	return tags, nil
+}
+
+// ReplacePostTags 替换帖子的标签
+// 参数:
+//   - postID: 帖子ID
+//   - names: 新的标签，已规范化并去重
+//
+// 返回值:
+//   - added: 新增的标签
+//   - removed: 移除的标签
+//   - err: 可能的错误
+func ReplacePostTags(postID uint64, names []string) (added, removed []string, err error) {
+	err = db.Transaction(func(tx *gorm.DB) error {
+		old, err := getPostTags(tx, postID)
+		if err != nil {
+			return err
+		}
+		keep := make(map[string]bool, len(names))
+		for _, name := range names {
+			keep[name] = true
+		}
+		had := make(map[string]bool, len(old))
+		for _, name := range old {
+			had[name] = true
+			if !keep[name] {
+				removed = append(removed, name)
+			}
+		}
+		for _, name := range names {
+			if !had[name] {
+				added = append(added, name)
+			}
+		}
+
+		if len(removed) > 0 {
+			if err = tx.Where("post_id = ? AND tag_id IN (?)", postID,
+				tx.Model(&models.Tag{}).Select("id").Where("name IN ?", removed)).
+				Delete(&models.PostTag{}).Error; err != nil {
+				return err
+			}
+		}
+		return insertPostTags(tx, postID, added)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return added, removed, nil
+}
+
+// TagExists 判断标签是否存在
+// 参数:
+//   - name: 规范化后的标签名
+//
+// 返回值:
+//   - bool: 是否存在
+//   - err: 可能的错误
+func TagExists(name string) (bool, error) {
+	var count int64
+	err := db.Model(&models.Tag{}).Where("name = ?", name).Count(&count).Error
+	return count > 0, err
+}
+
+// SearchTags 按前缀查找标签
+// 参数:
+//   - prefix: 规范化后的前缀
+//   - limit: 最多返回的数量
+//
+// 返回值:
+//   - names: 标签名，按名称排序
+//   - err: 可能的错误
+func SearchTags(prefix string, limit int) (names []string, err error) {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(prefix)
+	err = db.Model(&models.Tag{}).
+		Where("name LIKE ?", escaped+"%").
+		Order("name").
+		Limit(limit).
+		Pluck("name", &names).Error
+	return
+}
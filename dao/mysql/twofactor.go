@@ -0,0 +1,149 @@
+package mysql
+
+import (
+	"errors"
+	"land/models"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// GetTwoFactor 获取用户的两步验证配置
+// 参数:
+//   - userID: 用户ID
+//
+// 返回值:
+//   - tf: 两步验证配置
+//   - err: 未绑定时返回ErrorTwoFactorNotEnrolled
+func GetTwoFactor(userID uint64) (tf *models.UserTwoFactor, err error) {
+	tf = &models.UserTwoFactor{}
+	err = db.Where("user_id = ?", userID).First(tf).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrorTwoFactorNotEnrolled
+	}
+	if err != nil {
+		return nil, err
+	}
+	return tf, nil
+}
+
+// SaveTwoFactorSecret 保存待确认的密钥，重复绑定时覆盖未启用的旧密钥
+// 参数:
+//   - userID: 用户ID
+//   - secret: Base32密钥
+//
+// 返回值:
+//   - err: 可能的错误
+func SaveTwoFactorSecret(userID uint64, secret string) error {
+	now := time.Now()
+	tf := &models.UserTwoFactor{}
+	err := db.Where("user_id = ?", userID).First(tf).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return db.Create(&models.UserTwoFactor{
+			UserID:     userID,
+			Secret:     secret,
+			CreateTime: now,
+			UpdateTime: now,
+		}).Error
+	}
+	if err != nil {
+		return err
+	}
+	return db.Model(&models.UserTwoFactor{}).
+		Where("user_id = ? AND enabled = ?", userID, false).
+		Updates(map[string]interface{}{"secret": secret, "update_time": now}).Error
+}
+
+// EnableTwoFactor 启用两步验证并写入新的恢复码
+// 参数:
+//   - userID: 用户ID
+//   - codeHashes: 恢复码哈希
+//
+// 返回值:
+//   - err: 可能的错误
+func EnableTwoFactor(userID uint64, codeHashes []string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.UserTwoFactor{}).
+			Where("user_id = ?", userID).
+			Updates(map[string]interface{}{"enabled": true, "update_time": time.Now()}).Error
+		if err != nil {
+			return err
+		}
+		return replaceRecoveryCodes(tx, userID, codeHashes)
+	})
+}
+
+// ReplaceRecoveryCodes 作废旧恢复码并写入新的一组
+// 参数:
+//   - userID: 用户ID
+//   - codeHashes: 恢复码哈希
+//
+// 返回值:
+//   - err: 可能的错误
+func ReplaceRecoveryCodes(userID uint64, codeHashes []string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, codeHashes)
+	})
+}
+
+// DisableTwoFactor 关闭两步验证并删除恢复码
+// 参数:
+//   - userID: 用户ID
+//
+// 返回值:
+//   - err: 可能的错误
+func DisableTwoFactor(userID uint64) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.UserTwoFactor{}).Error
+	})
+}
+
+// UseRecoveryCode 消费一个未使用的恢复码
+// 参数:
+//   - userID: 用户ID
+//   - codeHash: 恢复码哈希
+//
+// 返回值:
+//   - bool: 是否消费成功
+//   - err: 可能的错误
+func UseRecoveryCode(userID uint64, codeHash string) (bool, error) {
+	result := db.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used = ?", userID, codeHash, false).
+		Updates(map[string]interface{}{"used": true, "used_time": time.Now()})
+	if result.Error != nil {
+		zap.L().Error("UseRecoveryCode failed", zap.Int64("user_id", int64(userID)), zap.Error(result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// CountUnusedRecoveryCodes 统计剩余可用的恢复码数量
+// 参数:
+//   - userID: 用户ID
+//
+// 返回值:
+//   - count: 剩余数量
+//   - err: 可能的错误
+func CountUnusedRecoveryCodes(userID uint64) (count int64, err error) {
+	err = db.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND used = ?", userID, false).
+		Count(&count).Error
+	return
+}
+
+// replaceRecoveryCodes 在事务中替换恢复码
+func replaceRecoveryCodes(tx *gorm.DB, userID uint64, codeHashes []string) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+	now := time.Now()
+	codes := make([]*models.RecoveryCode, 0, len(codeHashes))
+	for _, h := range codeHashes {
+		codes = append(codes, &models.RecoveryCode{UserID: userID, CodeHash: h, CreateTime: now})
+	}
+	return tx.Create(&codes).Error
+}
@@ -1,10 +1,14 @@
 package mysql
 
 import (
+	"errors"
 	"land/models"
+	"time"
+
+	"gorm.io/gorm"
 )
 
-// GetUserById 根据用户ID获取用户信息
+// GetUserById 根据用户ID获取用户信息，已注销的用户视为不存在
 // 参数:
 //   - uid: 用户ID
 //
@@ -13,7 +17,7 @@ import (
 //   - err: 可能的错误
 func GetUserById(id uint64) (user *models.User, err error) {
 	user = &models.User{}
-	err = db.Where("user_id = ?", id).Find(user).Error
+	err = db.Where("user_id = ? AND delete_time IS NULL", id).Find(user).Error
 	return user, err
 }
 
@@ -24,11 +28,14 @@ func GetUserById(id uint64) (user *models.User, err error) {
 // 返回值:
 //   - err: 可能的错误
 func InsertUser(user *models.User) error {
+	user.CreateTime = time.Now()
+	user.UpdateTime = user.CreateTime
 	err := db.Create(user).Error
 	return err
 }
 
 // CheckUserExist 检查指定用户名的用户是否存在
+// 已注销但未到期清除的用户仍占用用户名
 // 参数:
 //   - username: 用户名
 //
@@ -40,22 +47,109 @@ func CheckUserExist(username string) bool {
 	return count > 0
 }
 
-// Login 用户登录
+// GetUserByUsername 根据用户名获取用户信息（包含密码哈希）
+// 参数:
+//   - username: 用户名
+//
+// 返回值:
+//   - user: 用户信息
+//   - err: 可能的错误，如用户不存在
+func GetUserByUsername(username string) (user *models.User, err error) {
+	user = &models.User{}
+	err = db.Where("username = ? AND delete_time IS NULL", username).First(user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrorUserNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetUserByEmail 根据邮箱获取用户信息
+// 参数:
+//   - email: 邮箱
+//
+// 返回值:
+//   - user: 用户信息
+//   - err: 可能的错误，如用户不存在
+func GetUserByEmail(email string) (user *models.User, err error) {
+	user = &models.User{}
+	err = db.Where("email = ? AND delete_time IS NULL", email).First(user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrorUserNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetUserByVerifiedEmail 根据已验证的邮箱获取用户信息
+// 未验证的邮箱可以被任何人注册，不能用于找回账号；已验证的邮箱全局唯一
 // 参数:
-//   - user: 用户信息，包含用户名和密码
+//   - email: 邮箱
 //
 // 返回值:
-//   - err: 可能的错误，如用户不存在或密码错误
-func Login(user *models.User) error {
-	pwd := user.Password
-	usr := &models.User{}
-	err := db.Where("username = ?", user.Username).First(usr).Error
+//   - user: 用户信息
+//   - err: 没有用户验证过该邮箱时返回ErrorUserNotExist
+func GetUserByVerifiedEmail(email string) (user *models.User, err error) {
+	user = &models.User{}
+	err = db.Where("email = ? AND email_verified = ? AND delete_time IS NULL", email, true).First(user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrorUserNotExist
+	}
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return user, nil
+}
+
+// UpdateUserPassword 更新用户密码哈希
+// 参数:
+//   - userID: 用户ID
+//   - hash: 新的密码哈希
+//
+// 返回值:
+//   - err: 可能的错误
+func UpdateUserPassword(userID uint64, hash string) error {
+	return db.Model(&models.User{}).
+		Where("user_id = ?", userID).
+		Update("password", hash).Error
+}
 
-	if usr.Password != pwd {
-		return ErrorInvalidPassword
+// SetEmailVerified 将用户邮箱标记为已验证，邮箱需与验证时一致，且未被其他用户验证
+// 并发验证同一邮箱时由唯一索引uk_verified_email兜底
+// 参数:
+//   - userID: 用户ID
+//   - email: 验证链接中的邮箱
+//
+// 返回值:
+//   - bool: 是否有记录被更新（邮箱已变更或已被其他用户验证时为false）
+//   - err: 可能的错误
+func SetEmailVerified(userID uint64, email string) (bool, error) {
+	// MySQL不允许在UPDATE的子查询中直接读取同一张表，用派生表包一层
+	result := db.Model(&models.User{}).
+		Where("user_id = ? AND email = ?", userID, email).
+		Where("NOT EXISTS (SELECT 1 FROM (SELECT user_id FROM user WHERE email = ? AND email_verified = ? AND user_id <> ?) AS v)",
+			email, true, userID).
+		Update("email_verified", true)
+	if result.Error != nil {
+		return false, result.Error
 	}
-	return nil
+	return result.RowsAffected > 0, nil
+}
+
+// UpdateUserProfile 更新用户资料
+// 参数:
+//   - userID: 用户ID
+//   - fields: 需要更新的列
+//
+// 返回值:
+//   - err: 可能的错误
+func UpdateUserProfile(userID uint64, fields map[string]interface{}) error {
+	fields["update_time"] = time.Now()
+	return db.Model(&models.User{}).
+		Where("user_id = ?", userID).
+		Updates(fields).Error
 }
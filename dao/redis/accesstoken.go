@@ -0,0 +1,101 @@
+package redis
+
+import (
+	"context"
+	"land/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetAccessTokenCache 读取个人访问令牌缓存
+// 参数:
+//   - tokenHash: 令牌哈希
+//
+// 返回值:
+//   - *models.AccessTokenAuth: 令牌身份，缓存为无效标记时TokenID为0
+//   - *time.Time: 过期时间，为空表示永不过期
+//   - bool: 是否命中缓存
+//   - error: 可能的错误
+func GetAccessTokenCache(tokenHash string) (*models.AccessTokenAuth, *time.Time, bool, error) {
+	m, err := client.HGetAll(context.Background(), getRedisKey(KeyAccessTokenPF+tokenHash)).Result()
+	if err != nil {
+		return nil, nil, false, err
+	}
+	if len(m) == 0 {
+		return nil, nil, false, nil
+	}
+
+	auth := &models.AccessTokenAuth{}
+	auth.TokenID, _ = strconv.ParseUint(m["id"], 10, 64)
+	if auth.TokenID == 0 {
+		return auth, nil, true, nil
+	}
+	auth.UserID, _ = strconv.ParseUint(m["user_id"], 10, 64)
+	auth.Scopes = strings.Fields(m["scopes"])
+	auth.Roles = strings.Fields(m["roles"])
+
+	var expire *time.Time
+	if ts, _ := strconv.ParseInt(m["expire"], 10, 64); ts > 0 {
+		t := time.Unix(ts, 0)
+		expire = &t
+	}
+	return auth, expire, true, nil
+}
+
+// SetAccessTokenCache 缓存个人访问令牌
+// 参数:
+//   - tokenHash: 令牌哈希
+//   - auth: 令牌身份，为nil时写入无效标记
+//   - expire: 过期时间，为空表示永不过期
+//
+// 返回值:
+//   - error: 可能的错误
+func SetAccessTokenCache(tokenHash string, auth *models.AccessTokenAuth, expire *time.Time) error {
+	ctx := context.Background()
+	key := getRedisKey(KeyAccessTokenPF + tokenHash)
+
+	pipeline := client.TxPipeline()
+	pipeline.Del(ctx, key)
+	if auth == nil {
+		pipeline.HSet(ctx, key, "id", 0)
+		pipeline.Expire(ctx, key, AccessTokenInvalidTTL)
+	} else {
+		var ts int64
+		if expire != nil {
+			ts = expire.Unix()
+		}
+		pipeline.HSet(ctx, key,
+			"id", auth.TokenID,
+			"user_id", auth.UserID,
+			"scopes", strings.Join(auth.Scopes, " "),
+			"roles", strings.Join(auth.Roles, " "),
+			"expire", ts,
+		)
+		pipeline.Expire(ctx, key, AccessTokenCacheTTL)
+	}
+	_, err := pipeline.Exec(ctx)
+	return err
+}
+
+// DeleteAccessTokenCache 删除个人访问令牌缓存，吊销令牌后立即生效
+// 参数:
+//   - tokenHash: 令牌哈希
+//
+// 返回值:
+//   - error: 可能的错误
+func DeleteAccessTokenCache(tokenHash string) error {
+	return client.Del(context.Background(), getRedisKey(KeyAccessTokenPF+tokenHash)).Err()
+}
+
+// AcquireAccessTokenTouch 判断本次请求是否需要写回最近使用时间
+// 参数:
+//   - tokenID: 令牌ID
+//
+// 返回值:
+//   - bool: 间隔内首次使用返回true
+//   - error: 可能的错误
+func AcquireAccessTokenTouch(tokenID uint64) (bool, error) {
+	key := getRedisKey(KeyAccessTokenUsedPF + strconv.FormatUint(tokenID, 10))
+	return client.SetNX(context.Background(), key, 1, AccessTokenUsedTTL).Result()
+}
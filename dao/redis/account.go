@@ -0,0 +1,119 @@
+package redis
+
+import (
+	"context"
+	"land/models"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// scanKeys 用SCAN遍历匹配的键，避免KEYS阻塞
+// 参数:
+//   - pattern: 匹配模式
+//   - fn: 每批键的处理函数
+//
+// 返回值:
+//   - error: 可能的错误
+func scanKeys(ctx context.Context, pattern string, fn func(keys []string) error) error {
+	var cursor uint64
+	for {
+		keys, next, err := client.Scan(ctx, cursor, pattern, 200).Result()
+		if err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			if err = fn(keys); err != nil {
+				return err
+			}
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// GetUserVotes 获取用户在所有帖子上的投票记录
+// 投票记录按帖子存放在post:voted:<post_id>中，需要遍历
+// 参数:
+//   - userID: 用户ID
+//
+// 返回值:
+//   - []*models.UserVote: 投票记录
+//   - error: 可能的错误
+func GetUserVotes(userID string) ([]*models.UserVote, error) {
+	ctx := context.Background()
+	prefix := getRedisKey(KeyPostVotedPF)
+	votes := make([]*models.UserVote, 0)
+
+	err := scanKeys(ctx, prefix+"*", func(keys []string) error {
+		pipeline := client.Pipeline()
+		cmds := make([]*redis.FloatCmd, len(keys))
+		for i, key := range keys {
+			cmds[i] = pipeline.ZScore(ctx, key, userID)
+		}
+		if _, err := pipeline.Exec(ctx); err != nil && err != redis.Nil {
+			return err
+		}
+		for i, cmd := range cmds {
+			value, err := cmd.Result()
+			if err != nil || value == 0 {
+				continue
+			}
+			votes = append(votes, &models.UserVote{
+				PostID:    strings.TrimPrefix(keys[i], prefix),
+				Direction: int8(value),
+			})
+		}
+		return nil
+	})
+	return votes, err
+}
+
+// DeleteUserVotes 删除用户的投票记录，并撤回这些票对帖子分数的影响
+// 参数:
+//   - userID: 用户ID
+//   - votes: 用户的投票，由GetUserVotes获取
+//   - postTags: 帖子ID到标签的映射，用于同步调整各标签中的分数
+//
+// 返回值:
+//   - error: 可能的错误
+func DeleteUserVotes(userID string, votes []*models.UserVote, postTags map[string][]string) error {
+	if len(votes) == 0 {
+		return nil
+	}
+
+	ctx := context.Background()
+	pipeline := client.TxPipeline()
+	for _, v := range votes {
+		pipeline.ZRem(ctx, getRedisKey(KeyPostVotedPF+v.PostID), userID)
+		// 只调整仍在排序中的帖子，已删除的帖子恢复时会按投票记录重新计算分数
+		delta := -float64(v.Direction) * scorePerVote
+		pipeline.ZIncrXX(ctx, getRedisKey(KeyPostScoreZSet), &redis.Z{
+			Score:  delta,
+			Member: v.PostID,
+		})
+		incrTagScores(ctx, pipeline, v.PostID, postTags[v.PostID], delta)
+	}
+	_, err := pipeline.Exec(ctx)
+	return err
+}
+
+// DeleteUserViewRecords 从所有帖子的访问记录中移除该用户，访问量本身不回退
+// 参数:
+//   - userID: 用户ID
+//
+// 返回值:
+//   - error: 可能的错误
+func DeleteUserViewRecords(userID string) error {
+	ctx := context.Background()
+	return scanKeys(ctx, getRedisKey(KeyPostViewSetPF)+"*", func(keys []string) error {
+		pipeline := client.Pipeline()
+		for _, key := range keys {
+			pipeline.SRem(ctx, key, userID)
+		}
+		_, err := pipeline.Exec(ctx)
+		return err
+	})
+}
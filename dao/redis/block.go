@@ -0,0 +1,77 @@
+package redis
+
+import (
+	"context"
+	"land/models"
+	"strconv"
+)
+
+// blockSetPlaceholder 集合中的占位成员，用户ID不会为0
+const blockSetPlaceholder = "0"
+
+func getBlockSetKey(userID uint64, kind uint8) string {
+	prefix := KeyBlockSetPF
+	if kind == models.RelationMute {
+		prefix = KeyMuteSetPF
+	}
+	return getRedisKey(prefix + strconv.FormatUint(userID, 10))
+}
+
+// GetBlockSet 获取用户拉黑/屏蔽的人
+// 参数:
+//   - userID: 操作者ID
+//   - kind: 关系类型
+//
+// 返回值:
+//   - map[uint64]bool: 对象ID集合
+//   - bool: 缓存是否存在
+//   - error: 可能的错误
+func GetBlockSet(userID uint64, kind uint8) (map[uint64]bool, bool, error) {
+	members, err := client.SMembers(context.Background(), getBlockSetKey(userID, kind)).Result()
+	if err != nil || len(members) == 0 {
+		return nil, false, err
+	}
+	set := make(map[uint64]bool, len(members))
+	for _, m := range members {
+		if id, err := strconv.ParseUint(m, 10, 64); err == nil && id != 0 {
+			set[id] = true
+		}
+	}
+	return set, true, nil
+}
+
+// SetBlockSet 缓存用户拉黑/屏蔽的人
+// 参数:
+//   - userID: 操作者ID
+//   - kind: 关系类型
+//   - ids: 对象ID列表，可以为空
+//
+// 返回值:
+//   - error: 可能的错误
+func SetBlockSet(userID uint64, kind uint8, ids []uint64) error {
+	ctx := context.Background()
+	key := getBlockSetKey(userID, kind)
+	members := make([]interface{}, 0, len(ids)+1)
+	members = append(members, blockSetPlaceholder)
+	for _, id := range ids {
+		members = append(members, id)
+	}
+
+	pipeline := client.TxPipeline()
+	pipeline.Del(ctx, key)
+	pipeline.SAdd(ctx, key, members...)
+	pipeline.Expire(ctx, key, BlockSetTTL)
+	_, err := pipeline.Exec(ctx)
+	return err
+}
+
+// DeleteBlockSet 删除拉黑/屏蔽缓存，下次读取时从数据库重建
+// 参数:
+//   - userID: 操作者ID
+//   - kind: 关系类型
+//
+// 返回值:
+//   - error: 可能的错误
+func DeleteBlockSet(userID uint64, kind uint8) error {
+	return client.Del(context.Background(), getBlockSetKey(userID, kind)).Err()
+}
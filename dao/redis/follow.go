@@ -0,0 +1,387 @@
+package redis
+
+import (
+	"context"
+	"land/models"
+	"strconv"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// zaddIfExistsScript 仅当时间线已存在时写入帖子并裁剪长度
+// 不存在的时间线说明尚未构建或已闲置过期，读取时会整体重建，这里不能只写入一条造成不完整
+var zaddIfExistsScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return 0
+end
+redis.call("ZADD", KEYS[1], ARGV[1], ARGV[2])
+redis.call("ZREMRANGEBYRANK", KEYS[1], 0, -tonumber(ARGV[3]) - 1)
+return 1
+`)
+
+// saddIfExistsScript 仅当作者帖子集合已加载时加入帖子
+// 未加载的集合读取时从数据库整体构建，这里不能只写入一条造成计数错误
+var saddIfExistsScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return 0
+end
+return redis.call("SADD", KEYS[1], ARGV[1])
+`)
+
+// userPostSetPlaceholder 作者帖子集合中的占位成员，帖子ID不会为0
+const userPostSetPlaceholder = "0"
+
+func getUserPostsKey(authorID uint64) string {
+	return getRedisKey(KeyUserPostsPF + strconv.FormatUint(authorID, 10))
+}
+
+func getUserPostSetKey(authorID uint64) string {
+	return getRedisKey(KeyUserPostSetPF + strconv.FormatUint(authorID, 10))
+}
+
+func getTimelineKey(userID uint64) string {
+	return getRedisKey(KeyTimelinePF + strconv.FormatUint(userID, 10))
+}
+
+func getFollowingSetKey(userID uint64) string {
+	return getRedisKey(KeyFollowingSetPF + strconv.FormatUint(userID, 10))
+}
+
+func getFollowCountKey(userID uint64) string {
+	return getRedisKey(KeyFollowCountPF + strconv.FormatUint(userID, 10))
+}
+
+// UserPostsExist 检查作者的帖子时间线是否已构建
+// 参数:
+//   - authorID: 作者ID
+//
+// 返回值:
+//   - bool: 是否存在
+//   - error: 可能的错误
+func UserPostsExist(authorID uint64) (bool, error) {
+	n, err := client.Exists(context.Background(), getUserPostsKey(authorID)).Result()
+	return n > 0, err
+}
+
+// SetUserPosts 用数据库中的最近帖子构建作者的帖子时间线
+// 参数:
+//   - authorID: 作者ID
+//   - posts: 最近的帖子
+//
+// 返回值:
+//   - error: 可能的错误
+func SetUserPosts(authorID uint64, posts []*models.Post) error {
+	if len(posts) == 0 {
+		return nil
+	}
+	ctx := context.Background()
+	key := getUserPostsKey(authorID)
+	members := make([]*redis.Z, 0, len(posts))
+	for _, p := range posts {
+		members = append(members, &redis.Z{Score: float64(p.CreateTime.Unix()), Member: p.PostID})
+	}
+
+	pipeline := client.TxPipeline()
+	pipeline.ZAdd(ctx, key, members...)
+	pipeline.ZRemRangeByRank(ctx, key, 0, -TimelineMaxLen-1)
+	_, err := pipeline.Exec(ctx)
+	return err
+}
+
+// GetUserPostCount 获取作者已发布的帖子数
+// 参数:
+//   - authorID: 作者ID
+//
+// 返回值:
+//   - int64: 帖子数
+//   - bool: 缓存是否存在
+//   - error: 可能的错误
+func GetUserPostCount(authorID uint64) (int64, bool, error) {
+	n, err := client.SCard(context.Background(), getUserPostSetKey(authorID)).Result()
+	if err != nil || n == 0 {
+		return 0, false, err
+	}
+	return n - 1, true, nil
+}
+
+// SetUserPostSet 用数据库中已发布的帖子构建作者的帖子集合
+// 使用集合而不是计数器，发布、删除、恢复重试时重复写入不会使总数偏移
+// 参数:
+//   - authorID: 作者ID
+//   - postIDs: 已发布的帖子ID，可以为空
+//
+// 返回值:
+//   - error: 可能的错误
+func SetUserPostSet(authorID uint64, postIDs []uint64) error {
+	ctx := context.Background()
+	key := getUserPostSetKey(authorID)
+	members := make([]interface{}, 0, len(postIDs)+1)
+	members = append(members, userPostSetPlaceholder)
+	for _, id := range postIDs {
+		members = append(members, id)
+	}
+
+	pipeline := client.TxPipeline()
+	pipeline.Del(ctx, key)
+	pipeline.SAdd(ctx, key, members...)
+	pipeline.Expire(ctx, key, UserPostSetTTL)
+	_, err := pipeline.Exec(ctx)
+	return err
+}
+
+// PushToTimelines 将新帖子推送到粉丝的时间线（推模式），未构建的时间线跳过
+// 参数:
+//   - followerIDs: 粉丝ID列表
+//   - postID: 帖子ID
+//   - createTime: 发布时间（Unix秒）
+//
+// 返回值:
+//   - error: 可能的错误
+func PushToTimelines(followerIDs []uint64, postID uint64, createTime int64) error {
+	if len(followerIDs) == 0 {
+		return nil
+	}
+	ctx := context.Background()
+	pipeline := client.Pipeline()
+	for _, id := range followerIDs {
+		zaddIfExistsScript.Eval(ctx, pipeline, []string{getTimelineKey(id)}, createTime, postID, TimelineMaxLen)
+	}
+	_, err := pipeline.Exec(ctx)
+	return err
+}
+
+// TimelineExists 检查用户的时间线是否已构建
+// 参数:
+//   - userID: 用户ID
+//
+// 返回值:
+//   - bool: 是否存在
+//   - error: 可能的错误
+func TimelineExists(userID uint64) (bool, error) {
+	n, err := client.Exists(context.Background(), getTimelineKey(userID)).Result()
+	return n > 0, err
+}
+
+// RebuildTimeline 由所关注作者的帖子时间线合并出用户的时间线
+// 参数:
+//   - userID: 用户ID
+//   - authorIDs: 需要推模式的被关注者（不含大V）
+//
+// 返回值:
+//   - error: 可能的错误
+func RebuildTimeline(userID uint64, authorIDs []uint64) error {
+	if len(authorIDs) == 0 {
+		return nil
+	}
+	ctx := context.Background()
+	key := getTimelineKey(userID)
+	keys := make([]string, 0, len(authorIDs))
+	for _, id := range authorIDs {
+		keys = append(keys, getUserPostsKey(id))
+	}
+
+	pipeline := client.TxPipeline()
+	pipeline.ZUnionStore(ctx, key, &redis.ZStore{Keys: keys, Aggregate: "MAX"})
+	pipeline.ZRemRangeByRank(ctx, key, 0, -TimelineMaxLen-1)
+	pipeline.Expire(ctx, key, TimelineTTL)
+	_, err := pipeline.Exec(ctx)
+	return err
+}
+
+// GetTimelinePostIDs 分页获取时间线中的帖子ID，关注了大V时合并其帖子时间线（拉模式）
+// 参数:
+//   - userID: 用户ID
+//   - popularIDs: 关注的大V
+//   - p: 分页参数，支持页码和游标
+//
+// 返回值:
+//   - *models.PostIDPage: 帖子ID列表、下一页的游标位置和时间线中的帖子总数
+//   - error: 可能的错误
+func GetTimelinePostIDs(userID uint64, popularIDs []uint64, p *models.ParamPostList) (*models.PostIDPage, error) {
+	ctx := context.Background()
+	key := getTimelineKey(userID)
+	// 有人在读就续期，闲置的时间线自然过期，不再占用推送开销
+	client.Expire(ctx, key, TimelineTTL)
+
+	if len(popularIDs) == 0 {
+		return getIDsPage(key, p)
+	}
+
+	mergedKey := getRedisKey(KeyTimelineMergedPF + strconv.FormatUint(userID, 10))
+	if client.Exists(ctx, mergedKey).Val() < 1 {
+		keys := []string{key}
+		for _, id := range popularIDs {
+			keys = append(keys, getUserPostsKey(id))
+		}
+		pipeline := client.TxPipeline()
+		pipeline.ZUnionStore(ctx, mergedKey, &redis.ZStore{Keys: keys, Aggregate: "MAX"})
+		pipeline.Expire(ctx, mergedKey, TimelineMergedTTL)
+		if _, err := pipeline.Exec(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return getIDsPage(mergedKey, p)
+}
+
+// GetFollowingSet 获取缓存的关注列表
+// 参数:
+//   - userID: 用户ID
+//
+// 返回值:
+//   - []uint64: 被关注者ID
+//   - bool: 缓存是否存在
+//   - error: 可能的错误
+func GetFollowingSet(userID uint64) ([]uint64, bool, error) {
+	ctx := context.Background()
+	key := getFollowingSetKey(userID)
+	members, err := client.SMembers(ctx, key).Result()
+	if err != nil {
+		return nil, false, err
+	}
+	if len(members) == 0 {
+		return nil, false, nil
+	}
+	ids := make([]uint64, 0, len(members))
+	for _, m := range members {
+		if id, err := strconv.ParseUint(m, 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids, true, nil
+}
+
+// SetFollowingSet 缓存关注列表
+// 参数:
+//   - userID: 用户ID
+//   - ids: 被关注者ID
+//
+// 返回值:
+//   - error: 可能的错误
+func SetFollowingSet(userID uint64, ids []uint64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	ctx := context.Background()
+	key := getFollowingSetKey(userID)
+	members := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		members = append(members, id)
+	}
+	pipeline := client.TxPipeline()
+	pipeline.Del(ctx, key)
+	pipeline.SAdd(ctx, key, members...)
+	pipeline.Expire(ctx, key, TimelineTTL)
+	_, err := pipeline.Exec(ctx)
+	return err
+}
+
+// GetPopularFollowing 获取用户关注的大V
+// 参数:
+//   - userID: 用户ID
+//
+// 返回值:
+//   - []uint64: 大V的用户ID
+//   - error: 可能的错误
+func GetPopularFollowing(userID uint64) ([]uint64, error) {
+	members, err := client.SInter(context.Background(),
+		getFollowingSetKey(userID), getRedisKey(KeyFollowPopularSet)).Result()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint64, 0, len(members))
+	for _, m := range members {
+		if id, err := strconv.ParseUint(m, 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// IsPopularAuthor 检查作者是否为大V
+// 参数:
+//   - authorID: 作者ID
+//
+// 返回值:
+//   - bool: 是否为大V
+//   - error: 可能的错误
+func IsPopularAuthor(authorID uint64) (bool, error) {
+	return client.SIsMember(context.Background(), getRedisKey(KeyFollowPopularSet), authorID).Result()
+}
+
+// SetPopularAuthor 根据粉丝数更新作者的大V标记
+// 参数:
+//   - authorID: 作者ID
+//   - popular: 是否为大V
+//
+// 返回值:
+//   - error: 可能的错误
+func SetPopularAuthor(authorID uint64, popular bool) error {
+	ctx := context.Background()
+	if popular {
+		return client.SAdd(ctx, getRedisKey(KeyFollowPopularSet), authorID).Err()
+	}
+	return client.SRem(ctx, getRedisKey(KeyFollowPopularSet), authorID).Err()
+}
+
+// InvalidateFollowing 关注关系变化后删除用户的关注列表与时间线，下次读取时重建
+// 参数:
+//   - userID: 关注者ID
+//
+// 返回值:
+//   - error: 可能的错误
+func InvalidateFollowing(userID uint64) error {
+	uid := strconv.FormatUint(userID, 10)
+	return client.Del(context.Background(),
+		getFollowingSetKey(userID),
+		getTimelineKey(userID),
+		getRedisKey(KeyTimelineMergedPF+uid),
+	).Err()
+}
+
+// GetFollowCounts 获取缓存的关注数与粉丝数
+// 参数:
+//   - userID: 用户ID
+//
+// 返回值:
+//   - *models.FollowCounts: 计数，缓存不存在时为nil
+//   - error: 可能的错误
+func GetFollowCounts(userID uint64) (*models.FollowCounts, error) {
+	fields, err := client.HGetAll(context.Background(), getFollowCountKey(userID)).Result()
+	if err != nil || len(fields) == 0 {
+		return nil, err
+	}
+	followers, _ := strconv.ParseInt(fields["followers"], 10, 64)
+	following, _ := strconv.ParseInt(fields["following"], 10, 64)
+	return &models.FollowCounts{Followers: followers, Following: following}, nil
+}
+
+// SetFollowCounts 缓存关注数与粉丝数
+// 参数:
+//   - userID: 用户ID
+//   - counts: 计数
+//
+// 返回值:
+//   - error: 可能的错误
+func SetFollowCounts(userID uint64, counts *models.FollowCounts) error {
+	ctx := context.Background()
+	key := getFollowCountKey(userID)
+	pipeline := client.TxPipeline()
+	pipeline.HSet(ctx, key, "followers", counts.Followers, "following", counts.Following)
+	pipeline.Expire(ctx, key, FollowCountTTL)
+	_, err := pipeline.Exec(ctx)
+	return err
+}
+
+// DeleteFollowCounts 删除计数缓存
+// 参数:
+//   - userIDs: 用户ID
+//
+// 返回值:
+//   - error: 可能的错误
+func DeleteFollowCounts(userIDs ...uint64) error {
+	keys := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		keys = append(keys, getFollowCountKey(id))
+	}
+	return client.Del(context.Background(), keys...).Err()
+}
@@ -57,8 +57,156 @@ const (
 	// 用途：存储帖子ID及其访问量
 	KeyPostViewZSet = "post:view"
 
-	// JWT Token存储前缀
-	KeyJWTTokenPF = "jwt:token:"
+	// KeyPostRankPF 按排序策略计算的帖子分数有序集合
+	// 类型：zset
+	// 用途：键名后加策略名（hot/best/controversial/top），member为已发布的帖子ID，score为该策略的分数，
+	// 发布、投票、撤票、恢复时按所在社区的排序参数重新计算
+	KeyPostRankPF = "post:rank:"
+
+	// KeyTagTimePF 标签下的帖子时间有序集合
+	// 类型：zset
+	// 用途：member为帖子ID，score为发布时间，与post:time同步维护，只包含已发布的帖子
+	KeyTagTimePF = "tag:time:"
+
+	// KeyTagScorePF 标签下的帖子分数有序集合
+	// 类型：zset
+	// 用途：member为帖子ID，score为投票分数，与post:score同步维护
+	KeyTagScorePF = "tag:score:"
+
+	// KeyTagViewPF 标签下按访问量排序的缓存
+	// 类型：zset
+	// 用途：tag:time与post:view的交集，短期缓存；同时按社区过滤时键名后加":<社区ID>"
+	KeyTagViewPF = "tag:view:"
+
+	// KeyTagRankPF 标签下按排序策略排序的缓存
+	// 类型：zset
+	// 用途：键名为"tag:rank:<策略名>:<标签>"，tag:time与post:rank:<策略名>的交集，短期缓存；同时按社区过滤时键名后加":<社区ID>"
+	KeyTagRankPF = "tag:rank:"
+
+	// KeySearchTermPF 搜索倒排索引
+	// 类型：zset
+	// 用途：以索引词为键，member为包含该词的已发布帖子ID，score为该词在帖子中的权重
+	KeySearchTermPF = "search:term:"
+
+	// KeySearchDocPF 帖子的索引词
+	// 类型：set
+	// 用途：记录帖子写入了哪些索引词，更新和删除时据此从倒排索引中移除
+	KeySearchDocPF = "search:doc:"
+
+	// KeySearchResultPF 搜索结果缓存
+	// 类型：zset
+	// 用途：以查询词、排序和过滤条件的哈希为键，缓存排好序的结果供翻页使用，短期有效
+	KeySearchResultPF = "search:result:"
+
+	// KeyRefreshTokenPF refresh token记录
+	// 类型：hash
+	// 用途：以token哈希为键，保存user_id、session及是否已使用
+	KeyRefreshTokenPF = "refresh:token:"
+
+	// KeySessionPF 登录会话
+	// 类型：hash
+	// 用途：保存会话所属用户、设备名、IP、User-Agent、创建与最近活跃时间，键不存在即表示会话已吊销
+	KeySessionPF = "session:"
+
+	// KeyUserSessionsPF 用户的会话集合
+	// 类型：set
+	// 用途：记录用户名下所有会话ID，用于列出和整体吊销
+	KeyUserSessionsPF = "session:user:"
+
+	// KeyPasswordResetPF 密码重置令牌
+	// 类型：string
+	// 用途：以令牌哈希为键保存用户ID，一次性使用
+	KeyPasswordResetPF = "pwdreset:token:"
+
+	// KeyPasswordResetUserPF 用户当前有效的密码重置令牌
+	// 类型：string
+	// 用途：保存最新令牌哈希，新令牌签发时旧令牌作废
+	KeyPasswordResetUserPF = "pwdreset:user:"
+
+	// KeyEmailVerifyCooldownPF 邮箱验证邮件发送冷却
+	// 类型：string
+	// 用途：限制同一用户重发验证邮件的频率
+	KeyEmailVerifyCooldownPF = "email:verify:cooldown:"
+
+	// KeyLoginFailPF 登录失败计数
+	// 类型：string
+	// 用途：按"user:<用户名>"或"ip:<IP>"统计窗口内的失败次数
+	KeyLoginFailPF = "login:fail:"
+
+	// KeyLoginLockPF 登录锁定标记
+	// 类型：string
+	// 用途：键存在即表示对应用户名或IP被临时锁定，TTL为剩余锁定时间
+	KeyLoginLockPF = "login:lock:"
+
+	// KeyTwoFactorChallengePF 两步验证登录挑战
+	// 类型：hash
+	// 用途：保存密码校验通过后待完成两步验证的登录，字段为user_id、device、attempts
+	KeyTwoFactorChallengePF = "2fa:challenge:"
+
+	// KeyTwoFactorUsedStepPF 已使用的TOTP时间步
+	// 类型：string
+	// 用途：按"<用户ID>:<时间步>"标记，防止同一个验证码在有效期内被重放
+	KeyTwoFactorUsedStepPF = "2fa:used:"
+
+	// KeyOIDCStatePF 第三方登录状态
+	// 类型：hash
+	// 用途：以state哈希为键保存provider、verifier、nonce等，回调时一次性取回
+	KeyOIDCStatePF = "oidc:state:"
+
+	// KeyAccessTokenPF 个人访问令牌缓存
+	// 类型：hash
+	// 用途：以令牌哈希为键缓存id、user_id、scopes、roles、expire，避免每次请求查库；id为0表示令牌无效
+	KeyAccessTokenPF = "pat:"
+
+	// KeyAccessTokenUsedPF 个人访问令牌最近使用标记
+	// 类型：string
+	// 用途：限制写回last_used_time的频率，键存在期间不再更新数据库
+	KeyAccessTokenUsedPF = "pat:used:"
+
+	// KeyUserPostsPF 作者的帖子时间线
+	// 类型：zset
+	// 用途：member为帖子ID，score为发布时间，只保留最近TimelineMaxLen条
+	KeyUserPostsPF = "user:posts:"
+
+	// KeyUserPostSetPF 作者已发布的全部帖子
+	// 类型：set
+	// 用途：SCARD作为用户主页的帖子总数，不受时间线长度限制；加载时写入占位成员0，区分没有帖子与未加载
+	KeyUserPostSetPF = "user:post_set:"
+
+	// KeyTimelinePF 用户的关注时间线（推模式）
+	// 类型：zset
+	// 用途：member为关注作者的帖子ID，score为发布时间，过期后按需重建
+	KeyTimelinePF = "timeline:"
+
+	// KeyTimelineMergedPF 合并了大V帖子的时间线缓存（拉模式）
+	// 类型：zset
+	// 用途：timeline与所关注大V的user:posts的并集，短期缓存
+	KeyTimelineMergedPF = "timeline:merged:"
+
+	// KeyFollowingSetPF 用户关注的人
+	// 类型：set
+	// 用途：读时间线时与大V集合求交集，关注关系变化时删除重建
+	KeyFollowingSetPF = "follow:following:"
+
+	// KeyFollowPopularSet 大V集合
+	// 类型：set
+	// 用途：粉丝数达到阈值的作者，发帖不推送，由粉丝读取时拉取
+	KeyFollowPopularSet = "follow:popular"
+
+	// KeyFollowCountPF 关注数与粉丝数缓存
+	// 类型：hash
+	// 用途：字段followers/following
+	KeyFollowCountPF = "follow:count:"
+
+	// KeyBlockSetPF 用户拉黑的人
+	// 类型：set
+	// 用途：评论、回复、@时检查；加载时写入占位成员0，区分空集合与未加载
+	KeyBlockSetPF = "block:"
+
+	// KeyMuteSetPF 用户屏蔽的人
+	// 类型：set
+	// 用途：过滤帖子和评论列表，结构同KeyBlockSetPF
+	KeyMuteSetPF = "mute:"
 )
 
 // getRedisKey 获取完整的Redis键
@@ -89,6 +237,50 @@ const (
 	UserViewedBaseTTL       = 24 * time.Hour // 用户访问记录基础TTL
 	UserViewedJitterPercent = 10             // 用户访问记录随机抖动百分比
 
+	// 密码重置令牌TTL配置
+	PasswordResetTTL      = 30 * time.Minute // 重置令牌有效期
+	PasswordResetCooldown = 60 * time.Second // 同一用户两次申请的最小间隔
+
+	// 邮箱验证TTL配置
+	EmailVerifyTTL      = 24 * time.Hour   // 验证链接有效期
+	EmailVerifyCooldown = 60 * time.Second // 两次发送验证邮件的最小间隔
+
+	// 登录防爆破配置
+	LoginFailWindow   = 15 * time.Minute // 失败计数窗口
+	LoginUserMaxFails = 5                // 同一用户名允许的连续失败次数
+	LoginIPMaxFails   = 20               // 同一IP允许的连续失败次数
+	LoginLockBase     = 1 * time.Minute  // 首次锁定时长，此后每多失败一次翻倍
+	LoginLockMax      = 24 * time.Hour   // 最长锁定时长
+
+	// 两步验证配置
+	TwoFactorChallengeTTL = 5 * time.Minute // 登录挑战有效期
+	TwoFactorMaxAttempts  = 5               // 单个挑战允许的验证码尝试次数
+	TwoFactorMaxFails     = 5               // 同一用户跨挑战累计允许的验证码错误次数，密码登录成功不清零
+	TwoFactorUsedStepTTL  = 2 * time.Minute // 已用时间步标记保留时长，覆盖验证窗口
+
+	// 第三方登录配置
+	OIDCStateTTL = 10 * time.Minute // 发起登录到回调的最长时间
+
+	// 个人访问令牌配置
+	AccessTokenCacheTTL   = 5 * time.Minute // 令牌缓存有效期，角色变更最多延迟该时长生效
+	AccessTokenInvalidTTL = 1 * time.Minute // 无效令牌的缓存时间，防止穿透
+	AccessTokenUsedTTL    = 1 * time.Minute // last_used_time写回间隔
+
+	// 关注时间线配置
+	TimelineMaxLen      = 1000               // 每条时间线保留的帖子数
+	TimelineTTL         = 7 * 24 * time.Hour // 时间线闲置多久后过期，过期后不再接收推送
+	TimelineMergedTTL   = 60 * time.Second   // 合并大V帖子后的时间线缓存时间
+	FollowCountTTL      = 10 * time.Minute   // 关注数缓存时间
+	UserPostSetTTL      = 24 * time.Hour     // 作者帖子集合缓存时间，发布、删除、恢复时增量维护
+	FollowPopularLimit  = 10000              // 粉丝数达到该值的作者改为拉模式
+	TimelineFanoutBatch = 500                // 推送时每批处理的粉丝数
+
+	// 拉黑/屏蔽配置
+	BlockSetTTL = 24 * time.Hour // 拉黑/屏蔽集合缓存时间，变更时直接删除
+
+	// 搜索配置
+	SearchResultTTL = 60 * time.Second // 搜索结果缓存时间，期间的修改在过期后才反映到结果中
+
 	// 社区缓存TTL配置
 	CommunityCacheBaseTTL       = 60 * time.Second // 社区缓存基础TTL
 	CommunityCacheJitterPercent = 25               // 社区缓存随机抖动百分比
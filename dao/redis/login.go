@@ -0,0 +1,138 @@
+package redis
+
+import (
+	"context"
+	"land/models"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// getLoginFailKey 生成登录失败计数键
+func getLoginFailKey(lockType, target string) string {
+	return getRedisKey(KeyLoginFailPF + lockType + ":" + target)
+}
+
+// getLoginLockKey 生成登录锁定键
+func getLoginLockKey(lockType, target string) string {
+	return getRedisKey(KeyLoginLockPF + lockType + ":" + target)
+}
+
+// GetLoginLock 查询剩余锁定时间
+// 参数:
+//   - lockType: 锁定维度（models.LockTypeUser/models.LockTypeIP）
+//   - target: 用户名或IP
+//
+// 返回值:
+//   - time.Duration: 剩余锁定时间，未锁定时为0
+//   - error: 可能的错误
+func GetLoginLock(lockType, target string) (time.Duration, error) {
+	ttl, err := client.PTTL(context.Background(), getLoginLockKey(lockType, target)).Result()
+	if err != nil {
+		return 0, err
+	}
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+// RecordLoginFailure 记录一次登录失败，超过阈值后按指数退避锁定
+// 参数:
+//   - lockType: 锁定维度
+//   - target: 用户名或IP
+//   - maxFails: 触发锁定的失败次数
+//
+// 返回值:
+//   - time.Duration: 本次触发的锁定时长，未触发时为0
+//   - error: 可能的错误
+func RecordLoginFailure(lockType, target string, maxFails int64) (time.Duration, error) {
+	ctx := context.Background()
+	failKey := getLoginFailKey(lockType, target)
+
+	count, err := client.Incr(ctx, failKey).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	if count < maxFails {
+		client.Expire(ctx, failKey, LoginFailWindow)
+		return 0, nil
+	}
+
+	// 第maxFails次失败锁定LoginLockBase，之后每次翻倍
+	lock := LoginLockBase
+	for i := maxFails; i < count && lock < LoginLockMax; i++ {
+		lock *= 2
+	}
+	if lock > LoginLockMax {
+		lock = LoginLockMax
+	}
+
+	// 计数需比锁定存活更久，解锁后再失败才能继续升级
+	pipeline := client.TxPipeline()
+	pipeline.Set(ctx, getLoginLockKey(lockType, target), 1, lock)
+	pipeline.Expire(ctx, failKey, lock+LoginFailWindow)
+	_, err = pipeline.Exec(ctx)
+	return lock, err
+}
+
+// ResetLoginFailures 清除失败计数与锁定（登录成功或管理员解锁）
+// 参数:
+//   - lockType: 锁定维度
+//   - target: 用户名或IP
+//
+// 返回值:
+//   - error: 可能的错误
+func ResetLoginFailures(lockType, target string) error {
+	return client.Del(context.Background(),
+		getLoginFailKey(lockType, target),
+		getLoginLockKey(lockType, target)).Err()
+}
+
+// ListLoginLocks 列出当前所有登录锁定
+// 返回值:
+//   - []*models.LoginLock: 锁定列表
+//   - error: 可能的错误
+func ListLoginLocks() ([]*models.LoginLock, error) {
+	ctx := context.Background()
+	prefix := getRedisKey(KeyLoginLockPF)
+
+	keys := make([]string, 0)
+	iter := client.Scan(ctx, 0, prefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+
+	pipeline := client.Pipeline()
+	ttlCmds := make([]*redis.DurationCmd, 0, len(keys))
+	failCmds := make([]*redis.StringCmd, 0, len(keys))
+	for _, key := range keys {
+		parts := strings.SplitN(strings.TrimPrefix(key, prefix), ":", 2)
+		ttlCmds = append(ttlCmds, pipeline.TTL(ctx, key))
+		failCmds = append(failCmds, pipeline.Get(ctx, getLoginFailKey(parts[0], parts[len(parts)-1])))
+	}
+	if _, err := pipeline.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	locks := make([]*models.LoginLock, 0, len(keys))
+	for i, key := range keys {
+		parts := strings.SplitN(strings.TrimPrefix(key, prefix), ":", 2)
+		if len(parts) != 2 || ttlCmds[i].Val() <= 0 {
+			continue
+		}
+		failures, _ := failCmds[i].Int64()
+		locks = append(locks, &models.LoginLock{
+			Type:     parts[0],
+			Target:   parts[1],
+			Failures: failures,
+			ExpireIn: int64(ttlCmds[i].Val().Seconds()),
+		})
+	}
+	return locks, nil
+}
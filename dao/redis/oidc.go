@@ -0,0 +1,68 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"land/models"
+	"strconv"
+)
+
+var (
+	ErrOIDCStateNotFound = errors.New("登录状态不存在或已过期")
+)
+
+// SaveOIDCState 保存第三方登录状态
+// 参数:
+//   - stateHash: state哈希
+//   - state: 登录状态
+//
+// 返回值:
+//   - error: 可能的错误
+func SaveOIDCState(stateHash string, state *models.OIDCAuthState) error {
+	ctx := context.Background()
+	key := getRedisKey(KeyOIDCStatePF + stateHash)
+
+	pipeline := client.TxPipeline()
+	pipeline.HSet(ctx, key,
+		"provider", state.Provider,
+		"verifier", state.Verifier,
+		"nonce", state.Nonce,
+		"device", state.Device,
+		"link_user_id", state.LinkUserID,
+	)
+	pipeline.Expire(ctx, key, OIDCStateTTL)
+	_, err := pipeline.Exec(ctx)
+	return err
+}
+
+// ConsumeOIDCState 取回并删除第三方登录状态，state只能使用一次
+// 参数:
+//   - stateHash: state哈希
+//
+// 返回值:
+//   - *models.OIDCAuthState: 登录状态
+//   - error: 不存在或已使用时返回ErrOIDCStateNotFound
+func ConsumeOIDCState(stateHash string) (*models.OIDCAuthState, error) {
+	ctx := context.Background()
+	key := getRedisKey(KeyOIDCStatePF + stateHash)
+
+	pipeline := client.TxPipeline()
+	get := pipeline.HGetAll(ctx, key)
+	pipeline.Del(ctx, key)
+	if _, err := pipeline.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	m := get.Val()
+	if len(m) == 0 {
+		return nil, ErrOIDCStateNotFound
+	}
+	linkUserID, _ := strconv.ParseUint(m["link_user_id"], 10, 64)
+	return &models.OIDCAuthState{
+		Provider:   m["provider"],
+		Verifier:   m["verifier"],
+		Nonce:      m["nonce"],
+		Device:     m["device"],
+		LinkUserID: linkUserID,
+	}, nil
+}
@@ -45,24 +45,97 @@ func generateRandomTTL(baseTTL time.Duration, jitterPercent int) time.Duration {
 	return time.Duration(finalTTL)
 }
 
-func getIDsFormKey(key string, page, size int64) ([]string, error) {
-	start := (page - 1) * size
-	end := start + size - 1
-	// 3. ZREVRANGE 按分数从大到小的顺序查询指定数量的元素
-	return client.ZRevRange(context.Background(), key, start, end).Result()
+// getIDsPage 按分数从大到小分页查询有序集合中的帖子ID，同时用ZCARD取总数
+// 指定游标时从游标位置之后开始取，不受前面新增帖子或分数变化的影响，否则按页码取
+// 参数:
+//   - key: 有序集合的key
+//   - p: 列表参数，使用其中的Page、Size和After
+//
+// 返回值:
+//   - *models.PostIDPage: 帖子ID列表、下一页的游标位置和集合中的帖子总数
+//   - error: 可能的错误
+func getIDsPage(key string, p *models.ParamPostList) (*models.PostIDPage, error) {
+	total, err := client.ZCard(context.Background(), key).Result()
+	if err != nil {
+		return nil, err
+	}
+	var zs []redis.Z
+	if p.After == nil {
+		start := (p.Page - 1) * p.Size
+		zs, err = client.ZRevRangeWithScores(context.Background(), key, start, start+p.Size-1).Result()
+	} else {
+		zs, err = getZAfterCursor(key, p.After, p.Size)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := &models.PostIDPage{IDs: make([]string, 0, len(zs)), Total: total}
+	for _, z := range zs {
+		res.IDs = append(res.IDs, z.Member.(string))
+	}
+	if int64(len(zs)) == p.Size {
+		last := zs[len(zs)-1]
+		postID, _ := strconv.ParseUint(last.Member.(string), 10, 64)
+		res.Next = &models.PostCursor{Sort: last.Score, PostID: postID}
+	}
+	return res, nil
+}
+
+// getZAfterCursor 查询排在游标之后的size个成员
+// 游标帖子的分数未变时直接按它的排名往后取；否则分数相同的成员按成员名字典序倒序排列，
+// 在与游标分数相同的成员中二分查找第一个名字更小的成员的排名，从这里往后取。
+// 大量帖子分数相同时（如top排序的0分）也只需O(log n)次查询
+func getZAfterCursor(key string, after *models.PostCursor, size int64) ([]redis.Z, error) {
+	ctx := context.Background()
+	score := strconv.FormatFloat(after.Sort, 'f', -1, 64)
+	postID := strconv.FormatUint(after.PostID, 10)
+
+	current, err := client.ZScore(ctx, key, postID).Result()
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+	if err == nil && current == after.Sort {
+		rank, err := client.ZRevRank(ctx, key, postID).Result()
+		if err == nil {
+			return client.ZRevRangeWithScores(ctx, key, rank+1, rank+size).Result()
+		}
+		if err != redis.Nil {
+			return nil, err
+		}
+	}
+
+	// 分数与游标相同的成员的排名范围为[lo, hi)
+	lo, err := client.ZCount(ctx, key, "("+score, "+inf").Result()
+	if err != nil {
+		return nil, err
+	}
+	hi, err := client.ZCount(ctx, key, score, "+inf").Result()
+	if err != nil {
+		return nil, err
+	}
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		members, err := client.ZRevRange(ctx, key, mid, mid).Result()
+		if err != nil {
+			return nil, err
+		}
+		if len(members) == 0 || members[0] < postID {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	return client.ZRevRangeWithScores(ctx, key, lo, lo+size-1).Result()
 }
 
-func GetPostIDsInOrder(p *models.ParamPostList) ([]string, error) {
+// GetPostIDsInOrder 按排序方式分页获取全站已发布帖子的ID
+func GetPostIDsInOrder(p *models.ParamPostList) (*models.PostIDPage, error) {
 	// 从redis获取id
 	// 1. 根据用户请求中携带的order参数确定要查询的redis key
-	key := getRedisKey(KeyPostTimeZSet)
-	if p.Order == models.OrderScore {
-		key = getRedisKey(KeyPostScoreZSet)
-	} else if p.Order == models.OrderView {
-		key = getRedisKey(KeyPostViewZSet)
-	}
-	// 2. 确定查询的索引起始点
-	return getIDsFormKey(key, p.Page, p.Size)
+	key := getOrderKey(p.Order)
+	// 2. 按页码或游标确定查询的起始点
+	return getIDsPage(key, p)
 }
 
 // GetPostVoteData 根据ids查询每篇帖子的投赞成票的数据
@@ -90,13 +163,8 @@ func GetPostVoteData(ids []string) (data []int64, err error) {
 }
 
 // GetCommunityPostIDsInOrder 根据社区id查询社区帖子的id列表
-func GetCommunityPostIDsInOrder(p *models.ParamPostList) ([]string, error) {
-	orderKey := getRedisKey(KeyPostTimeZSet)
-	if p.Order == models.OrderScore {
-		orderKey = getRedisKey(KeyPostScoreZSet)
-	} else if p.Order == models.OrderView {
-		orderKey = getRedisKey(KeyPostViewZSet)
-	}
+func GetCommunityPostIDsInOrder(p *models.ParamPostList) (*models.PostIDPage, error) {
+	orderKey := getOrderKey(p.Order)
 
 	// 使用 zinterstore 把分区的帖子set与帖子分数的 zset 生成一个新的zset
 	// 针对新的zset 按之前的逻辑取数据
@@ -111,9 +179,10 @@ func GetCommunityPostIDsInOrder(p *models.ParamPostList) ([]string, error) {
 	if client.Exists(context.Background(), key).Val() < 1 {
 		// 不存在，需要计算
 		pipeline := client.Pipeline()
+		// 社区集合的分数不参与计算，排序策略的分数可能小于1甚至为负
 		pipeline.ZInterStore(context.Background(), key, &redis.ZStore{
-			Keys:      []string{cKey, orderKey},
-			Aggregate: "MAX",
+			Keys:    []string{cKey, orderKey},
+			Weights: []float64{0, 1},
 		}) // zinterstore 计算
 
 		// 生成随机TTL，防止缓存雪崩
@@ -126,7 +195,22 @@ func GetCommunityPostIDsInOrder(p *models.ParamPostList) ([]string, error) {
 		}
 	}
 	// 存在的话就直接根据key查询ids
-	return getIDsFormKey(key, p.Page, p.Size)
+	return getIDsPage(key, p)
+}
+
+// CountPosts 获取已发布的帖子总数，发布、删除和恢复时随排序集合与社区集合一起更新，无需COUNT(*)
+// 参数:
+//   - communityID: 社区ID，0表示全站
+//
+// 返回值:
+//   - int64: 帖子总数
+//   - error: 可能的错误
+func CountPosts(communityID uint64) (int64, error) {
+	if communityID == 0 {
+		return client.ZCard(context.Background(), getRedisKey(KeyPostTimeZSet)).Result()
+	}
+	cKey := getRedisKey(KeyCommunitySetPF + strconv.FormatUint(communityID, 10))
+	return client.SCard(context.Background(), cKey).Result()
 }
 
 // IncrementPostViewCount 增加帖子访问量
@@ -665,6 +749,130 @@ func InitPostViewZSet(viewCounts map[uint64]int64) error {
 	return nil
 }
 
+// RemovePost 帖子删除后从排序集合、社区集合和作者时间线中移除，并删除缓存与访问量计数
+// 粉丝时间线中的帖子ID不逐个清理，读取时由数据库过滤
+// 参数:
+//   - post: 帖子，需要post_id、author_id和community_id
+//
+// 返回值:
+//   - err: 可能的错误
+func RemovePost(post *models.Post) error {
+	ctx := context.Background()
+	pid := strconv.FormatUint(post.PostID, 10)
+	cid := strconv.FormatUint(post.CommunityID, 10)
+
+	pipeline := client.TxPipeline()
+	for _, key := range postOrderKeys() {
+		pipeline.ZRem(ctx, key, pid)
+		// 社区排序缓存由ZINTERSTORE生成，同样需要移除
+		pipeline.ZRem(ctx, key+cid, pid)
+	}
+	pipeline.SRem(ctx, getRedisKey(KeyCommunitySetPF+cid), pid)
+	removePostFromTags(ctx, pipeline, pid, cid, post.Tags)
+	pipeline.ZRem(ctx, getUserPostsKey(post.AuthorID), pid)
+	pipeline.SRem(ctx, getUserPostSetKey(post.AuthorID), pid)
+	// 访问量已写回数据库，删除计数避免同步任务把帖子重新加入访问量排序
+	pipeline.Del(ctx,
+		GetPostCacheKey(post.AuthorID, post.PostID),
+		getRedisKey(KeyPostViewCountPF+pid),
+		getRedisKey(KeyPostViewSetPF+pid),
+	)
+	_, err := pipeline.Exec(ctx)
+	return err
+}
+
+// MovePostCommunity 帖子修改社区后，把它从原社区的集合移到新社区
+// 原社区的排序缓存中移除该帖子，新社区的排序缓存直接删除，下次读取时重新计算
+// 参数:
+//   - postID: 帖子ID
+//   - from: 原社区ID
+//   - to: 新社区ID
+//   - tags: 帖子的标签，标签下按社区过滤的缓存同样处理
+//
+// 返回值:
+//   - err: 可能的错误
+func MovePostCommunity(postID, from, to uint64, tags []string) error {
+	ctx := context.Background()
+	pid := strconv.FormatUint(postID, 10)
+	fromID := strconv.FormatUint(from, 10)
+	toID := strconv.FormatUint(to, 10)
+
+	pipeline := client.TxPipeline()
+	pipeline.SRem(ctx, getRedisKey(KeyCommunitySetPF+fromID), pid)
+	pipeline.SAdd(ctx, getRedisKey(KeyCommunitySetPF+toID), pid)
+	for _, key := range postOrderKeys() {
+		pipeline.ZRem(ctx, key+fromID, pid)
+		pipeline.Del(ctx, key+toID)
+	}
+	for _, tag := range tags {
+		for _, prefix := range tagOrderPrefixes() {
+			key := getTagKey(prefix, tag)
+			pipeline.ZRem(ctx, key+":"+fromID, pid)
+			pipeline.Del(ctx, key+":"+toID)
+		}
+	}
+	_, err := pipeline.Exec(ctx)
+	return err
+}
+
+// RestorePost 恢复帖子在各排序集合、社区集合和作者时间线中的位置
+// 参数:
+//   - post: 帖子，需要post_id、author_id、community_id、create_time和view_count
+//   - ranks: 按投票记录重新计算的各排序策略的分数
+//
+// 返回值:
+//   - err: 可能的错误
+func RestorePost(post *models.Post, ranks map[string]float64) error {
+	ctx := context.Background()
+	pid := strconv.FormatUint(post.PostID, 10)
+	cid := strconv.FormatUint(post.CommunityID, 10)
+	createTime := float64(post.CreateTime.Unix())
+
+	// 分数按投票记录重新计算：发布时间 + (赞成票 - 反对票) * 每票分数
+	ups, downs, err := countVotes(ctx, client, pid)
+	if err != nil {
+		return err
+	}
+
+	score := createTime + float64(ups-downs)*scorePerVote
+
+	pipeline := client.TxPipeline()
+	pipeline.ZAdd(ctx, getRedisKey(KeyPostTimeZSet), &redis.Z{Score: createTime, Member: pid})
+	pipeline.ZAdd(ctx, getRedisKey(KeyPostScoreZSet), &redis.Z{Score: score, Member: pid})
+	addPostToTags(ctx, pipeline, pid, post.Tags, createTime, score)
+	for order, rank := range ranks {
+		pipeline.ZAdd(ctx, getRankKey(order), &redis.Z{Score: rank, Member: pid})
+	}
+	pipeline.ZAdd(ctx, getRedisKey(KeyPostViewZSet), &redis.Z{Score: float64(post.ViewCount), Member: pid})
+	pipeline.Set(ctx, getRedisKey(KeyPostViewCountPF+pid), post.ViewCount,
+		generateRandomTTL(ViewCountBaseTTL, ViewCountJitterPercent))
+	pipeline.SAdd(ctx, getRedisKey(KeyCommunitySetPF+cid), pid)
+	zaddIfExistsScript.Eval(ctx, pipeline, []string{getUserPostsKey(post.AuthorID)}, post.CreateTime.Unix(), pid, TimelineMaxLen)
+	saddIfExistsScript.Eval(ctx, pipeline, []string{getUserPostSetKey(post.AuthorID)}, pid)
+	// 社区排序缓存直接删除，下次读取时重新计算
+	for _, key := range postOrderKeys() {
+		pipeline.Del(ctx, key+cid)
+	}
+	pipeline.Del(ctx, GetPostNotExistKey(post.PostID))
+	_, err = pipeline.Exec(ctx)
+	return err
+}
+
+// PurgePost 帖子彻底删除后清理其投票与访问记录
+// 参数:
+//   - postID: 帖子ID
+//
+// 返回值:
+//   - err: 可能的错误
+func PurgePost(postID uint64) error {
+	pid := strconv.FormatUint(postID, 10)
+	return client.Del(context.Background(),
+		getRedisKey(KeyPostVotedPF+pid),
+		getRedisKey(KeyPostViewCountPF+pid),
+		getRedisKey(KeyPostViewSetPF+pid),
+	).Err()
+}
+
 // TestRandomTTL 测试随机TTL生成功能
 // 参数:
 //   - baseTTL: 基础TTL时间
@@ -0,0 +1,128 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"land/models"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const rankUpdateRetries = 5 // 计算分数期间有新投票时的最大重试次数
+
+var ErrRankConflict = errors.New("帖子投票频繁，排序分数更新失败")
+
+// getRankKey 排序策略的分数有序集合
+func getRankKey(order string) string {
+	return getRedisKey(KeyPostRankPF + order)
+}
+
+// isRankOrder 是否为按排序策略计算分数的排序方式
+func isRankOrder(order string) bool {
+	for _, o := range models.RankOrders {
+		if o == order {
+			return true
+		}
+	}
+	return false
+}
+
+// getOrderKey 排序方式对应的全站排序集合
+func getOrderKey(order string) string {
+	switch {
+	case order == models.OrderScore:
+		return getRedisKey(KeyPostScoreZSet)
+	case order == models.OrderView:
+		return getRedisKey(KeyPostViewZSet)
+	case isRankOrder(order):
+		return getRankKey(order)
+	}
+	return getRedisKey(KeyPostTimeZSet)
+}
+
+// postOrderKeys 全站的各个排序集合，帖子删除和恢复时一并处理
+func postOrderKeys() []string {
+	keys := []string{
+		getRedisKey(KeyPostTimeZSet),
+		getRedisKey(KeyPostScoreZSet),
+		getRedisKey(KeyPostViewZSet),
+	}
+	for _, order := range models.RankOrders {
+		keys = append(keys, getRankKey(order))
+	}
+	return keys
+}
+
+// countVotes 统计帖子的赞成票和反对票数
+func countVotes(ctx context.Context, c redis.Cmdable, pid string) (up, down int64, err error) {
+	votedKey := getRedisKey(KeyPostVotedPF + pid)
+	if up, err = c.ZCount(ctx, votedKey, "1", "1").Result(); err != nil {
+		return 0, 0, err
+	}
+	down, err = c.ZCount(ctx, votedKey, "-1", "-1").Result()
+	return up, down, err
+}
+
+// GetPostVoteCounts 获取帖子的赞成票和反对票数
+// 参数:
+//   - postID: 帖子ID
+//
+// 返回值:
+//   - up: 赞成票数
+//   - down: 反对票数
+//   - err: 可能的错误
+func GetPostVoteCounts(postID string) (up, down int64, err error) {
+	return countVotes(context.Background(), client, postID)
+}
+
+// SetPostRanks 写入帖子在各排序策略中的分数，用于按投票记录全量重算
+// 参数:
+//   - postID: 帖子ID
+//   - ranks: 排序方式到分数的映射
+//
+// 返回值:
+//   - error: 可能的错误
+func SetPostRanks(postID string, ranks map[string]float64) error {
+	ctx := context.Background()
+	pipeline := client.TxPipeline()
+	for order, score := range ranks {
+		pipeline.ZAdd(ctx, getRankKey(order), &redis.Z{Score: score, Member: postID})
+	}
+	_, err := pipeline.Exec(ctx)
+	return err
+}
+
+// UpdatePostRanks 按当前的投票记录重新计算帖子在各排序策略中的分数
+// 监视投票记录，计算期间有人投票时重试，保证写入的分数与最新的投票一致；只更新仍在排序中的帖子
+// 参数:
+//   - postID: 帖子ID
+//   - rank: 根据赞成票和反对票数计算各排序方式的分数
+//
+// 返回值:
+//   - error: 多次重试仍冲突时返回ErrRankConflict
+func UpdatePostRanks(postID string, rank func(up, down int64) map[string]float64) error {
+	ctx := context.Background()
+	votedKey := getRedisKey(KeyPostVotedPF + postID)
+	update := func(tx *redis.Tx) error {
+		up, down, err := countVotes(ctx, tx, postID)
+		if err != nil {
+			return err
+		}
+		ranks := rank(up, down)
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			for order, score := range ranks {
+				pipe.ZAddXX(ctx, getRankKey(order), &redis.Z{Score: score, Member: postID})
+			}
+			return nil
+		})
+		return err
+	}
+
+	for i := 0; i < rankUpdateRetries; i++ {
+		err := client.Watch(ctx, update, votedKey)
+		if err != redis.TxFailedErr {
+			return err
+		}
+	}
+	return ErrRankConflict
+}
@@ -0,0 +1,81 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/go-redis/redis/v8"
+)
+
+var (
+	ErrResetTokenNotFound = errors.New("重置令牌不存在或已过期")
+	ErrResetTooFrequent   = errors.New("申请过于频繁")
+)
+
+// SavePasswordResetToken 保存密码重置令牌，同一用户之前签发的令牌随之作废
+// 参数:
+//   - tokenHash: 令牌哈希
+//   - userID: 用户ID
+//
+// 返回值:
+//   - error: 冷却期内重复申请时返回ErrResetTooFrequent
+func SavePasswordResetToken(tokenHash string, userID uint64) error {
+	ctx := context.Background()
+	userKey := getRedisKey(KeyPasswordResetUserPF + strconv.FormatUint(userID, 10))
+
+	// 冷却期内已有令牌则拒绝，防止邮件轰炸
+	oldHash, err := client.Get(ctx, userKey).Result()
+	if err != nil && err != redis.Nil {
+		return err
+	}
+	if oldHash != "" {
+		ttl, err := client.TTL(ctx, userKey).Result()
+		if err != nil {
+			return err
+		}
+		if PasswordResetTTL-ttl < PasswordResetCooldown {
+			return ErrResetTooFrequent
+		}
+	}
+
+	pipeline := client.TxPipeline()
+	if oldHash != "" {
+		pipeline.Del(ctx, getRedisKey(KeyPasswordResetPF+oldHash))
+	}
+	pipeline.Set(ctx, getRedisKey(KeyPasswordResetPF+tokenHash), userID, PasswordResetTTL)
+	pipeline.Set(ctx, userKey, tokenHash, PasswordResetTTL)
+	_, err = pipeline.Exec(ctx)
+	return err
+}
+
+// ConsumePasswordResetToken 消费密码重置令牌，令牌只能使用一次
+// 参数:
+//   - tokenHash: 令牌哈希
+//
+// 返回值:
+//   - uint64: 令牌对应的用户ID
+//   - error: 令牌不存在或已被使用时返回ErrResetTokenNotFound
+func ConsumePasswordResetToken(tokenHash string) (uint64, error) {
+	ctx := context.Background()
+	key := getRedisKey(KeyPasswordResetPF + tokenHash)
+
+	// GET与DEL放在同一事务中，并发请求只有一个能拿到用户ID
+	pipeline := client.TxPipeline()
+	get := pipeline.Get(ctx, key)
+	pipeline.Del(ctx, key)
+	if _, err := pipeline.Exec(ctx); err != nil && err != redis.Nil {
+		return 0, err
+	}
+
+	userID, err := get.Uint64()
+	if err == redis.Nil {
+		return 0, ErrResetTokenNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	client.Del(ctx, getRedisKey(KeyPasswordResetUserPF+strconv.FormatUint(userID, 10)))
+	return userID, nil
+}
@@ -0,0 +1,180 @@
+package redis
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"land/models"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// getSearchTermKey 获取索引词的倒排索引键
+func getSearchTermKey(term string) string {
+	return getRedisKey(KeySearchTermPF + term)
+}
+
+// IndexPost 写入或更新帖子的索引，旧的索引词中不再出现的会被移除
+// 参数:
+//   - postID: 帖子ID
+//   - weights: 索引词到权重的映射
+//
+// 返回值:
+//   - error: 可能的错误
+func IndexPost(postID uint64, weights map[string]float64) error {
+	ctx := context.Background()
+	pid := strconv.FormatUint(postID, 10)
+	docKey := getRedisKey(KeySearchDocPF + pid)
+
+	old, err := client.SMembers(ctx, docKey).Result()
+	if err != nil {
+		return err
+	}
+
+	pipeline := client.TxPipeline()
+	for _, term := range old {
+		if _, ok := weights[term]; !ok {
+			pipeline.ZRem(ctx, getSearchTermKey(term), pid)
+		}
+	}
+	terms := make([]interface{}, 0, len(weights))
+	for term, w := range weights {
+		pipeline.ZAdd(ctx, getSearchTermKey(term), &redis.Z{Score: w, Member: pid})
+		terms = append(terms, term)
+	}
+	pipeline.Del(ctx, docKey)
+	if len(terms) > 0 {
+		pipeline.SAdd(ctx, docKey, terms...)
+	}
+	_, err = pipeline.Exec(ctx)
+	return err
+}
+
+// RemovePostIndex 从索引中移除帖子
+// 参数:
+//   - postID: 帖子ID
+//
+// 返回值:
+//   - error: 可能的错误
+func RemovePostIndex(postID uint64) error {
+	ctx := context.Background()
+	pid := strconv.FormatUint(postID, 10)
+	docKey := getRedisKey(KeySearchDocPF + pid)
+
+	terms, err := client.SMembers(ctx, docKey).Result()
+	if err != nil {
+		return err
+	}
+	pipeline := client.TxPipeline()
+	for _, term := range terms {
+		pipeline.ZRem(ctx, getSearchTermKey(term), pid)
+	}
+	pipeline.Del(ctx, docKey)
+	_, err = pipeline.Exec(ctx)
+	return err
+}
+
+// searchIDF 索引词的逆文档频率，越少见的词权重越高
+func searchIDF(total, df int64) float64 {
+	idf := math.Log(1 + (float64(total-df)+0.5)/(float64(df)+0.5))
+	return math.Max(idf, 0.01)
+}
+
+// getSearchResultKey 按查询词、排序和过滤条件生成结果缓存键
+func getSearchResultKey(terms []string, p *models.ParamPostList) string {
+	h := sha1.New()
+	h.Write([]byte(strings.Join([]string{
+		p.Order,
+		strconv.FormatUint(p.CommunityID, 10),
+		p.Tag,
+		strings.Join(terms, "\x00"),
+	}, "\x01")))
+	return getRedisKey(KeySearchResultPF + hex.EncodeToString(h.Sum(nil)))
+}
+
+// SearchPostIDs 搜索包含全部查询词的已发布帖子，分页返回帖子ID
+// order为relevance时按相关度（各查询词权重与逆文档频率的乘积之和）排序，
+// 为time/score/view或hot/best/controversial/top时按对应的排序集合排序
+// 参数:
+//   - terms: 查询词
+//   - p: 查询参数，可按社区和标签过滤
+//
+// 返回值:
+//   - *models.PostIDPage: 帖子ID列表、下一页的游标位置和帖子总数
+//   - error: 可能的错误
+func SearchPostIDs(terms []string, p *models.ParamPostList) (*models.PostIDPage, error) {
+	ctx := context.Background()
+	key := getSearchResultKey(terms, p)
+	if client.Exists(ctx, key).Val() > 0 {
+		return getIDsPage(key, p)
+	}
+
+	// 统计已发布帖子总数和各查询词的文档频率
+	timeKey := getRedisKey(KeyPostTimeZSet)
+	pipeline := client.Pipeline()
+	totalCmd := pipeline.ZCard(ctx, timeKey)
+	dfCmds := make([]*redis.IntCmd, len(terms))
+	for i, term := range terms {
+		dfCmds[i] = pipeline.ZCard(ctx, getSearchTermKey(term))
+	}
+	if _, err := pipeline.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	relevance := p.Order == models.OrderRelevance
+	store := &redis.ZStore{Aggregate: "SUM"}
+	for i, term := range terms {
+		df := dfCmds[i].Val()
+		if df == 0 {
+			// 所有查询词都要出现，任何一个词没有命中时结果为空
+			return &models.PostIDPage{IDs: []string{}}, nil
+		}
+		weight := 0.0
+		if relevance {
+			weight = searchIDF(totalCmd.Val(), df)
+		}
+		store.Keys = append(store.Keys, getSearchTermKey(term))
+		store.Weights = append(store.Weights, weight)
+	}
+
+	// 与post:time求交集，保证只返回已发布的帖子；按时间排序时直接取其分数
+	timeWeight := 0.0
+	if p.Order == models.OrderTime {
+		timeWeight = 1
+	}
+	store.Keys = append(store.Keys, timeKey)
+	store.Weights = append(store.Weights, timeWeight)
+	if p.Order == models.OrderScore || isRankOrder(p.Order) {
+		store.Keys = append(store.Keys, getOrderKey(p.Order))
+		store.Weights = append(store.Weights, 1)
+	}
+	if p.CommunityID != 0 {
+		store.Keys = append(store.Keys, getRedisKey(KeyCommunitySetPF+strconv.FormatUint(p.CommunityID, 10)))
+		store.Weights = append(store.Weights, 0)
+	}
+	if p.Tag != "" {
+		store.Keys = append(store.Keys, getTagKey(KeyTagTimePF, p.Tag))
+		store.Weights = append(store.Weights, 0)
+	}
+
+	tx := client.TxPipeline()
+	tx.ZInterStore(ctx, key, store)
+	if p.Order == models.OrderView {
+		// 没有访问过的帖子不在post:view中，先取出有访问量的部分，再合并回结果，访问量缺省为0
+		viewed := key + ":view"
+		tx.ZInterStore(ctx, viewed, &redis.ZStore{
+			Keys:    []string{key, getRedisKey(KeyPostViewZSet)},
+			Weights: []float64{0, 1},
+		})
+		tx.ZUnionStore(ctx, key, &redis.ZStore{Keys: []string{key, viewed}, Aggregate: "SUM"})
+		tx.Del(ctx, viewed)
+	}
+	tx.Expire(ctx, key, SearchResultTTL)
+	if _, err := tx.Exec(ctx); err != nil {
+		return nil, err
+	}
+	return getIDsPage(key, p)
+}
@@ -0,0 +1,200 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"land/models"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+var (
+	ErrSessionNotFound = errors.New("会话不存在或已吊销")
+)
+
+// getSessionKey 生成会话键
+func getSessionKey(sessionID string) string {
+	return getRedisKey(KeySessionPF + sessionID)
+}
+
+// getUserSessionsKey 生成用户会话集合键
+func getUserSessionsKey(userID uint64) string {
+	return getRedisKey(KeyUserSessionsPF + strconv.FormatUint(userID, 10))
+}
+
+// CreateSession 创建登录会话
+// 参数:
+//   - s: 会话信息
+//   - expire: 过期时间（与refresh token一致）
+//
+// 返回值:
+//   - error: 可能的错误
+func CreateSession(s *models.Session, expire time.Duration) error {
+	ctx := context.Background()
+	key := getSessionKey(s.SessionID)
+	userKey := getUserSessionsKey(s.UserID)
+
+	pipeline := client.TxPipeline()
+	pipeline.HSet(ctx, key, map[string]interface{}{
+		"user_id":     s.UserID,
+		"device_name": s.DeviceName,
+		"ip":          s.IP,
+		"user_agent":  s.UserAgent,
+		"create_time": s.CreateTime.Unix(),
+		"last_seen":   s.LastSeen.Unix(),
+	})
+	pipeline.Expire(ctx, key, expire)
+	pipeline.SAdd(ctx, userKey, s.SessionID)
+	pipeline.Expire(ctx, userKey, expire)
+	_, err := pipeline.Exec(ctx)
+	return err
+}
+
+// GetSession 获取会话信息
+// 参数:
+//   - sessionID: 会话ID
+//
+// 返回值:
+//   - *models.Session: 会话信息
+//   - error: 会话不存在时返回ErrSessionNotFound
+func GetSession(sessionID string) (*models.Session, error) {
+	fields, err := client.HGetAll(context.Background(), getSessionKey(sessionID)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, ErrSessionNotFound
+	}
+	return parseSession(sessionID, fields), nil
+}
+
+// touchSessionScript 会话存在且属于该用户时刷新最近活跃时间和IP
+// 校验与写入在同一脚本中完成，会话在两者之间过期或被吊销时不会重新创建出没有过期时间的键
+var touchSessionScript = redis.NewScript(`
+if redis.call("HGET", KEYS[1], "user_id") ~= ARGV[1] then
+	return 0
+end
+redis.call("HSET", KEYS[1], "last_seen", ARGV[2])
+if ARGV[3] ~= "" then
+	redis.call("HSET", KEYS[1], "ip", ARGV[3])
+end
+return 1
+`)
+
+// TouchSession 校验会话归属并刷新最近活跃信息
+// 参数:
+//   - userID: 用户ID
+//   - sessionID: 会话ID
+//   - ip: 本次请求的客户端IP，为空时不更新
+//
+// 返回值:
+//   - bool: 会话存在且属于该用户
+//   - error: 可能的错误
+func TouchSession(userID uint64, sessionID, ip string) (bool, error) {
+	ok, err := touchSessionScript.Run(context.Background(), client,
+		[]string{getSessionKey(sessionID)},
+		strconv.FormatUint(userID, 10), time.Now().Unix(), ip).Int()
+	if err != nil {
+		return false, err
+	}
+	return ok == 1, nil
+}
+
+// ListSessions 列出用户所有有效会话，顺带清理已过期的会话ID
+// 参数:
+//   - userID: 用户ID
+//
+// 返回值:
+//   - []*models.Session: 会话列表
+//   - error: 可能的错误
+func ListSessions(userID uint64) ([]*models.Session, error) {
+	ctx := context.Background()
+	userKey := getUserSessionsKey(userID)
+
+	ids, err := client.SMembers(ctx, userKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline := client.Pipeline()
+	cmds := make([]*redis.StringStringMapCmd, 0, len(ids))
+	for _, id := range ids {
+		cmds = append(cmds, pipeline.HGetAll(ctx, getSessionKey(id)))
+	}
+	if _, err = pipeline.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	sessions := make([]*models.Session, 0, len(ids))
+	stale := make([]interface{}, 0)
+	for i, cmd := range cmds {
+		fields := cmd.Val()
+		if len(fields) == 0 {
+			stale = append(stale, ids[i])
+			continue
+		}
+		sessions = append(sessions, parseSession(ids[i], fields))
+	}
+	if len(stale) > 0 {
+		client.SRem(ctx, userKey, stale...)
+	}
+	return sessions, nil
+}
+
+// DeleteSession 吊销会话，其refresh token与access token随之失效
+// 参数:
+//   - userID: 用户ID
+//   - sessionID: 会话ID
+//
+// 返回值:
+//   - error: 可能的错误
+func DeleteSession(userID uint64, sessionID string) error {
+	ctx := context.Background()
+	pipeline := client.TxPipeline()
+	pipeline.Del(ctx, getSessionKey(sessionID))
+	pipeline.SRem(ctx, getUserSessionsKey(userID), sessionID)
+	_, err := pipeline.Exec(ctx)
+	return err
+}
+
+// DeleteAllSessions 吊销用户名下所有会话
+// 参数:
+//   - userID: 用户ID
+//
+// 返回值:
+//   - error: 可能的错误
+func DeleteAllSessions(userID uint64) error {
+	ctx := context.Background()
+	userKey := getUserSessionsKey(userID)
+
+	ids, err := client.SMembers(ctx, userKey).Result()
+	if err != nil {
+		return err
+	}
+
+	pipeline := client.TxPipeline()
+	for _, id := range ids {
+		pipeline.Del(ctx, getSessionKey(id))
+	}
+	pipeline.Del(ctx, userKey)
+	_, err = pipeline.Exec(ctx)
+	return err
+}
+
+// parseSession 将会话hash解析为结构体
+func parseSession(sessionID string, fields map[string]string) *models.Session {
+	userID, _ := strconv.ParseUint(fields["user_id"], 10, 64)
+	createTime, _ := strconv.ParseInt(fields["create_time"], 10, 64)
+	lastSeen, _ := strconv.ParseInt(fields["last_seen"], 10, 64)
+	return &models.Session{
+		SessionID:  sessionID,
+		UserID:     userID,
+		DeviceName: fields["device_name"],
+		IP:         fields["ip"],
+		UserAgent:  fields["user_agent"],
+		CreateTime: time.Unix(createTime, 0),
+		LastSeen:   time.Unix(lastSeen, 0),
+	}
+}
@@ -0,0 +1,179 @@
+package redis
+
+import (
+	"context"
+	"land/models"
+	"strconv"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// tagOrderPrefixes 每个标签维护的有序集合，及按访问量和各排序策略排序的缓存
+func tagOrderPrefixes() []string {
+	return append([]string{KeyTagTimePF, KeyTagScorePF}, tagCachePrefixes()...)
+}
+
+// tagCachePrefixes 标签下由ZINTERSTORE生成的排序缓存
+func tagCachePrefixes() []string {
+	prefixes := []string{KeyTagViewPF}
+	for _, order := range models.RankOrders {
+		prefixes = append(prefixes, getTagRankPF(order))
+	}
+	return prefixes
+}
+
+// getTagRankPF 标签下按排序策略排序的缓存键前缀
+func getTagRankPF(order string) string {
+	return KeyTagRankPF + order + ":"
+}
+
+// getTagKey 获取标签的有序集合键
+func getTagKey(prefix, name string) string {
+	return getRedisKey(prefix + name)
+}
+
+// addPostToTags 把帖子加入各标签的时间和分数排序
+func addPostToTags(ctx context.Context, pipe redis.Pipeliner, pid string, tags []string, publishTime, score float64) {
+	for _, tag := range tags {
+		pipe.ZAdd(ctx, getTagKey(KeyTagTimePF, tag), &redis.Z{Score: publishTime, Member: pid})
+		pipe.ZAdd(ctx, getTagKey(KeyTagScorePF, tag), &redis.Z{Score: score, Member: pid})
+		// 访问量和排序策略的排序是缓存，直接删除，下次读取时重新计算
+		for _, prefix := range tagCachePrefixes() {
+			pipe.Del(ctx, getTagKey(prefix, tag))
+		}
+	}
+}
+
+// removePostFromTags 把帖子从各标签的排序及其按社区过滤的缓存中移除
+func removePostFromTags(ctx context.Context, pipe redis.Pipeliner, pid, cid string, tags []string) {
+	for _, tag := range tags {
+		for _, prefix := range tagOrderPrefixes() {
+			key := getTagKey(prefix, tag)
+			pipe.ZRem(ctx, key, pid)
+			pipe.ZRem(ctx, key+":"+cid, pid)
+		}
+	}
+}
+
+// incrTagScores 调整帖子在各标签分数排序中的分数，只调整仍在排序中的帖子
+func incrTagScores(ctx context.Context, pipe redis.Pipeliner, pid string, tags []string, delta float64) {
+	for _, tag := range tags {
+		pipe.ZIncrXX(ctx, getTagKey(KeyTagScorePF, tag), &redis.Z{Score: delta, Member: pid})
+	}
+}
+
+// UpdatePostTags 帖子的标签修改后调整各标签的排序，帖子未发布时只移除不添加
+// 参数:
+//   - postID: 帖子ID
+//   - communityID: 社区ID
+//   - added: 新增的标签
+//   - removed: 移除的标签
+//
+// 返回值:
+//   - error: 可能的错误
+func UpdatePostTags(postID, communityID uint64, added, removed []string) error {
+	ctx := context.Background()
+	pid := strconv.FormatUint(postID, 10)
+	cid := strconv.FormatUint(communityID, 10)
+
+	pipeline := client.TxPipeline()
+	removePostFromTags(ctx, pipeline, pid, cid, removed)
+	if len(added) > 0 {
+		publishTime, err := client.ZScore(ctx, getRedisKey(KeyPostTimeZSet), pid).Result()
+		if err != nil && err != redis.Nil {
+			return err
+		}
+		if err == nil {
+			score, err := client.ZScore(ctx, getRedisKey(KeyPostScoreZSet), pid).Result()
+			if err == redis.Nil {
+				score = publishTime
+			} else if err != nil {
+				return err
+			}
+			addPostToTags(ctx, pipeline, pid, added, publishTime, score)
+		}
+	}
+	_, err := pipeline.Exec(ctx)
+	return err
+}
+
+// GetTagPostIDsInOrder 按排序方式分页获取标签下的帖子ID，指定社区时只返回该社区的帖子
+// 参数:
+//   - p: 查询参数，tag为规范化后的标签
+//
+// 返回值:
+//   - *models.PostIDPage: 帖子ID列表、下一页的游标位置和帖子总数
+//   - error: 可能的错误
+func GetTagPostIDsInOrder(p *models.ParamPostList) (*models.PostIDPage, error) {
+	ctx := context.Background()
+	timeKey := getTagKey(KeyTagTimePF, p.Tag)
+	key := timeKey
+	switch p.Order {
+	case models.OrderScore:
+		key = getTagKey(KeyTagScorePF, p.Tag)
+	case models.OrderView:
+		// 标签下的帖子与全站访问量排序求交集，只取访问量作为分数
+		key = getTagKey(KeyTagViewPF, p.Tag)
+		if err := interStoreIfMissing(ctx, key, getRedisKey(KeyPostViewZSet), timeKey); err != nil {
+			return nil, err
+		}
+	default:
+		if isRankOrder(p.Order) {
+			// 同样与全站的排序策略分数求交集
+			key = getTagKey(getTagRankPF(p.Order), p.Tag)
+			if err := interStoreIfMissing(ctx, key, getRankKey(p.Order), timeKey); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if p.CommunityID != 0 {
+		cid := strconv.FormatUint(p.CommunityID, 10)
+		cKey := getRedisKey(KeyCommunitySetPF + cid)
+		orderKey := key
+		key = orderKey + ":" + cid
+		if err := interStoreIfMissing(ctx, key, orderKey, cKey); err != nil {
+			return nil, err
+		}
+	}
+	return getIDsPage(key, p)
+}
+
+// interStoreIfMissing 缓存不存在时求scoreKey与filterKey的交集，分数只取scoreKey中的分数
+func interStoreIfMissing(ctx context.Context, key, scoreKey, filterKey string) error {
+	if client.Exists(ctx, key).Val() > 0 {
+		return nil
+	}
+	pipeline := client.Pipeline()
+	pipeline.ZInterStore(ctx, key, &redis.ZStore{
+		Keys:    []string{scoreKey, filterKey},
+		Weights: []float64{1, 0},
+	})
+	pipeline.Expire(ctx, key, generateRandomTTL(CommunityCacheBaseTTL, CommunityCacheJitterPercent))
+	_, err := pipeline.Exec(ctx)
+	return err
+}
+
+// CountTagPosts 批量获取标签下已发布的帖子数
+// 参数:
+//   - names: 标签名
+//
+// 返回值:
+//   - []int64: 与names一一对应的帖子数
+//   - error: 可能的错误
+func CountTagPosts(names []string) ([]int64, error) {
+	ctx := context.Background()
+	pipeline := client.Pipeline()
+	cmds := make([]*redis.IntCmd, len(names))
+	for i, name := range names {
+		cmds[i] = pipeline.ZCard(ctx, getTagKey(KeyTagTimePF, name))
+	}
+	if _, err := pipeline.Exec(ctx); err != nil {
+		return nil, err
+	}
+	counts := make([]int64, len(names))
+	for i, cmd := range cmds {
+		counts[i] = cmd.Val()
+	}
+	return counts, nil
+}
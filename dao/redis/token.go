@@ -0,0 +1,91 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+var (
+	ErrRefreshTokenNotFound = errors.New("refresh token不存在或已过期")
+)
+
+// RefreshTokenInfo refresh token记录
+type RefreshTokenInfo struct {
+	UserID    int64  // 所属用户
+	SessionID string // 所属会话
+	Reused    bool   // 是否为重复使用（已被消费过）
+}
+
+// SaveRefreshToken 保存refresh token并续期其所属会话
+// 参数:
+//   - tokenHash: refresh token的哈希
+//   - userID: 用户ID
+//   - sessionID: 会话ID
+//   - expire: 过期时间
+//
+// 返回值:
+//   - error: 可能的错误
+func SaveRefreshToken(tokenHash string, userID int64, sessionID string, expire time.Duration) error {
+	ctx := context.Background()
+	tokenKey := getRedisKey(KeyRefreshTokenPF + tokenHash)
+
+	pipeline := client.TxPipeline()
+	pipeline.HSet(ctx, tokenKey, map[string]interface{}{
+		"user_id": userID,
+		"session": sessionID,
+		"used":    0,
+	})
+	pipeline.Expire(ctx, tokenKey, expire)
+	pipeline.Expire(ctx, getRedisKey(KeySessionPF+sessionID), expire)
+	pipeline.Expire(ctx, getRedisKey(KeyUserSessionsPF+strconv.FormatInt(userID, 10)), expire)
+	_, err := pipeline.Exec(ctx)
+	return err
+}
+
+// consumeRefreshTokenScript token存在时将使用次数加一，返回user_id、session与加一后的次数
+// 存在判断与自增在同一脚本中完成，token在两者之间过期时不会重新创建出没有过期时间的键
+var consumeRefreshTokenScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return false
+end
+local used = redis.call("HINCRBY", KEYS[1], "used", 1)
+local fields = redis.call("HMGET", KEYS[1], "user_id", "session")
+return {fields[1], fields[2], used}
+`)
+
+// ConsumeRefreshToken 消费refresh token，已使用过的token会被标记为重复使用
+// 参数:
+//   - tokenHash: refresh token的哈希
+//
+// 返回值:
+//   - *RefreshTokenInfo: token记录
+//   - error: token不存在时返回ErrRefreshTokenNotFound
+func ConsumeRefreshToken(tokenHash string) (*RefreshTokenInfo, error) {
+	res, err := consumeRefreshTokenScript.Run(context.Background(), client,
+		[]string{getRedisKey(KeyRefreshTokenPF + tokenHash)}).Slice()
+	if err == redis.Nil {
+		return nil, ErrRefreshTokenNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 脚本原子执行，并发刷新时只有第一个请求得到1
+	userIDStr, _ := res[0].(string)
+	sessionID, _ := res[1].(string)
+	used, _ := res[2].(int64)
+	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	if err != nil {
+		return nil, ErrRefreshTokenNotFound
+	}
+
+	return &RefreshTokenInfo{
+		UserID:    userID,
+		SessionID: sessionID,
+		Reused:    used > 1,
+	}, nil
+}
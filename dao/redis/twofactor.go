@@ -0,0 +1,104 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/go-redis/redis/v8"
+)
+
+var (
+	ErrChallengeNotFound = errors.New("登录挑战不存在或已过期")
+)
+
+// SaveTwoFactorChallenge 保存两步验证登录挑战
+// 参数:
+//   - challengeHash: 挑战令牌哈希
+//   - userID: 用户ID
+//   - device: 登录设备名称
+//
+// 返回值:
+//   - error: 可能的错误
+func SaveTwoFactorChallenge(challengeHash string, userID uint64, device string) error {
+	ctx := context.Background()
+	key := getRedisKey(KeyTwoFactorChallengePF + challengeHash)
+
+	pipeline := client.TxPipeline()
+	pipeline.HSet(ctx, key, "user_id", userID, "device", device, "attempts", 0)
+	pipeline.Expire(ctx, key, TwoFactorChallengeTTL)
+	_, err := pipeline.Exec(ctx)
+	return err
+}
+
+// GetTwoFactorChallenge 读取登录挑战并累加尝试次数
+// 超过最大尝试次数的挑战直接作废，防止在有效期内穷举验证码
+// 参数:
+//   - challengeHash: 挑战令牌哈希
+//
+// 返回值:
+//   - uint64: 用户ID
+//   - string: 登录设备名称
+//   - error: 挑战不存在、已过期或尝试次数耗尽时返回ErrChallengeNotFound
+func GetTwoFactorChallenge(challengeHash string) (uint64, string, error) {
+	ctx := context.Background()
+	key := getRedisKey(KeyTwoFactorChallengePF + challengeHash)
+
+	pipeline := client.TxPipeline()
+	get := pipeline.HMGet(ctx, key, "user_id", "device")
+	incr := pipeline.HIncrBy(ctx, key, "attempts", 1)
+	if _, err := pipeline.Exec(ctx); err != nil {
+		return 0, "", err
+	}
+
+	vals := get.Val()
+	uidStr, ok := vals[0].(string)
+	if !ok {
+		// 键不存在时HIncrBy会创建只含attempts的新hash，需要清理
+		client.Del(ctx, key)
+		return 0, "", ErrChallengeNotFound
+	}
+	if incr.Val() > TwoFactorMaxAttempts {
+		client.Del(ctx, key)
+		return 0, "", ErrChallengeNotFound
+	}
+
+	userID, err := strconv.ParseUint(uidStr, 10, 64)
+	if err != nil {
+		return 0, "", err
+	}
+	device, _ := vals[1].(string)
+	return userID, device, nil
+}
+
+// DeleteTwoFactorChallenge 删除登录挑战，验证通过后调用，保证挑战只能使用一次
+// 参数:
+//   - challengeHash: 挑战令牌哈希
+//
+// 返回值:
+//   - bool: 是否由本次调用删除，并发完成同一挑战时只有一个返回true
+//   - error: 可能的错误
+func DeleteTwoFactorChallenge(challengeHash string) (bool, error) {
+	n, err := client.Del(context.Background(), getRedisKey(KeyTwoFactorChallengePF+challengeHash)).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// MarkTOTPStepUsed 标记用户已使用的TOTP时间步
+// 参数:
+//   - userID: 用户ID
+//   - step: 验证码对应的时间步
+//
+// 返回值:
+//   - bool: 首次使用返回true，重放返回false
+//   - error: 可能的错误
+func MarkTOTPStepUsed(userID uint64, step int64) (bool, error) {
+	key := getRedisKey(KeyTwoFactorUsedStepPF + strconv.FormatUint(userID, 10) + ":" + strconv.FormatInt(step, 10))
+	ok, err := client.SetNX(context.Background(), key, 1, TwoFactorUsedStepTTL).Result()
+	if err != nil && err != redis.Nil {
+		return false, err
+	}
+	return ok, nil
+}
@@ -0,0 +1,18 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+)
+
+// AcquireEmailVerifyCooldown 占用验证邮件发送冷却期
+// 参数:
+//   - userID: 用户ID
+//
+// 返回值:
+//   - bool: 是否成功占用（false表示仍在冷却期内）
+//   - error: 可能的错误
+func AcquireEmailVerifyCooldown(userID uint64) (bool, error) {
+	key := getRedisKey(KeyEmailVerifyCooldownPF + strconv.FormatUint(userID, 10))
+	return client.SetNX(context.Background(), key, 1, EmailVerifyCooldown).Result()
+}
@@ -13,8 +13,9 @@ import (
 // 推荐阅读
 // 基于用户投票的相关算法：http://www.ruanyifeng.com/blog/algorithm/
 
-// 本项目使用简化版的投票分数
+// 本项目使用简化版的投票分数（order=score）
 // 投一票就加432分   86400/200  --> 200张赞成票可以给你的帖子续一天
+// hot/best/controversial/top 排序的分数按投票记录重新计算，见 UpdatePostRanks
 
 /* 投票的几种情况：
    direction=1时，有两种情况：
@@ -28,14 +29,13 @@ import (
    	2. 之前投赞成票，现在改投反对票    --> 更新分数和投票记录  差值的绝对值：2  -432*2
 
    投票的限制：
-   每个贴子自发表之日起一个星期之内允许用户投票，超过一个星期就不允许再投票了。
+   每个贴子自发表之日起一段时间内允许用户投票（默认一个星期，可按社区配置），超过期限就不允许再投票了。
    	1. 到期之后将redis中保存的赞成票数及反对票数存储到mysql表中
    	2. 到期之后删除那个 KeyPostVotedZSetPF
 */
 
 const (
-	oneWeekInSeconds = 7 * 24 * 3600
-	scorePerVote     = 432 // 每一票值多少分
+	scorePerVote = 432 // 每一票值多少分
 )
 
 var (
@@ -43,34 +43,73 @@ var (
 	ErrVoteRepeated   = errors.New("不允许重复投票")
 )
 
-func CreatePost(postID, communityID uint64) error {
+// CreatePost 帖子发布后加入时间、分数排序、社区集合和各标签的排序
+// 参数:
+//   - postID: 帖子ID
+//   - authorID: 作者ID
+//   - communityID: 社区ID
+//   - publishTime: 发布时间（Unix秒），草稿和定时帖子为实际发布的时间而不是创建时间
+//   - tags: 帖子的标签
+//   - ranks: 没有投票时各排序策略的初始分数
+//
+// 返回值:
+//   - error: 可能的错误
+func CreatePost(postID, authorID, communityID uint64, publishTime int64, tags []string, ranks map[string]float64) error {
 	pipeline := client.TxPipeline()
 
 	// 帖子时间
 	pipeline.ZAdd(context.Background(), getRedisKey(KeyPostTimeZSet), &redis.Z{
-		Score:  float64(time.Now().Unix()),
+		Score:  float64(publishTime),
 		Member: postID,
 	})
 
 	// 帖子分数
 	pipeline.ZAdd(context.Background(), getRedisKey(KeyPostScoreZSet), &redis.Z{
-		Score:  float64(time.Now().Unix()),
+		Score:  float64(publishTime),
 		Member: postID,
 	})
 
+	// 各排序策略的分数
+	for order, rank := range ranks {
+		pipeline.ZAdd(context.Background(), getRankKey(order), &redis.Z{
+			Score:  rank,
+			Member: postID,
+		})
+	}
+
 	// 更新：把帖子id加到社区的set
 	cKey := getRedisKey(KeyCommunitySetPF + strconv.Itoa(int(communityID)))
 	pipeline.SAdd(context.Background(), cKey, postID)
+
+	// 各标签的时间和分数排序
+	addPostToTags(context.Background(), pipeline, strconv.FormatUint(postID, 10), tags, float64(publishTime), float64(publishTime))
+
+	// 作者的帖子时间线，供粉丝重建关注时间线和拉取大V帖子
+	zaddIfExistsScript.Eval(context.Background(), pipeline, []string{getUserPostsKey(authorID)}, publishTime, postID, TimelineMaxLen)
+	saddIfExistsScript.Eval(context.Background(), pipeline, []string{getUserPostSetKey(authorID)}, postID)
+
+	// 草稿阶段访问详情可能留下不存在标记，发布后立即可见
+	pipeline.Del(context.Background(), GetPostNotExistKey(postID))
 	_, err := pipeline.Exec(context.Background())
 	return err
 }
 
-func VoteForPost(userID, postID string, value float64) error {
+// VoteForPost 投票并更新帖子在全站和各标签中的分数
+// 参数:
+//   - userID: 用户ID
+//   - postID: 帖子ID
+//   - value: 1赞成，0取消，-1反对
+//   - tags: 帖子的标签
+//   - voteWindow: 投票期限（秒），从发布时间算起
+//
+// 返回值:
+//   - error: 超过投票期限返回ErrVoteTimeExpire，重复投票返回ErrVoteRepeated
+func VoteForPost(userID, postID string, value float64, tags []string, voteWindow int64) error {
 	// 1. 先检查投票时间是否过期
 	postTime := client.ZScore(context.Background(), getRedisKey(KeyPostTimeZSet), postID).Val()
 
 	// 时间过期，返回错误
-	if float64(time.Now().Unix())-postTime > oneWeekInSeconds {
+	if float64(time.Now().Unix())-postTime > float64(voteWindow) {
 		return ErrVoteTimeExpire
 	}
 
@@ -92,6 +131,7 @@ func VoteForPost(userID, postID string, value float64) error {
 	// 3. 更新帖子分数
 	pipeline := client.TxPipeline()
 	pipeline.ZIncrBy(context.Background(), getRedisKey(KeyPostScoreZSet), op*diff*scorePerVote, postID)
+	incrTagScores(context.Background(), pipeline, postID, tags, op*diff*scorePerVote)
 
 	// 4. 更新投票记录
 	if value == 0 {
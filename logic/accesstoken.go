@@ -0,0 +1,220 @@
+package logic
+
+import (
+	"errors"
+	"land/dao/mysql"
+	"land/dao/redis"
+	"land/models"
+	"land/pkg/jwt"
+	"land/pkg/snowflake"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// AccessTokenPrefix 个人访问令牌前缀，认证中间件据此区分JWT与个人访问令牌，也便于密钥扫描工具识别
+	AccessTokenPrefix = "land_pat_"
+
+	maxAccessTokens = 50 // 每个用户最多持有的令牌数量
+)
+
+var (
+	// ErrorAccessTokenInvalid 个人访问令牌不存在、已吊销或已过期
+	ErrorAccessTokenInvalid = errors.New("无效的访问令牌")
+
+	// ErrorAccessTokenLimit 令牌数量达到上限
+	ErrorAccessTokenLimit = errors.New("访问令牌数量已达上限")
+
+	// ErrorScopeNotAllowed 当前用户无权申请该权限范围
+	ErrorScopeNotAllowed = errors.New("无权申请该权限范围")
+)
+
+// IsAccessToken 判断Bearer凭证是否为个人访问令牌
+// 参数:
+//   - token: Authorization头中的凭证
+//
+// 返回值:
+//   - bool: 带有个人访问令牌前缀时返回true
+func IsAccessToken(token string) bool {
+	return strings.HasPrefix(token, AccessTokenPrefix)
+}
+
+// CreateAccessToken 创建个人访问令牌，明文只在创建时返回一次
+// 参数:
+//   - userID: 用户ID
+//   - p: 名称、权限范围与有效天数
+//
+// 返回值:
+//   - *models.AccessToken: 令牌信息
+//   - string: 令牌明文
+//   - error: 可能的错误
+func CreateAccessToken(userID uint64, p *models.ParamCreateAccessToken) (*models.AccessToken, string, error) {
+	count, err := mysql.CountUserAccessTokens(userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if count >= maxAccessTokens {
+		return nil, "", ErrorAccessTokenLimit
+	}
+
+	// 运维权限只能由管理员申请
+	scopes := normalizeScopes(p.Scopes)
+	if hasAnyRole(scopes, models.ScopeAdminSync) {
+		roles, err := GetUserRoles(userID)
+		if err != nil {
+			return nil, "", err
+		}
+		if !hasAnyRole(roles, models.RoleAdmin) {
+			return nil, "", ErrorScopeNotAllowed
+		}
+	}
+
+	secret, err := jwt.GenOpaqueToken()
+	if err != nil {
+		return nil, "", err
+	}
+	raw := AccessTokenPrefix + secret
+
+	token := &models.AccessToken{
+		ID:         snowflake.GetID(),
+		UserID:     userID,
+		Name:       p.Name,
+		TokenHash:  jwt.HashToken(raw),
+		Scopes:     strings.Join(scopes, " "),
+		CreateTime: time.Now(),
+	}
+	if p.ExpireDays > 0 {
+		expire := token.CreateTime.AddDate(0, 0, p.ExpireDays)
+		token.ExpireTime = &expire
+	}
+
+	if err = mysql.InsertAccessToken(token); err != nil {
+		return nil, "", err
+	}
+	zap.L().Info("Access token created", zap.Int64("user_id", int64(userID)), zap.Int64("token_id", int64(token.ID)), zap.String("scopes", token.Scopes))
+	return token, raw, nil
+}
+
+// GetAccessTokenList 获取用户的个人访问令牌列表
+// 参数:
+//   - userID: 用户ID
+//
+// 返回值:
+//   - []*models.AccessToken: 令牌列表，不含明文与哈希
+//   - error: 可能的错误
+func GetAccessTokenList(userID uint64) ([]*models.AccessToken, error) {
+	return mysql.GetUserAccessTokens(userID)
+}
+
+// RevokeAccessToken 吊销个人访问令牌，缓存同步删除，立即生效
+// 参数:
+//   - userID: 用户ID
+//   - tokenID: 令牌ID
+//
+// 返回值:
+//   - error: 令牌不存在或不属于该用户时返回mysql.ErrorAccessTokenNotExist
+func RevokeAccessToken(userID, tokenID uint64) error {
+	token, err := mysql.GetAccessToken(userID, tokenID)
+	if err != nil {
+		return err
+	}
+	if err = mysql.DeleteAccessToken(userID, tokenID); err != nil {
+		return err
+	}
+	return redis.DeleteAccessTokenCache(token.TokenHash)
+}
+
+// AuthenticateAccessToken 校验个人访问令牌并记录最近使用情况
+// 参数:
+//   - raw: 令牌明文
+//   - ip: 客户端IP
+//
+// 返回值:
+//   - *models.AccessTokenAuth: 令牌对应的用户、权限范围与角色
+//   - error: 令牌无效时返回ErrorAccessTokenInvalid
+func AuthenticateAccessToken(raw, ip string) (*models.AccessTokenAuth, error) {
+	tokenHash := jwt.HashToken(raw)
+
+	auth, expire, hit, err := redis.GetAccessTokenCache(tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	if !hit {
+		auth, expire, err = loadAccessToken(tokenHash)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if auth == nil || auth.TokenID == 0 {
+		return nil, ErrorAccessTokenInvalid
+	}
+	if expire != nil && time.Now().After(*expire) {
+		return nil, ErrorAccessTokenInvalid
+	}
+
+	// last_used_time按固定间隔写回，避免每个请求都更新数据库
+	if ok, err := redis.AcquireAccessTokenTouch(auth.TokenID); err == nil && ok {
+		if err = mysql.TouchAccessToken(auth.TokenID, ip); err != nil {
+			zap.L().Error("mysql.TouchAccessToken() failed", zap.Int64("token_id", int64(auth.TokenID)), zap.Error(err))
+		}
+	}
+	return auth, nil
+}
+
+// loadAccessToken 从数据库加载令牌并写入缓存，令牌不存在时缓存无效标记
+func loadAccessToken(tokenHash string) (*models.AccessTokenAuth, *time.Time, error) {
+	token, err := mysql.GetAccessTokenByHash(tokenHash)
+	if errors.Is(err, mysql.ErrorAccessTokenNotExist) {
+		if err = redis.SetAccessTokenCache(tokenHash, nil, nil); err != nil {
+			zap.L().Error("redis.SetAccessTokenCache() failed", zap.Error(err))
+		}
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	roles, err := GetUserRoles(token.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	auth := &models.AccessTokenAuth{
+		TokenID: token.ID,
+		UserID:  token.UserID,
+		Scopes:  strings.Fields(token.Scopes),
+		Roles:   roles,
+	}
+	if err = redis.SetAccessTokenCache(tokenHash, auth, token.ExpireTime); err != nil {
+		zap.L().Error("redis.SetAccessTokenCache() failed", zap.Error(err))
+	}
+	return auth, token.ExpireTime, nil
+}
+
+// normalizeScopes 去重并按固定顺序排列权限范围
+func normalizeScopes(scopes []string) []string {
+	want := make(map[string]bool, len(scopes))
+	for _, s := range scopes {
+		want[s] = true
+	}
+	result := make([]string, 0, len(want))
+	for _, s := range models.AccessTokenScopes {
+		if want[s] {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// hasAnyRole 判断列表中是否包含任一指定值，用于角色与权限范围
+func hasAnyRole(have []string, want ...string) bool {
+	for _, h := range have {
+		for _, w := range want {
+			if h == w {
+				return true
+			}
+		}
+	}
+	return false
+}
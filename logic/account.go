@@ -0,0 +1,294 @@
+package logic
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"land/dao/mysql"
+	"land/dao/redis"
+	"land/models"
+	"land/pkg/password"
+	"land/settings"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultDeleteGrace = 30 * 24 * time.Hour // 未配置时的宽限期
+	purgeBatchSize     = 100                 // 每轮最多清除的用户数
+)
+
+// deleteGrace 注销后保留个人信息的宽限期
+func deleteGrace() time.Duration {
+	if settings.Conf.AuthConfig == nil || settings.Conf.AuthConfig.DeleteGrace <= 0 {
+		return defaultDeleteGrace
+	}
+	return time.Duration(settings.Conf.AuthConfig.DeleteGrace) * time.Second
+}
+
+// ExportUserData 导出用户的个人数据
+// 参数:
+//   - userID: 用户ID
+//
+// 返回值:
+//   - *models.UserDataExport: 资料、第三方身份、帖子、评论与投票记录
+//   - error: 用户不存在时返回mysql.ErrorUserNotExist
+func ExportUserData(userID uint64) (*models.UserDataExport, error) {
+	profile, err := GetUserProfile(userID, true)
+	if err != nil {
+		return nil, err
+	}
+	identities, err := mysql.GetUserIdentities(userID)
+	if err != nil {
+		return nil, err
+	}
+	posts, err := mysql.GetAllPostsByAuthor(userID)
+	if err != nil {
+		return nil, err
+	}
+	fillPostTags(posts...)
+	comments, err := mysql.GetAllCommentsByAuthor(userID)
+	if err != nil {
+		return nil, err
+	}
+	// 投票只保存在Redis中
+	votes, err := redis.GetUserVotes(strconv.FormatUint(userID, 10))
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.UserDataExport{
+		ExportTime: time.Now(),
+		Profile:    profile,
+		Identities: identities,
+		Posts:      posts,
+		Comments:   comments,
+		Votes:      votes,
+	}, nil
+}
+
+// BuildExportArchive 将导出数据打包为ZIP，每类数据一个JSON文件
+// 参数:
+//   - data: 导出数据
+//
+// 返回值:
+//   - []byte: ZIP文件内容
+//   - error: 可能的错误
+func BuildExportArchive(data *models.UserDataExport) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+
+	files := []struct {
+		name string
+		v    interface{}
+	}{
+		{"profile.json", data.Profile},
+		{"identities.json", data.Identities},
+		{"posts.json", data.Posts},
+		{"comments.json", data.Comments},
+		{"votes.json", data.Votes},
+	}
+	for _, f := range files {
+		w, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     f.name,
+			Method:   zip.Deflate,
+			Modified: data.ExportTime,
+		})
+		if err != nil {
+			return nil, err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err = enc.Encode(f.v); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DeleteAccount 注销账号
+// 帖子与评论立即匿名化，投票与访问记录、会话和访问令牌立即清除，
+// 用户行保留到宽限期满后由AccountPurgeService彻底删除
+// 参数:
+//   - userID: 用户ID
+//   - p: 注销参数，设置过密码的账号需提供密码
+//
+// 返回值:
+//   - *models.AccountDeletion: 个人信息彻底删除的时间
+//   - error: 密码错误时返回mysql.ErrorInvalidPassword
+func DeleteAccount(userID uint64, p *models.ParamDeleteAccount) (*models.AccountDeletion, error) {
+	user, err := getProfileUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Password != "" {
+		ok, _, err := password.Verify(p.Password, user.Password, settings.Conf.Secret)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, mysql.ErrorInvalidPassword
+		}
+	}
+
+	// 删除前记下需要同步清理的缓存
+	posts, err := mysql.GetAllPostsByAuthor(userID)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := mysql.GetUserAccessTokens(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err = mysql.MarkUserDeleted(userID, now); err != nil {
+		return nil, err
+	}
+	zap.L().Info("Account deleted", zap.Int64("user_id", int64(userID)))
+
+	// 以下为Redis清理，失败只记录日志，数据库状态已生效
+	if err := redis.DeleteAllSessions(userID); err != nil {
+		zap.L().Error("redis.DeleteAllSessions() failed", zap.Int64("user_id", int64(userID)), zap.Error(err))
+	}
+	for _, t := range tokens {
+		if err := redis.DeleteAccessTokenCache(t.TokenHash); err != nil {
+			zap.L().Error("redis.DeleteAccessTokenCache() failed", zap.Int64("token_id", int64(t.ID)), zap.Error(err))
+		}
+	}
+	uid := strconv.FormatUint(userID, 10)
+	if err := deleteUserVotes(uid); err != nil {
+		zap.L().Error("deleteUserVotes() failed", zap.Int64("user_id", int64(userID)), zap.Error(err))
+	}
+	if err := redis.DeleteUserViewRecords(uid); err != nil {
+		zap.L().Error("redis.DeleteUserViewRecords() failed", zap.Int64("user_id", int64(userID)), zap.Error(err))
+	}
+	if err := redis.InvalidateFollowing(userID); err != nil {
+		zap.L().Error("redis.InvalidateFollowing() failed", zap.Int64("user_id", int64(userID)), zap.Error(err))
+	}
+	if err := redis.SetPopularAuthor(userID, false); err != nil {
+		zap.L().Error("redis.SetPopularAuthor() failed", zap.Int64("user_id", int64(userID)), zap.Error(err))
+	}
+	for _, kind := range []uint8{models.RelationBlock, models.RelationMute} {
+		if err := redis.DeleteBlockSet(userID, kind); err != nil {
+			zap.L().Error("redis.DeleteBlockSet() failed", zap.Int64("user_id", int64(userID)), zap.Error(err))
+		}
+	}
+	for _, post := range posts {
+		if err := redis.DeletePostCache(userID, post.PostID); err != nil {
+			zap.L().Error("redis.DeletePostCache() failed", zap.Int64("post_id", int64(post.PostID)), zap.Error(err))
+		}
+		// 标题和内容已匿名化，不再参与搜索
+		removePostIndex(post.PostID)
+	}
+
+	return &models.AccountDeletion{PurgeTime: now.Add(deleteGrace())}, nil
+}
+
+// deleteUserVotes 删除用户的投票，并从全站和各标签的分数中撤回，再重算这些帖子的排序分数
+func deleteUserVotes(uid string) error {
+	votes, err := redis.GetUserVotes(uid)
+	if err != nil || len(votes) == 0 {
+		return err
+	}
+	postTags, err := getVotedPostTags(votes)
+	if err != nil {
+		return err
+	}
+	if err = redis.DeleteUserVotes(uid, votes, postTags); err != nil {
+		return err
+	}
+
+	ids := make([]string, 0, len(votes))
+	for _, v := range votes {
+		ids = append(ids, v.PostID)
+	}
+	// 只有已发布的帖子在排序中，已删除的帖子恢复时会按投票记录重新计算
+	posts, err := mysql.GetPostListByIDs(ids)
+	if err != nil {
+		return err
+	}
+	for _, post := range posts {
+		refreshPostRanks(post)
+	}
+	return nil
+}
+
+// PurgeDeletedAccounts 彻底删除宽限期已满的注销用户
+// 返回值:
+//   - int: 本次删除的用户数
+//   - error: 可能的错误
+func PurgeDeletedAccounts() (int, error) {
+	ids, err := mysql.GetUsersToPurge(time.Now().Add(-deleteGrace()), purgeBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	purged := 0
+	for _, id := range ids {
+		if err := mysql.PurgeUser(id); err != nil {
+			zap.L().Error("mysql.PurgeUser() failed", zap.Int64("user_id", int64(id)), zap.Error(err))
+			continue
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// AccountPurgeService 注销用户清除服务，定期删除宽限期已满的用户个人信息
+type AccountPurgeService struct {
+	checkInterval time.Duration // 检查间隔
+	stopChan      chan bool     // 停止信号
+}
+
+// NewAccountPurgeService 创建注销用户清除服务
+// 参数:
+//   - checkInterval: 检查间隔
+//
+// 返回值:
+//   - *AccountPurgeService: 清除服务实例
+func NewAccountPurgeService(checkInterval time.Duration) *AccountPurgeService {
+	return &AccountPurgeService{
+		checkInterval: checkInterval,
+		stopChan:      make(chan bool),
+	}
+}
+
+// Start 启动清除服务
+func (s *AccountPurgeService) Start() {
+	go s.purgeLoop()
+	zap.L().Info("AccountPurgeService started",
+		zap.Duration("check_interval", s.checkInterval))
+}
+
+// Stop 停止清除服务
+func (s *AccountPurgeService) Stop() {
+	close(s.stopChan)
+	zap.L().Info("AccountPurgeService stopped")
+}
+
+// purgeLoop 清除循环
+func (s *AccountPurgeService) purgeLoop() {
+	ticker := time.NewTicker(s.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			n, err := PurgeDeletedAccounts()
+			if err != nil {
+				zap.L().Error("PurgeDeletedAccounts() failed", zap.Error(err))
+				continue
+			}
+			if n > 0 {
+				zap.L().Info("Deleted accounts purged", zap.Int("count", n))
+			}
+		case <-s.stopChan:
+			return
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package logic
+
+import (
+	"errors"
+	"land/dao/mysql"
+	"land/dao/redis"
+	"land/models"
+	"land/pkg/jwt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultDeviceName = "未命名设备"
+)
+
+var (
+	// ErrorRefreshTokenInvalid refresh token不存在、已过期或会话已吊销
+	ErrorRefreshTokenInvalid = errors.New("无效的refresh token")
+
+	// ErrorRefreshTokenReused refresh token被重复使用，所属会话已吊销
+	ErrorRefreshTokenReused = errors.New("refresh token被重复使用")
+)
+
+// IssueTokenPair 登录成功后创建新会话并签发令牌
+// 参数:
+//   - user: 登录用户
+//   - device: 设备名，为空时使用默认名称
+//   - ip: 客户端IP
+//   - userAgent: 客户端User-Agent
+//
+// 返回值:
+//   - *models.TokenPair: access token与refresh token
+//   - error: 可能的错误
+func IssueTokenPair(user *models.User, device, ip, userAgent string) (*models.TokenPair, error) {
+	sessionID, err := jwt.GenOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	if device == "" {
+		device = defaultDeviceName
+	}
+
+	now := time.Now()
+	session := &models.Session{
+		SessionID:  sessionID,
+		UserID:     user.UserID,
+		DeviceName: device,
+		IP:         ip,
+		UserAgent:  userAgent,
+		CreateTime: now,
+		LastSeen:   now,
+	}
+	if err = redis.CreateSession(session, jwt.RefreshExpire()); err != nil {
+		return nil, err
+	}
+
+	return issueTokens(user, sessionID)
+}
+
+// RefreshTokenPair 使用refresh token换取新的令牌对（轮换）
+// 参数:
+//   - refreshToken: 客户端提交的refresh token
+//
+// 返回值:
+//   - *models.TokenPair: 新的access token与refresh token
+//   - error: ErrorRefreshTokenInvalid或ErrorRefreshTokenReused
+func RefreshTokenPair(refreshToken string) (*models.TokenPair, error) {
+	info, err := redis.ConsumeRefreshToken(jwt.HashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, redis.ErrRefreshTokenNotFound) {
+			return nil, ErrorRefreshTokenInvalid
+		}
+		return nil, err
+	}
+
+	// 已使用过的refresh token再次出现，说明可能被盗用，吊销整个会话
+	if info.Reused {
+		zap.L().Warn("Refresh token reuse detected, revoking session",
+			zap.Int64("user_id", info.UserID),
+			zap.String("session_id", info.SessionID))
+		if err := redis.DeleteSession(uint64(info.UserID), info.SessionID); err != nil {
+			return nil, err
+		}
+		return nil, ErrorRefreshTokenReused
+	}
+
+	ok, err := redis.TouchSession(uint64(info.UserID), info.SessionID, "")
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrorRefreshTokenInvalid
+	}
+
+	user, err := mysql.GetUserById(uint64(info.UserID))
+	if err != nil {
+		return nil, err
+	}
+	return issueTokens(user, info.SessionID)
+}
+
+// GetSessionList 获取用户的会话列表，并标记当前会话
+// 参数:
+//   - userID: 用户ID
+//   - currentSessionID: 发起请求的会话ID
+//
+// 返回值:
+//   - []*models.Session: 会话列表
+//   - error: 可能的错误
+func GetSessionList(userID uint64, currentSessionID string) ([]*models.Session, error) {
+	sessions, err := redis.ListSessions(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range sessions {
+		s.Current = s.SessionID == currentSessionID
+	}
+	return sessions, nil
+}
+
+// RevokeSession 吊销用户的指定会话
+// 参数:
+//   - userID: 用户ID
+//   - sessionID: 待吊销的会话ID
+//
+// 返回值:
+//   - error: 会话不存在或不属于该用户时返回redis.ErrSessionNotFound
+func RevokeSession(userID uint64, sessionID string) error {
+	session, err := redis.GetSession(sessionID)
+	if err != nil {
+		return err
+	}
+	if session.UserID != userID {
+		return redis.ErrSessionNotFound
+	}
+	return redis.DeleteSession(userID, sessionID)
+}
+
+// Logout 注销当前会话
+// 参数:
+//   - userID: 用户ID
+//   - sessionID: 当前access token所属会话
+//
+// 返回值:
+//   - error: 可能的错误
+func Logout(userID uint64, sessionID string) error {
+	return redis.DeleteSession(userID, sessionID)
+}
+
+// LogoutAll 注销用户所有会话（所有设备）
+// 参数:
+//   - userID: 用户ID
+//
+// 返回值:
+//   - error: 可能的错误
+func LogoutAll(userID uint64) error {
+	return redis.DeleteAllSessions(userID)
+}
+
+// issueTokens 在指定会话下签发access token与refresh token
+func issueTokens(user *models.User, sessionID string) (*models.TokenPair, error) {
+	roles, err := GetUserRoles(user.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := jwt.GenToken(int64(user.UserID), user.Username, sessionID, roles)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := jwt.GenOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	err = redis.SaveRefreshToken(jwt.HashToken(refreshToken), int64(user.UserID), sessionID, jwt.RefreshExpire())
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(jwt.AccessExpire().Seconds()),
+	}, nil
+}
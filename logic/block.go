@@ -0,0 +1,208 @@
+package logic
+
+import (
+	"errors"
+	"land/dao/mysql"
+	"land/dao/redis"
+	"land/models"
+	"regexp"
+
+	"go.uber.org/zap"
+)
+
+const maxMentions = 20 // 单条内容最多@的用户数
+
+var (
+	ErrorBlockSelf = errors.New("不能拉黑或屏蔽自己")
+	ErrorBlocked   = errors.New("你已被对方拉黑")
+
+	ErrorTooManyMentions = errors.New("最多@20个用户")
+
+	mentionRegexp = regexp.MustCompile(`@([` + models.UsernameCharset + `]+)`)
+)
+
+// BlockUser 拉黑或屏蔽用户，重复操作视为成功
+// 拉黑会同时解除双方的关注关系
+// 参数:
+//   - userID: 操作者ID
+//   - targetID: 对象ID
+//   - kind: models.RelationBlock或models.RelationMute
+//
+// 返回值:
+//   - error: 对象为自己时返回ErrorBlockSelf，用户不存在返回mysql.ErrorUserNotExist
+func BlockUser(userID, targetID uint64, kind uint8) error {
+	if userID == targetID {
+		return ErrorBlockSelf
+	}
+	if _, err := getProfileUser(targetID); err != nil {
+		return err
+	}
+	if err := mysql.InsertUserBlock(userID, targetID, kind); err != nil {
+		return err
+	}
+	if err := redis.DeleteBlockSet(userID, kind); err != nil {
+		return err
+	}
+
+	if kind == models.RelationBlock {
+		if err := UnfollowUser(userID, targetID); err != nil {
+			return err
+		}
+		if err := UnfollowUser(targetID, userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UnblockUser 解除拉黑或屏蔽
+// 参数:
+//   - userID: 操作者ID
+//   - targetID: 对象ID
+//   - kind: 关系类型
+//
+// 返回值:
+//   - error: 可能的错误
+func UnblockUser(userID, targetID uint64, kind uint8) error {
+	if err := mysql.DeleteUserBlock(userID, targetID, kind); err != nil {
+		return err
+	}
+	return redis.DeleteBlockSet(userID, kind)
+}
+
+// GetBlockList 分页获取拉黑或屏蔽列表
+// 参数:
+//   - userID: 操作者ID
+//   - kind: 关系类型
+//   - page: 页码
+//   - size: 每页数量
+//
+// 返回值:
+//   - *models.UserListRes: 用户列表及分页信息
+//   - error: 可能的错误
+func GetBlockList(userID uint64, kind uint8, page, size int64) (*models.UserListRes, error) {
+	total, err := mysql.CountUserBlocks(userID, kind)
+	if err != nil {
+		return nil, err
+	}
+	users, err := mysql.GetUserBlockList(userID, kind, page, size)
+	if err != nil {
+		return nil, err
+	}
+	return &models.UserListRes{
+		Page: models.Page{Total: total, Page: page, Size: size},
+		List: users,
+	}, nil
+}
+
+// getBlockSet 获取用户拉黑/屏蔽的人，缓存不存在时从数据库加载
+func getBlockSet(userID uint64, kind uint8) (map[uint64]bool, error) {
+	set, ok, err := redis.GetBlockSet(userID, kind)
+	if err != nil {
+		zap.L().Error("redis.GetBlockSet() failed", zap.Error(err))
+	}
+	if ok {
+		return set, nil
+	}
+
+	ids, err := mysql.GetUserBlockTargets(userID, kind)
+	if err != nil {
+		return nil, err
+	}
+	if err = redis.SetBlockSet(userID, kind, ids); err != nil {
+		zap.L().Error("redis.SetBlockSet() failed", zap.Error(err))
+	}
+	set = make(map[uint64]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set, nil
+}
+
+// getHiddenAuthors 获取对当前用户隐藏的作者：屏蔽和拉黑的人
+// 获取失败时不过滤，只记录日志
+// 参数:
+//   - viewerID: 当前用户ID，游客为0
+//
+// 返回值:
+//   - map[uint64]bool: 需要隐藏的作者ID，无需过滤时为nil
+func getHiddenAuthors(viewerID uint64) map[uint64]bool {
+	if viewerID == 0 {
+		return nil
+	}
+	hidden, err := getBlockSet(viewerID, models.RelationMute)
+	if err != nil {
+		zap.L().Error("getBlockSet() failed", zap.Int64("user_id", int64(viewerID)), zap.Error(err))
+		return nil
+	}
+	blocked, err := getBlockSet(viewerID, models.RelationBlock)
+	if err != nil {
+		zap.L().Error("getBlockSet() failed", zap.Int64("user_id", int64(viewerID)), zap.Error(err))
+		return hidden
+	}
+	for id := range blocked {
+		hidden[id] = true
+	}
+	if len(hidden) == 0 {
+		return nil
+	}
+	return hidden
+}
+
+// checkBlocked 检查actor是否被任一owner拉黑
+// 参数:
+//   - actorID: 发起互动的用户
+//   - ownerIDs: 被互动的内容作者
+//
+// 返回值:
+//   - error: 被拉黑时返回ErrorBlocked
+func checkBlocked(actorID uint64, ownerIDs ...uint64) error {
+	for _, ownerID := range ownerIDs {
+		if ownerID == 0 || ownerID == actorID {
+			continue
+		}
+		blocked, err := getBlockSet(ownerID, models.RelationBlock)
+		if err != nil {
+			return err
+		}
+		if blocked[actorID] {
+			return ErrorBlocked
+		}
+	}
+	return nil
+}
+
+// checkMentions 检查内容中@到的用户是否拉黑了作者，@的用户过多时直接拒绝，保证每个@都经过检查
+// 参数:
+//   - authorID: 内容作者
+//   - texts: 需要检查的文本
+//
+// 返回值:
+//   - error: @了拉黑自己的用户时返回ErrorBlocked，超过maxMentions个用户时返回ErrorTooManyMentions
+func checkMentions(authorID uint64, texts ...string) error {
+	seen := make(map[string]bool)
+	for _, text := range texts {
+		for _, m := range mentionRegexp.FindAllStringSubmatch(text, -1) {
+			name := m[1]
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			if len(seen) > maxMentions {
+				return ErrorTooManyMentions
+			}
+
+			user, err := mysql.GetUserByUsername(name)
+			if errors.Is(err, mysql.ErrorUserNotExist) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if err = checkBlocked(authorID, user.UserID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package logic
+
+import (
+	"land/dao/mysql"
+	"land/models"
+	"land/pkg/snowflake"
+)
+
+// CreateComment 发表评论或回复
+// 被帖子作者或被回复评论的作者拉黑时不能发表，也不能@拉黑了自己的用户
+// 参数:
+//   - comment: 评论内容，需已设置AuthorID
+//
+// 返回值:
+//   - error: 帖子或父评论不存在时返回mysql.ErrorInvalidID，被拉黑时返回ErrorBlocked
+func CreateComment(comment *models.Comment) error {
+	post, err := mysql.GetPostByID(comment.PostID)
+	if err != nil {
+		return err
+	}
+	owners := []uint64{post.AuthorID}
+
+	if comment.ParentID != 0 {
+		parent, err := mysql.GetCommentByID(comment.ParentID)
+		if err != nil {
+			return err
+		}
+		if parent.PostID != comment.PostID {
+			return mysql.ErrorInvalidID
+		}
+		owners = append(owners, parent.AuthorID)
+	}
+
+	if err = checkBlocked(comment.AuthorID, owners...); err != nil {
+		return err
+	}
+	if err = checkMentions(comment.AuthorID, comment.Content); err != nil {
+		return err
+	}
+
+	comment.CommentID = snowflake.GetID()
+	comment.ContentHTML = renderContent(comment.Content)
+	return mysql.CreateComment(comment)
+}
+
+// GetCommentListByIDs 批量获取评论，过滤当前用户屏蔽和拉黑的作者
+// 参数:
+//   - ids: 评论ID列表
+//   - viewerID: 当前用户ID，游客为0
+//
+// 返回值:
+//   - []*models.Comment: 评论列表
+//   - error: 可能的错误
+func GetCommentListByIDs(ids []string, viewerID uint64) ([]*models.Comment, error) {
+	comments, err := mysql.GetCommentListByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	ensureCommentHTML(comments...)
+	hidden := getHiddenAuthors(viewerID)
+	if hidden == nil {
+		return comments, nil
+	}
+
+	visible := make([]*models.Comment, 0, len(comments))
+	for _, c := range comments {
+		if !hidden[c.AuthorID] {
+			visible = append(visible, c)
+		}
+	}
+	return visible, nil
+}
@@ -0,0 +1,73 @@
+package logic
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"land/models"
+)
+
+var ErrorInvalidCursor = errors.New("无效的分页游标")
+
+// cursorToken 游标的编码内容，记录排序方式以免换了排序后继续使用
+type cursorToken struct {
+	Order  string  `json:"o"`
+	Sort   float64 `json:"s"`
+	PostID uint64  `json:"i"`
+}
+
+// encodeCursor 把游标位置编码为不透明的字符串
+// 参数:
+//   - order: 排序方式
+//   - c: 游标位置，为nil时表示没有下一页
+//
+// 返回值:
+//   - string: base64url编码的游标，没有下一页时为空
+func encodeCursor(order string, c *models.PostCursor) string {
+	if c == nil {
+		return ""
+	}
+	b, err := json.Marshal(cursorToken{Order: order, Sort: c.Sort, PostID: c.PostID})
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// parseCursor 解析列表参数中的cursor，排序方式需先确定
+// 参数:
+//   - p: 列表参数，解析结果写入After
+//
+// 返回值:
+//   - error: 游标无效时返回ErrorInvalidCursor
+func parseCursor(p *models.ParamPostList) error {
+	if p.Cursor == "" {
+		return nil
+	}
+	after, err := decodeCursor(p.Cursor, p.Order)
+	if err != nil {
+		return err
+	}
+	p.After = after
+	return nil
+}
+
+// decodeCursor 解析客户端传回的游标
+// 参数:
+//   - s: encodeCursor生成的游标
+//   - order: 本次请求的排序方式，需与生成游标时一致
+//
+// 返回值:
+//   - *models.PostCursor: 游标位置
+//   - error: 格式错误或排序方式不一致时返回ErrorInvalidCursor
+func decodeCursor(s, order string) (*models.PostCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrorInvalidCursor
+	}
+	var t cursorToken
+	if err = json.Unmarshal(b, &t); err != nil || t.Order != order || t.PostID == 0 {
+		return nil, ErrorInvalidCursor
+	}
+	return &models.PostCursor{Sort: t.Sort, PostID: t.PostID}, nil
+}
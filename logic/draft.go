@@ -0,0 +1,254 @@
+package logic
+
+import (
+	"errors"
+	"land/dao/mysql"
+	"land/dao/redis"
+	"land/models"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const publishBatchSize = 100 // 每轮最多发布的定时帖子数
+
+var (
+	ErrorInvalidPublishTime = errors.New("定时发布时间必须晚于当前时间")
+)
+
+// GetDraftList 分页获取当前用户的草稿与定时帖子
+// 参数:
+//   - authorID: 作者ID
+//   - page: 页码
+//   - size: 每页数量
+//
+// 返回值:
+//   - *models.DraftListRes: 草稿列表及分页信息
+//   - error: 可能的错误
+func GetDraftList(authorID uint64, page, size int64) (*models.DraftListRes, error) {
+	total, err := mysql.CountDraftsByAuthor(authorID)
+	if err != nil {
+		return nil, err
+	}
+	posts, err := mysql.GetDraftsByAuthor(authorID, page, size)
+	if err != nil {
+		return nil, err
+	}
+	ensurePostHTML(posts...)
+	fillPostTags(posts...)
+	return &models.DraftListRes{
+		Page: models.Page{Total: total, Page: page, Size: size},
+		List: posts,
+	}, nil
+}
+
+// UpdateDraft 修改草稿或定时帖子，指定发布时间时转为定时发布，否则转为草稿
+// 参数:
+//   - authorID: 作者ID
+//   - postID: 帖子ID
+//   - p: 修改内容
+//
+// 返回值:
+//   - error: 帖子不存在或已发布返回mysql.ErrorInvalidID，发布时间不晚于当前时间返回ErrorInvalidPublishTime，
+//     标签不合法时返回ErrorInvalidTag或ErrorTooManyTags
+func UpdateDraft(authorID, postID uint64, p *models.ParamUpdateDraft) error {
+	if err := checkMentions(authorID, p.Title, p.Content); err != nil {
+		return err
+	}
+	tags, err := normalizeTags(p.Tags)
+	if err != nil {
+		return err
+	}
+	post := &models.Post{
+		PostID:      postID,
+		AuthorID:    authorID,
+		Title:       p.Title,
+		Content:     p.Content,
+		ContentHTML: renderContent(p.Content),
+		CommunityID: p.CommunityID,
+		Status:      models.PostStatusDraft,
+	}
+	if p.PublishTime != nil {
+		if !p.PublishTime.After(time.Now()) {
+			return ErrorInvalidPublishTime
+		}
+		post.Status = models.PostStatusScheduled
+		post.PublishTime = p.PublishTime
+	}
+	if err = mysql.UpdateDraft(post); err != nil || tags == nil {
+		return err
+	}
+	// 草稿不在Redis排序中，只修改数据库
+	_, _, err = mysql.ReplacePostTags(postID, tags)
+	return err
+}
+
+// DeleteDraft 删除草稿或定时帖子
+// 参数:
+//   - authorID: 作者ID
+//   - postID: 帖子ID
+//
+// 返回值:
+//   - error: 帖子不存在或已发布返回mysql.ErrorInvalidID
+func DeleteDraft(authorID, postID uint64) error {
+	return mysql.DeleteDraft(postID, authorID)
+}
+
+// PublishDraft 立即发布草稿或定时帖子
+// 参数:
+//   - authorID: 作者ID
+//   - postID: 帖子ID
+//
+// 返回值:
+//   - error: 帖子不存在、不属于该作者或已发布返回mysql.ErrorInvalidID
+func PublishDraft(authorID, postID uint64) error {
+	post, err := mysql.GetPostByIDAnyStatus(postID)
+	if err != nil {
+		return err
+	}
+	if post.AuthorID != authorID ||
+		(post.Status != models.PostStatusDraft && post.Status != models.PostStatusScheduled) {
+		return mysql.ErrorInvalidID
+	}
+	if err = checkMentions(authorID, post.Title, post.Content); err != nil {
+		return err
+	}
+	published, err := publishUnpublished(post)
+	if err != nil {
+		return err
+	}
+	if !published {
+		// 同时被定时任务发布了
+		return mysql.ErrorInvalidID
+	}
+	return nil
+}
+
+// publishUnpublished 发布草稿或定时帖子：先写入Redis排序、社区集合、标签排序与搜索索引，再把MySQL中的状态改为已发布
+// Redis的写入可以重复执行，失败时帖子仍未发布，定时帖子由发布服务下一轮重试；
+// 写入Redis后、状态修改前，列表从MySQL读取时会过滤掉未发布的帖子
+func publishUnpublished(post *models.Post) (bool, error) {
+	tags, err := mysql.GetPostTags(post.PostID)
+	if err != nil {
+		return false, err
+	}
+	now := time.Now()
+	post.Status = models.PostStatusPublished
+	post.CreateTime = now
+	post.UpdateTime = now
+	post.PublishTime = &now
+	post.Tags = tags
+
+	if err = redis.CreatePost(post.PostID, post.AuthorID, post.CommunityID, now.Unix(), tags, postRanks(post, 0, 0)); err != nil {
+		return false, err
+	}
+	if err = redis.IndexPost(post.PostID, searchWeights(post)); err != nil {
+		return false, err
+	}
+	published, err := mysql.PublishPost(post.PostID, now)
+	if err != nil {
+		return false, err
+	}
+	if !published {
+		discardUnpublished(post)
+		return false, nil
+	}
+	pushPublishedPost(post)
+	zap.L().Info("Post published",
+		zap.Int64("post_id", int64(post.PostID)),
+		zap.Int64("author_id", int64(post.AuthorID)))
+	return true, nil
+}
+
+// discardUnpublished 帖子没有由本次调用发布时调用：已被其他实例发布的保持不变，
+// 期间被作者删除或改回草稿的，把刚写入Redis的内容移除
+func discardUnpublished(post *models.Post) {
+	_, err := mysql.GetPostByID(post.PostID)
+	if err == nil {
+		return
+	}
+	if !errors.Is(err, mysql.ErrorInvalidID) {
+		zap.L().Error("mysql.GetPostByID() failed", zap.Int64("post_id", int64(post.PostID)), zap.Error(err))
+		return
+	}
+	if err = redis.RemovePost(post); err != nil {
+		zap.L().Error("redis.RemovePost() failed", zap.Int64("post_id", int64(post.PostID)), zap.Error(err))
+	}
+	removePostIndex(post.PostID)
+}
+
+// PublishDuePosts 发布发布时间已到的定时帖子
+// 定时信息保存在MySQL中，服务重启后会补发停机期间到期的帖子
+// 返回值:
+//   - int: 本次发布的帖子数
+//   - error: 可能的错误
+func PublishDuePosts() (int, error) {
+	posts, err := mysql.GetDuePosts(time.Now(), publishBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, post := range posts {
+		published, err := publishUnpublished(post)
+		if err != nil {
+			zap.L().Error("publishUnpublished() failed", zap.Int64("post_id", int64(post.PostID)), zap.Error(err))
+		}
+		if published {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// PostPublishService 定时发布服务，定期发布到期的定时帖子
+type PostPublishService struct {
+	checkInterval time.Duration // 检查间隔
+	stopChan      chan bool     // 停止信号
+}
+
+// NewPostPublishService 创建定时发布服务
+// 参数:
+//   - checkInterval: 检查间隔
+//
+// 返回值:
+//   - *PostPublishService: 发布服务实例
+func NewPostPublishService(checkInterval time.Duration) *PostPublishService {
+	return &PostPublishService{
+		checkInterval: checkInterval,
+		stopChan:      make(chan bool),
+	}
+}
+
+// Start 启动发布服务，启动时先补发停机期间到期的帖子
+func (s *PostPublishService) Start() {
+	go s.publishLoop()
+	zap.L().Info("PostPublishService started",
+		zap.Duration("check_interval", s.checkInterval))
+}
+
+// Stop 停止发布服务
+func (s *PostPublishService) Stop() {
+	close(s.stopChan)
+	zap.L().Info("PostPublishService stopped")
+}
+
+// publishLoop 发布循环
+func (s *PostPublishService) publishLoop() {
+	ticker := time.NewTicker(s.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := PublishDuePosts()
+		if err != nil {
+			zap.L().Error("PublishDuePosts() failed", zap.Error(err))
+		} else if n > 0 {
+			zap.L().Info("Scheduled posts published", zap.Int("count", n))
+		}
+
+		select {
+		case <-ticker.C:
+		case <-s.stopChan:
+			return
+		}
+	}
+}
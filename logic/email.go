@@ -0,0 +1,131 @@
+package logic
+
+import (
+	"errors"
+	"fmt"
+	"land/dao/mysql"
+	"land/dao/redis"
+	"land/models"
+	"land/pkg/jwt"
+	"land/pkg/mail"
+	"land/settings"
+	"net/url"
+
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrorVerifyTokenInvalid 验证链接无效、已过期或邮箱已变更
+	ErrorVerifyTokenInvalid = errors.New("无效的验证链接")
+
+	// ErrorEmailAlreadyVerified 邮箱已验证
+	ErrorEmailAlreadyVerified = errors.New("邮箱已验证")
+
+	// ErrorEmailTaken 邮箱已被其他用户验证
+	ErrorEmailTaken = errors.New("邮箱已被其他用户验证")
+
+	// ErrorSendTooFrequent 验证邮件发送过于频繁
+	ErrorSendTooFrequent = errors.New("发送过于频繁，请稍后再试")
+)
+
+// SendVerificationEmail 向用户邮箱发送签名的验证链接
+// 参数:
+//   - user: 用户信息
+//
+// 返回值:
+//   - error: 可能的错误
+func SendVerificationEmail(user *models.User) error {
+	token, err := jwt.GenEmailToken(int64(user.UserID), user.Email, redis.EmailVerifyTTL)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/auth/verify?token=%s", settings.Conf.SiteURL, url.QueryEscape(token))
+	body := fmt.Sprintf("%s，你好：\n\n欢迎注册，请在%d小时内打开以下链接验证邮箱：\n\n%s\n\n如果不是你本人操作，请忽略本邮件。\n",
+		user.Username, int(redis.EmailVerifyTTL.Hours()), link)
+
+	return mail.Send(user.Email, "验证邮箱", body)
+}
+
+// ResendVerificationEmail 重新发送验证邮件
+// 参数:
+//   - userID: 用户ID
+//
+// 返回值:
+//   - error: 已验证返回ErrorEmailAlreadyVerified，冷却期内返回ErrorSendTooFrequent
+func ResendVerificationEmail(userID uint64) error {
+	user, err := mysql.GetUserById(userID)
+	if err != nil {
+		return err
+	}
+	if user.UserID == 0 {
+		return mysql.ErrorUserNotExist
+	}
+	if user.EmailVerified {
+		return ErrorEmailAlreadyVerified
+	}
+
+	ok, err := redis.AcquireEmailVerifyCooldown(userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrorSendTooFrequent
+	}
+
+	return SendVerificationEmail(user)
+}
+
+// VerifyEmail 校验验证链接并标记邮箱已验证
+// 参数:
+//   - token: 验证链接中的令牌
+//
+// 返回值:
+//   - error: 链接无效时返回ErrorVerifyTokenInvalid，邮箱已被其他用户验证时返回ErrorEmailTaken
+func VerifyEmail(token string) error {
+	claims, err := jwt.ParseEmailToken(token)
+	if err != nil {
+		zap.L().Info("jwt.ParseEmailToken() failed", zap.Error(err))
+		return ErrorVerifyTokenInvalid
+	}
+
+	updated, err := mysql.SetEmailVerified(uint64(claims.UserID), claims.Email)
+	if err != nil {
+		return err
+	}
+	if updated {
+		return nil
+	}
+
+	// 没有记录被更新：可能已经验证过、邮箱已变更，或邮箱已被其他用户验证
+	user, err := mysql.GetUserById(uint64(claims.UserID))
+	if err != nil {
+		return err
+	}
+	if user.Email != claims.Email {
+		return ErrorVerifyTokenInvalid
+	}
+	if user.EmailVerified {
+		return nil
+	}
+	return ErrorEmailTaken
+}
+
+// CheckEmailVerified 检查用户是否满足邮箱验证策略
+// 未开启auth.require_verified_email时始终返回true
+// 参数:
+//   - userID: 用户ID
+//
+// 返回值:
+//   - bool: 是否允许执行需要验证邮箱的操作
+//   - error: 可能的错误
+func CheckEmailVerified(userID uint64) (bool, error) {
+	if settings.Conf.AuthConfig == nil || !settings.Conf.RequireVerifiedEmail {
+		return true, nil
+	}
+	user, err := mysql.GetUserById(userID)
+	if err != nil {
+		return false, err
+	}
+	return user.EmailVerified, nil
+}
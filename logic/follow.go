@@ -0,0 +1,292 @@
+package logic
+
+import (
+	"errors"
+	"land/dao/mysql"
+	"land/dao/redis"
+	"land/models"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrorFollowSelf = errors.New("不能关注自己")
+)
+
+// FollowUser 关注用户，重复关注视为成功
+// 参数:
+//   - followerID: 关注者ID
+//   - followeeID: 被关注者ID
+//
+// 返回值:
+//   - error: 关注自己返回ErrorFollowSelf，用户不存在返回mysql.ErrorUserNotExist，被对方拉黑返回ErrorBlocked
+func FollowUser(followerID, followeeID uint64) error {
+	if followerID == followeeID {
+		return ErrorFollowSelf
+	}
+	if _, err := getProfileUser(followeeID); err != nil {
+		return err
+	}
+	if err := checkBlocked(followerID, followeeID); err != nil {
+		return err
+	}
+
+	added, err := mysql.InsertFollow(followerID, followeeID)
+	if err != nil || !added {
+		return err
+	}
+	onFollowChanged(followerID, followeeID)
+	return nil
+}
+
+// UnfollowUser 取消关注，未关注时视为成功
+// 参数:
+//   - followerID: 关注者ID
+//   - followeeID: 被关注者ID
+//
+// 返回值:
+//   - error: 可能的错误
+func UnfollowUser(followerID, followeeID uint64) error {
+	removed, err := mysql.DeleteFollow(followerID, followeeID)
+	if err != nil || !removed {
+		return err
+	}
+	onFollowChanged(followerID, followeeID)
+	return nil
+}
+
+// onFollowChanged 关注关系变化后清理缓存并更新被关注者的大V标记
+func onFollowChanged(followerID, followeeID uint64) {
+	if err := redis.DeleteFollowCounts(followerID, followeeID); err != nil {
+		zap.L().Error("redis.DeleteFollowCounts() failed", zap.Error(err))
+	}
+	if err := redis.InvalidateFollowing(followerID); err != nil {
+		zap.L().Error("redis.InvalidateFollowing() failed", zap.Error(err))
+	}
+
+	counts, err := GetFollowCounts(followeeID)
+	if err != nil {
+		zap.L().Error("GetFollowCounts() failed", zap.Error(err))
+		return
+	}
+	if err = redis.SetPopularAuthor(followeeID, counts.Followers >= redis.FollowPopularLimit); err != nil {
+		zap.L().Error("redis.SetPopularAuthor() failed", zap.Error(err))
+	}
+}
+
+// GetFollowCounts 获取用户的粉丝数与关注数，优先读缓存
+// 参数:
+//   - userID: 用户ID
+//
+// 返回值:
+//   - *models.FollowCounts: 计数
+//   - error: 可能的错误
+func GetFollowCounts(userID uint64) (*models.FollowCounts, error) {
+	counts, err := redis.GetFollowCounts(userID)
+	if err != nil {
+		zap.L().Error("redis.GetFollowCounts() failed", zap.Error(err))
+	}
+	if counts != nil {
+		return counts, nil
+	}
+
+	counts = new(models.FollowCounts)
+	if counts.Followers, err = mysql.CountFollowers(userID); err != nil {
+		return nil, err
+	}
+	if counts.Following, err = mysql.CountFollowing(userID); err != nil {
+		return nil, err
+	}
+	if err = redis.SetFollowCounts(userID, counts); err != nil {
+		zap.L().Error("redis.SetFollowCounts() failed", zap.Error(err))
+	}
+	return counts, nil
+}
+
+// GetFollowerList 分页获取粉丝列表
+// 参数:
+//   - userID: 用户ID
+//   - page: 页码
+//   - size: 每页数量
+//
+// 返回值:
+//   - *models.UserListRes: 粉丝列表及分页信息
+//   - error: 用户不存在时返回mysql.ErrorUserNotExist
+func GetFollowerList(userID uint64, page, size int64) (*models.UserListRes, error) {
+	if _, err := getProfileUser(userID); err != nil {
+		return nil, err
+	}
+	counts, err := GetFollowCounts(userID)
+	if err != nil {
+		return nil, err
+	}
+	users, err := mysql.GetFollowerList(userID, page, size)
+	if err != nil {
+		return nil, err
+	}
+	return &models.UserListRes{
+		Page: models.Page{Total: counts.Followers, Page: page, Size: size},
+		List: users,
+	}, nil
+}
+
+// GetFollowingList 分页获取关注列表
+// 参数:
+//   - userID: 用户ID
+//   - page: 页码
+//   - size: 每页数量
+//
+// 返回值:
+//   - *models.UserListRes: 关注列表及分页信息
+//   - error: 用户不存在时返回mysql.ErrorUserNotExist
+func GetFollowingList(userID uint64, page, size int64) (*models.UserListRes, error) {
+	if _, err := getProfileUser(userID); err != nil {
+		return nil, err
+	}
+	counts, err := GetFollowCounts(userID)
+	if err != nil {
+		return nil, err
+	}
+	users, err := mysql.GetFollowingList(userID, page, size)
+	if err != nil {
+		return nil, err
+	}
+	return &models.UserListRes{
+		Page: models.Page{Total: counts.Following, Page: page, Size: size},
+		List: users,
+	}, nil
+}
+
+// fanoutPost 将新帖子推送到粉丝的时间线，大V的帖子由粉丝读取时拉取
+// 参数:
+//   - p: 新帖子
+func fanoutPost(p *models.Post) {
+	popular, err := redis.IsPopularAuthor(p.AuthorID)
+	if err != nil {
+		zap.L().Error("redis.IsPopularAuthor() failed", zap.Error(err))
+		return
+	}
+	if popular {
+		return
+	}
+
+	var afterID uint64
+	for {
+		ids, err := mysql.GetFollowerIDsAfter(p.AuthorID, afterID, redis.TimelineFanoutBatch)
+		if err != nil {
+			zap.L().Error("mysql.GetFollowerIDsAfter() failed", zap.Error(err))
+			return
+		}
+		if len(ids) == 0 {
+			return
+		}
+		if err = redis.PushToTimelines(ids, p.PostID, p.CreateTime.Unix()); err != nil {
+			zap.L().Error("redis.PushToTimelines() failed",
+				zap.Int64("post_id", int64(p.PostID)),
+				zap.Error(err))
+		}
+		afterID = ids[len(ids)-1]
+	}
+}
+
+// getFollowingIDs 获取用户关注的人，优先读缓存
+func getFollowingIDs(userID uint64) ([]uint64, error) {
+	ids, ok, err := redis.GetFollowingSet(userID)
+	if err != nil {
+		zap.L().Error("redis.GetFollowingSet() failed", zap.Error(err))
+	}
+	if ok {
+		return ids, nil
+	}
+
+	ids, err = mysql.GetFollowingIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err = redis.SetFollowingSet(userID, ids); err != nil {
+		zap.L().Error("redis.SetFollowingSet() failed", zap.Error(err))
+	}
+	return ids, nil
+}
+
+// ensureUserPosts 作者的帖子时间线不存在时从数据库构建
+func ensureUserPosts(authorID uint64) error {
+	exists, err := redis.UserPostsExist(authorID)
+	if err != nil || exists {
+		return err
+	}
+	posts, err := mysql.GetRecentPostsByAuthor(authorID, redis.TimelineMaxLen)
+	if err != nil {
+		return err
+	}
+	return redis.SetUserPosts(authorID, posts)
+}
+
+// GetFollowingFeed 获取关注时间线
+// 普通作者发帖时推送到粉丝的时间线（推模式），大V的帖子在读取时合并（拉模式）
+// 参数:
+//   - userID: 当前用户ID
+//   - p: 分页参数，支持页码和游标
+//
+// 返回值:
+//   - *models.PostDetailRes: 帖子详情列表、分页信息和下一页的游标，total为时间线中的帖子数
+//   - error: 游标无效时返回ErrorInvalidCursor
+func GetFollowingFeed(userID uint64, p *models.ParamPostList) (*models.PostDetailRes, error) {
+	// 时间线只按发布时间排序
+	p.Order = models.OrderTime
+	if err := parseCursor(p); err != nil {
+		return nil, err
+	}
+	following, err := getFollowingIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(following) == 0 {
+		return &models.PostDetailRes{
+			Page: models.Page{Page: p.Page, Size: p.Size},
+			List: make([]*models.PostDetail, 0),
+		}, nil
+	}
+
+	popular, err := redis.GetPopularFollowing(userID)
+	if err != nil {
+		return nil, err
+	}
+	isPopular := make(map[uint64]bool, len(popular))
+	for _, id := range popular {
+		isPopular[id] = true
+		if err = ensureUserPosts(id); err != nil {
+			return nil, err
+		}
+	}
+
+	// 时间线闲置过期后不再接收推送，需要整体重建
+	exists, err := redis.TimelineExists(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		authors := make([]uint64, 0, len(following))
+		for _, id := range following {
+			if isPopular[id] {
+				continue
+			}
+			if err = ensureUserPosts(id); err != nil {
+				return nil, err
+			}
+			authors = append(authors, id)
+		}
+		if err = redis.RebuildTimeline(userID, authors); err != nil {
+			return nil, err
+		}
+	}
+
+	res, err := getPostListCommon(func(p *models.ParamPostList) (*models.PostIDPage, error) {
+		return redis.GetTimelinePostIDs(userID, popular, p)
+	}, p)
+	if err != nil {
+		zap.L().Error("GetFollowingFeed failed", zap.Error(err))
+		return nil, err
+	}
+	return res, nil
+}
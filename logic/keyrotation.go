@@ -0,0 +1,72 @@
+package logic
+
+import (
+	"land/pkg/jwt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// KeyRotationService 签名密钥轮换服务，定期检查当前密钥是否到期并清理已退役的旧密钥
+type KeyRotationService struct {
+	checkInterval time.Duration // 检查间隔
+	stopChan      chan bool     // 停止信号
+}
+
+// NewKeyRotationService 创建签名密钥轮换服务
+// 参数:
+//   - checkInterval: 检查间隔，应远小于轮换周期与access token有效期
+//
+// 返回值:
+//   - *KeyRotationService: 轮换服务实例
+func NewKeyRotationService(checkInterval time.Duration) *KeyRotationService {
+	return &KeyRotationService{
+		checkInterval: checkInterval,
+		stopChan:      make(chan bool),
+	}
+}
+
+// Start 启动轮换服务
+func (s *KeyRotationService) Start() {
+	go s.rotateLoop()
+	zap.L().Info("KeyRotationService started",
+		zap.Duration("check_interval", s.checkInterval))
+}
+
+// Stop 停止轮换服务
+func (s *KeyRotationService) Stop() {
+	close(s.stopChan)
+	zap.L().Info("KeyRotationService stopped")
+}
+
+// rotateLoop 轮换循环
+func (s *KeyRotationService) rotateLoop() {
+	ticker := time.NewTicker(s.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := jwt.RotateKeyIfDue(); err != nil {
+				zap.L().Error("jwt.RotateKeyIfDue() failed", zap.Error(err))
+			}
+		case <-s.stopChan:
+			return
+		}
+	}
+}
+
+// RotateSigningKey 立即轮换签名密钥，用于密钥疑似泄露等场景
+// 返回值:
+//   - string: 新密钥的kid
+//   - error: 可能的错误
+func RotateSigningKey() (string, error) {
+	return jwt.RotateKey()
+}
+
+// GetJWKS 获取当前有效的签名公钥集合
+// 返回值:
+//   - *jwt.JSONWebKeySet: 公钥集合
+func GetJWKS() *jwt.JSONWebKeySet {
+	return jwt.JWKS()
+}
@@ -0,0 +1,48 @@
+package logic
+
+import (
+	"land/models"
+	"land/pkg/markdown"
+
+	"go.uber.org/zap"
+)
+
+// renderContent 渲染Markdown，写入帖子和评论时与原文一起保存
+// 渲染失败时返回空字符串，读取时会再次尝试
+func renderContent(src string) string {
+	html, err := markdown.Render(src)
+	if err != nil {
+		zap.L().Error("markdown.Render() failed", zap.Error(err))
+		return ""
+	}
+	return html
+}
+
+// ensurePostHTML 为没有渲染结果的旧帖子补渲染，不回写数据库
+func ensurePostHTML(posts ...*models.Post) {
+	for _, p := range posts {
+		if p.ContentHTML == "" && p.Content != "" {
+			p.ContentHTML = renderContent(p.Content)
+		}
+	}
+}
+
+// ensureCommentHTML 为没有渲染结果的旧评论补渲染，不回写数据库
+func ensureCommentHTML(comments ...*models.Comment) {
+	for _, c := range comments {
+		if c.ContentHTML == "" && c.Content != "" {
+			c.ContentHTML = renderContent(c.Content)
+		}
+	}
+}
+
+// PreviewContent 渲染Markdown预览，不保存
+// 参数:
+//   - content: Markdown原文
+//
+// 返回值:
+//   - string: 过滤后的HTML
+//   - error: 可能的错误
+func PreviewContent(content string) (string, error) {
+	return markdown.Render(content)
+}
@@ -0,0 +1,307 @@
+package logic
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"land/dao/mysql"
+	"land/dao/redis"
+	"land/models"
+	"land/pkg/jwt"
+	"land/pkg/oidc"
+	"land/pkg/snowflake"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	oidcUsernameMaxLen   = 32 // 自动生成用户名的最大长度
+	oidcUsernameAttempts = 5  // 用户名冲突时追加随机后缀的尝试次数
+
+	// OIDCStateTTL 发起登录到回调的最长时间，浏览器绑定cookie的有效期与之一致
+	OIDCStateTTL = redis.OIDCStateTTL
+)
+
+var (
+	// ErrorOIDCStateInvalid state无效、已过期或与提供方不匹配
+	ErrorOIDCStateInvalid = errors.New("无效的登录状态")
+
+	// ErrorOIDCEmailConflict 第三方账号邮箱已被本站用户使用，需登录后手动绑定
+	ErrorOIDCEmailConflict = errors.New("该邮箱已注册，请登录后绑定")
+
+	// ErrorIdentityLinked 第三方身份已绑定其他用户
+	ErrorIdentityLinked = errors.New("该第三方账号已绑定其他用户")
+
+	// ErrorIdentityNotLinked 当前用户未绑定该提供方
+	ErrorIdentityNotLinked = errors.New("未绑定该第三方账号")
+
+	// ErrorLastLoginMethod 解绑后用户将无法登录
+	ErrorLastLoginMethod = errors.New("这是唯一的登录方式，请先设置密码")
+
+	usernameCleaner = regexp.MustCompile(`[^` + models.UsernameCharset + `]+`)
+)
+
+// GetOIDCProviders 获取已配置的第三方登录提供方
+// 返回值:
+//   - []string: 提供方名称列表
+func GetOIDCProviders() []string {
+	return oidc.Names()
+}
+
+// StartOIDCLogin 发起第三方登录或绑定，生成state、nonce与PKCE参数
+// 参数:
+//   - provider: 提供方名称
+//   - device: 登录设备名称
+//   - linkUserID: 已登录用户绑定账号时传入用户ID，登录时传0
+//
+// 返回值:
+//   - authURL: 跳转到提供方的授权地址
+//   - binding: state的哈希，需写入发起者浏览器的cookie，回调时校验，防止把回调链接交给他人完成（登录/绑定CSRF）
+//   - err: 未配置的提供方返回oidc.ErrProviderNotFound
+func StartOIDCLogin(provider, device string, linkUserID uint64) (authURL, binding string, err error) {
+	p, err := oidc.Get(provider)
+	if err != nil {
+		return "", "", err
+	}
+
+	state, err := jwt.GenOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := jwt.GenOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+	verifier, challenge, err := oidc.NewPKCE()
+	if err != nil {
+		return "", "", err
+	}
+
+	authURL, err = p.AuthCodeURL(state, nonce, challenge)
+	if err != nil {
+		return "", "", err
+	}
+
+	err = redis.SaveOIDCState(jwt.HashToken(state), &models.OIDCAuthState{
+		Provider:   provider,
+		Verifier:   verifier,
+		Nonce:      nonce,
+		Device:     device,
+		LinkUserID: linkUserID,
+	})
+	if err != nil {
+		return "", "", err
+	}
+	return authURL, jwt.HashToken(state), nil
+}
+
+// FinishOIDCLogin 处理提供方回调：兑换授权码、校验ID Token，再登录、注册或绑定
+// 参数:
+//   - provider: 提供方名称
+//   - p: 回调中的code与state
+//   - binding: 浏览器cookie中的state哈希，必须与回调中的state一致
+//
+// 返回值:
+//   - *models.User: 对应的本站用户
+//   - *models.OIDCAuthState: 发起时保存的状态，LinkUserID非0表示本次为绑定
+//   - error: cookie与state不一致时返回ErrorOIDCStateInvalid
+func FinishOIDCLogin(provider string, p *models.ParamOIDCCallback, binding string) (*models.User, *models.OIDCAuthState, error) {
+	// 回调不需要登录，只有发起流程的浏览器带有对应的cookie
+	stateHash := jwt.HashToken(p.State)
+	if subtle.ConstantTimeCompare([]byte(binding), []byte(stateHash)) != 1 {
+		return nil, nil, ErrorOIDCStateInvalid
+	}
+	state, err := redis.ConsumeOIDCState(stateHash)
+	if err != nil {
+		if errors.Is(err, redis.ErrOIDCStateNotFound) {
+			return nil, nil, ErrorOIDCStateInvalid
+		}
+		return nil, nil, err
+	}
+	if state.Provider != provider {
+		return nil, nil, ErrorOIDCStateInvalid
+	}
+
+	op, err := oidc.Get(provider)
+	if err != nil {
+		return nil, nil, err
+	}
+	idToken, err := op.Exchange(p.Code, state.Verifier, state.Nonce)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if state.LinkUserID != 0 {
+		user, err := linkIdentity(state.LinkUserID, provider, idToken)
+		return user, state, err
+	}
+
+	identity, err := mysql.GetIdentity(provider, idToken.Subject)
+	if err == nil {
+		user, err := mysql.GetUserById(identity.UserID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if user.UserID == 0 {
+			return nil, nil, mysql.ErrorUserNotExist
+		}
+		return user, state, nil
+	}
+	if !errors.Is(err, mysql.ErrorIdentityNotExist) {
+		return nil, nil, err
+	}
+
+	user, err := createOIDCUser(provider, idToken)
+	return user, state, err
+}
+
+// GetUserIdentities 获取用户绑定的第三方身份
+// 参数:
+//   - userID: 用户ID
+//
+// 返回值:
+//   - []*models.UserIdentity: 第三方身份列表
+//   - error: 可能的错误
+func GetUserIdentities(userID uint64) ([]*models.UserIdentity, error) {
+	return mysql.GetUserIdentities(userID)
+}
+
+// UnlinkIdentity 解绑第三方身份，没有密码的用户不能解绑最后一个身份
+// 参数:
+//   - userID: 用户ID
+//   - provider: 提供方名称
+//
+// 返回值:
+//   - error: 可能的错误
+func UnlinkIdentity(userID uint64, provider string) error {
+	user, err := mysql.GetUserById(userID)
+	if err != nil {
+		return err
+	}
+	identities, err := mysql.GetUserIdentities(userID)
+	if err != nil {
+		return err
+	}
+	if user.Password == "" && len(identities) <= 1 {
+		return ErrorLastLoginMethod
+	}
+
+	ok, err := mysql.DeleteIdentity(userID, provider)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrorIdentityNotLinked
+	}
+	return nil
+}
+
+// linkIdentity 将第三方身份绑定到已登录用户
+func linkIdentity(userID uint64, provider string, idToken *oidc.IDToken) (*models.User, error) {
+	user, err := mysql.GetUserById(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.UserID == 0 {
+		return nil, mysql.ErrorUserNotExist
+	}
+
+	identity, err := mysql.GetIdentity(provider, idToken.Subject)
+	if err == nil {
+		if identity.UserID != userID {
+			return nil, ErrorIdentityLinked
+		}
+		return user, nil
+	}
+	if !errors.Is(err, mysql.ErrorIdentityNotExist) {
+		return nil, err
+	}
+
+	err = mysql.InsertIdentity(&models.UserIdentity{
+		UserID:     userID,
+		Provider:   provider,
+		Subject:    idToken.Subject,
+		Email:      idToken.Email,
+		CreateTime: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	zap.L().Info("OIDC identity linked", zap.Int64("user_id", int64(userID)), zap.String("provider", provider))
+	return user, nil
+}
+
+// createOIDCUser 第三方身份首次登录时创建本站用户
+// 邮箱已被本站用户使用时不自动合并，防止通过第三方账号接管已有账号
+func createOIDCUser(provider string, idToken *oidc.IDToken) (*models.User, error) {
+	if idToken.Email != "" {
+		_, err := mysql.GetUserByEmail(idToken.Email)
+		if err == nil {
+			return nil, ErrorOIDCEmailConflict
+		}
+		if !errors.Is(err, mysql.ErrorUserNotExist) {
+			return nil, err
+		}
+	}
+
+	user := &models.User{
+		UserID:        snowflake.GetID(),
+		Username:      oidcUsername(provider, idToken),
+		Email:         idToken.Email,
+		EmailVerified: idToken.Email != "" && idToken.EmailVerified,
+		// 密码留空，只能通过第三方登录，Verify对空哈希始终返回不匹配
+		Password: "",
+	}
+	identity := &models.UserIdentity{
+		Provider:   provider,
+		Subject:    idToken.Subject,
+		Email:      idToken.Email,
+		CreateTime: time.Now(),
+	}
+	if err := mysql.InsertUserWithIdentity(user, identity); err != nil {
+		return nil, err
+	}
+	zap.L().Info("User created from OIDC login", zap.Int64("user_id", int64(user.UserID)), zap.String("provider", provider))
+	return user, nil
+}
+
+// oidcUsername 根据ID Token生成未被占用的用户名
+// 依次尝试preferred_username、邮箱前缀、name，冲突时追加随机后缀
+func oidcUsername(provider string, idToken *oidc.IDToken) string {
+	base := ""
+	for _, candidate := range []string{
+		idToken.PreferredUsername,
+		strings.SplitN(idToken.Email, "@", 2)[0],
+		idToken.Name,
+	} {
+		candidate = usernameCleaner.ReplaceAllString(candidate, "")
+		if candidate != "" {
+			base = candidate
+			break
+		}
+	}
+	if base == "" {
+		base = provider + "_user"
+	}
+	if r := []rune(base); len(r) > oidcUsernameMaxLen-7 {
+		base = string(r[:oidcUsernameMaxLen-7])
+	}
+
+	if !mysql.CheckUserExist(base) {
+		return base
+	}
+	for i := 0; i < oidcUsernameAttempts; i++ {
+		suffix, err := jwt.GenOpaqueToken()
+		if err != nil {
+			break
+		}
+		name := fmt.Sprintf("%s_%s", base, strings.ToLower(suffix[:6]))
+		if !mysql.CheckUserExist(name) {
+			return name
+		}
+	}
+	return fmt.Sprintf("%s_%d", base, snowflake.GetID())
+}
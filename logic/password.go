@@ -0,0 +1,92 @@
+package logic
+
+import (
+	"errors"
+	"fmt"
+	"land/dao/mysql"
+	"land/dao/redis"
+	"land/pkg/jwt"
+	"land/pkg/mail"
+	"land/pkg/password"
+	"land/settings"
+	"net/url"
+
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrorResetTokenInvalid 重置令牌无效、已过期或已被使用
+	ErrorResetTokenInvalid = errors.New("无效的重置令牌")
+)
+
+// ForgotPassword 申请重置密码，向邮箱发送一次性重置令牌
+// 只接受已验证的邮箱：未验证的邮箱可能被他人抢注，不能证明邮箱持有者就是账号所有者
+// 邮箱未注册或未验证时同样返回成功，避免被用来探测账号是否存在
+// 参数:
+//   - email: 已验证的注册邮箱
+//
+// 返回值:
+//   - error: 可能的错误
+func ForgotPassword(email string) error {
+	user, err := mysql.GetUserByVerifiedEmail(email)
+	if err != nil {
+		if errors.Is(err, mysql.ErrorUserNotExist) {
+			zap.L().Info("Password reset requested for unknown or unverified email", zap.String("email", email))
+			return nil
+		}
+		return err
+	}
+
+	token, err := jwt.GenOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	if err = redis.SavePasswordResetToken(jwt.HashToken(token), user.UserID); err != nil {
+		if errors.Is(err, redis.ErrResetTooFrequent) {
+			zap.L().Info("Password reset requested too frequently", zap.Int64("user_id", int64(user.UserID)))
+			return nil
+		}
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", settings.Conf.SiteURL, url.QueryEscape(token))
+	body := fmt.Sprintf("%s，你好：\n\n我们收到了重置密码的申请，请在%d分钟内打开以下链接设置新密码：\n\n%s\n\n如果不是你本人操作，请忽略本邮件。\n",
+		user.Username, int(redis.PasswordResetTTL.Minutes()), link)
+
+	return mail.Send(user.Email, "重置密码", body)
+}
+
+// ResetPassword 使用重置令牌设置新密码，并吊销该用户的全部会话
+// 参数:
+//   - token: 邮件中的重置令牌
+//   - newPassword: 新密码
+//
+// 返回值:
+//   - error: 令牌无效时返回ErrorResetTokenInvalid
+func ResetPassword(token, newPassword string) error {
+	userID, err := redis.ConsumePasswordResetToken(jwt.HashToken(token))
+	if err != nil {
+		if errors.Is(err, redis.ErrResetTokenNotFound) {
+			return ErrorResetTokenInvalid
+		}
+		return err
+	}
+
+	hash, err := password.Hash(newPassword)
+	if err != nil {
+		return err
+	}
+	if err = mysql.UpdateUserPassword(userID, hash); err != nil {
+		return err
+	}
+
+	// 密码已变更，所有设备上的令牌全部作废
+	if err = redis.DeleteAllSessions(userID); err != nil {
+		zap.L().Error("redis.DeleteAllSessions() failed", zap.Int64("user_id", int64(userID)), zap.Error(err))
+		return err
+	}
+
+	zap.L().Info("Password reset", zap.Int64("user_id", int64(userID)))
+	return nil
+}
@@ -12,18 +12,63 @@ import (
 	"go.uber.org/zap"
 )
 
+// CreatePost 创建帖子
+// 状态为草稿或定时发布时只写入MySQL，不进入Redis排序，也不推送到粉丝时间线
+// 参数:
+//   - p: 帖子，status为PostStatusDraft/PostStatusScheduled时保存为草稿/定时帖子，其余按立即发布处理
+//
+// 返回值:
+//   - err: 定时发布时间不晚于当前时间时返回ErrorInvalidPublishTime，@了拉黑自己的用户时返回ErrorBlocked，
+//     标签不合法时返回ErrorInvalidTag或ErrorTooManyTags
 func CreatePost(p *models.Post) (err error) {
+	if err = checkMentions(p.AuthorID, p.Title, p.Content); err != nil {
+		return
+	}
+	if p.Tags, err = normalizeTags(p.Tags); err != nil {
+		return
+	}
+
 	p.ID = snowflake.GetID()
+	p.PostID = p.ID
+	p.ContentHTML = renderContent(p.Content)
+	p.CreateTime = time.Now()
+	p.UpdateTime = p.CreateTime
+
+	switch p.Status {
+	case models.PostStatusDraft:
+		p.PublishTime = nil
+	case models.PostStatusScheduled:
+		if p.PublishTime == nil || !p.PublishTime.After(p.CreateTime) {
+			return ErrorInvalidPublishTime
+		}
+	default:
+		p.Status = models.PostStatusPublished
+		p.PublishTime = &p.CreateTime
+	}
 
 	err = mysql.CreatePost(p)
-	if err != nil {
+	if err != nil || p.Status != models.PostStatusPublished {
 		return
 	}
 
-	err = redis.CreatePost(p.ID, p.CommunityID)
-	if err != nil {
-		return
+	return publishPost(p)
+}
+
+// publishPost 帖子发布后加入Redis排序、社区集合、标签排序与搜索索引，并推送到粉丝的关注时间线
+// 立即发布走这里，草稿和定时帖子由publishUnpublished先写Redis再改状态，p.CreateTime为实际发布时间，p.Tags为帖子的标签
+func publishPost(p *models.Post) error {
+	if err := redis.CreatePost(p.PostID, p.AuthorID, p.CommunityID, p.CreateTime.Unix(), p.Tags, postRanks(p, 0, 0)); err != nil {
+		return err
 	}
+	indexPost(p)
+	pushPublishedPost(p)
+	return nil
+}
+
+// pushPublishedPost 帖子写入Redis后推送到粉丝的关注时间线
+func pushPublishedPost(p *models.Post) {
+	// 推送到粉丝的关注时间线，粉丝多时耗时较长，不阻塞发帖
+	go fanoutPost(p)
 
 	// 清除相关缓存
 	go func() {
@@ -33,8 +78,6 @@ func CreatePost(p *models.Post) (err error) {
 			zap.Int64("post_id", int64(p.ID)),
 			zap.Int64("author_id", int64(p.AuthorID)))
 	}()
-
-	return
 }
 
 func GetPostByID(pid uint64, userID ...uint64) (data *models.PostDetail, err error) {
@@ -157,6 +200,8 @@ func GetPostByID(pid uint64, userID ...uint64) (data *models.PostDetail, err err
 		viewCount = 0
 	}
 	post.ViewCount = viewCount
+	ensurePostHTML(post)
+	fillPostTags(post)
 
 	// 6. 组装数据
 	data = &models.PostDetail{
@@ -181,13 +226,19 @@ func GetPostByID(pid uint64, userID ...uint64) (data *models.PostDetail, err err
 	return data, nil
 }
 
-// GetPostList 获取帖子列表
-func GetPostList(page, size int64) (data []*models.PostDetail, err error) {
+// GetPostList 获取帖子列表，总数取Redis中维护的已发布帖子数
+func GetPostList(page, size int64) (*models.PostDetailRes, error) {
+	total, err := redis.CountPosts(0)
+	if err != nil {
+		return nil, err
+	}
 	posts, err := mysql.GetPostList(page, size)
 	if err != nil {
 		return nil, err
 	}
-	data = make([]*models.PostDetail, 0, len(posts))
+	ensurePostHTML(posts...)
+	fillPostTags(posts...)
+	data := make([]*models.PostDetail, 0, len(posts))
 
 	for _, post := range posts {
 		// 根据作者id查询作者信息
@@ -213,18 +264,21 @@ func GetPostList(page, size int64) (data []*models.PostDetail, err error) {
 		}
 		data = append(data, postDetail)
 	}
-	return
+	return &models.PostDetailRes{
+		Page: models.Page{Total: total, Page: page, Size: size},
+		List: data,
+	}, nil
 }
 
 // GetPostListNew  将两个查询帖子列表逻辑合二为一的函数
-func GetPostListNew(p *models.ParamPostList) (data []*models.PostDetail, err error) {
+func GetPostListNew(p *models.ParamPostList) (res *models.PostDetailRes, err error) {
 	// 根据请求参数的不同，执行不同的逻辑。
 	if p.CommunityID == 0 {
 		// 查所有
-		data, err = getPostListCommon(redis.GetPostIDsInOrder, p)
+		res, err = getPostListCommon(redis.GetPostIDsInOrder, p)
 	} else {
 		// 根据社区id查询
-		data, err = getPostListCommon(redis.GetCommunityPostIDsInOrder, p)
+		res, err = getPostListCommon(redis.GetCommunityPostIDsInOrder, p)
 	}
 	if err != nil {
 		zap.L().Error("GetPostListNew failed", zap.Error(err))
@@ -233,29 +287,46 @@ func GetPostListNew(p *models.ParamPostList) (data []*models.PostDetail, err err
 	return
 }
 
-func getPostListCommon(getIDsFunc func(p *models.ParamPostList) ([]string, error), p *models.ParamPostList) (data []*models.PostDetail, err error) {
-	// 1. 获取帖子 ID 列表
-	ids, err := getIDsFunc(p)
+// getPostListCommon 按getIDsFunc查到的帖子ID组装帖子列表，分页信息中的总数取自ID所在集合的大小
+func getPostListCommon(getIDsFunc func(p *models.ParamPostList) (*models.PostIDPage, error), p *models.ParamPostList) (*models.PostDetailRes, error) {
+	// 1. 获取帖子 ID 列表、总数和下一页的游标位置
+	idPage, err := getIDsFunc(p)
 	if err != nil {
-		return
+		return nil, err
 	}
+	res := &models.PostDetailRes{
+		Page:       models.Page{Total: idPage.Total, Page: p.Page, Size: p.Size},
+		List:       make([]*models.PostDetail, 0, len(idPage.IDs)),
+		NextCursor: encodeCursor(p.Order, idPage.Next),
+	}
+	ids := idPage.IDs
 	if len(ids) == 0 {
 		zap.L().Warn("getIDsFunc(p) return 0 data")
-		return
+		return res, nil
 	}
 	zap.L().Debug("getPostListCommon", zap.Any("ids", ids))
 
 	// 2. 根据 ID 列表查询帖子详细信息
 	posts, err := mysql.GetPostListByIDs(ids)
 	if err != nil {
-		return
+		return nil, err
 	}
 	zap.L().Debug("getPostListCommon", zap.Any("posts", posts))
+	ensurePostHTML(posts...)
+	fillPostTags(posts...)
+
+	// 已删除的帖子不会返回，按实际查到的帖子取投票数和访问量，保证下标一致
+	if len(posts) < len(ids) {
+		ids = make([]string, 0, len(posts))
+		for _, post := range posts {
+			ids = append(ids, strconv.FormatUint(post.PostID, 10))
+		}
+	}
 
 	// 3. 查询每篇帖子的投票数
 	voteData, err := redis.GetPostVoteData(ids)
 	if err != nil {
-		return
+		return nil, err
 	}
 
 	// 4. 查询每篇帖子的访问量
@@ -267,7 +338,13 @@ func getPostListCommon(getIDsFunc func(p *models.ParamPostList) ([]string, error
 	}
 
 	// 5. 填充作者和社区信息，同时尝试从缓存获取完整数据
+	hidden := getHiddenAuthors(p.ViewerID)
 	for idx, post := range posts {
+		// 跳过当前用户屏蔽和拉黑的作者
+		if hidden[post.AuthorID] {
+			continue
+		}
+
 		// 尝试从缓存获取完整数据
 		cacheData, err := redis.GetPostCache(post.AuthorID, post.PostID)
 		if err == nil {
@@ -287,7 +364,7 @@ func getPostListCommon(getIDsFunc func(p *models.ParamPostList) ([]string, error
 				if idx < len(voteData) {
 					postDetail.VoteNum = voteData[idx]
 				}
-				data = append(data, &postDetail)
+				res.List = append(res.List, &postDetail)
 				continue
 			}
 		}
@@ -319,7 +396,7 @@ func getPostListCommon(getIDsFunc func(p *models.ParamPostList) ([]string, error
 			Post:            post,
 			CommunityDetail: community,
 		}
-		data = append(data, postDetail)
+		res.List = append(res.List, postDetail)
 
 		// 异步回填缓存
 		go func(p *models.Post, pd *models.PostDetail) {
@@ -328,18 +405,42 @@ func getPostListCommon(getIDsFunc func(p *models.ParamPostList) ([]string, error
 			}
 		}(post, postDetail)
 	}
-	return
+	return res, nil
 }
 
 // GetPostListByOrder 根据排序方式获取帖子列表（混合策略）
+// 指定cursor时从上一页最后一个帖子之后开始取，翻页期间有新帖子或排序变化也不会重复、遗漏
 // 参数:
 //   - p: 查询参数
 //
 // 返回值:
-//   - data: 帖子详情列表
-//   - err: 可能的错误
-func GetPostListByOrder(p *models.ParamPostList) (data []*models.PostDetail, err error) {
-	// 如果明确指定不使用索引，或者按分数排序，使用Redis
+//   - *models.PostDetailRes: 帖子详情列表、分页信息和下一页的游标
+//   - error: 可能的错误
+func GetPostListByOrder(p *models.ParamPostList) (*models.PostDetailRes, error) {
+	if p.Tag != "" {
+		tag, ok := normalizeTag(p.Tag)
+		if !ok {
+			return nil, ErrorInvalidTag
+		}
+		p.Tag = tag
+	}
+	if p.Search == "" && p.Order == models.OrderRelevance {
+		p.Order = models.OrderTime
+	}
+	if err := parseCursor(p); err != nil {
+		return nil, err
+	}
+
+	// 搜索时在倒排索引中求交集，同时处理社区、标签过滤和排序
+	if p.Search != "" {
+		return searchPostList(p)
+	}
+	// 按标签过滤时使用标签的排序集合
+	if p.Tag != "" {
+		return getPostListCommon(redis.GetTagPostIDsInOrder, p)
+	}
+
+	// 如果明确指定不使用索引，或者按分数排序，使用Redis；按排序策略排序时走下面的默认分支
 	if !p.UseIndex || p.Order == "score" {
 		return GetPostListNew(p)
 	}
@@ -354,23 +455,45 @@ func GetPostListByOrder(p *models.ParamPostList) (data []*models.PostDetail, err
 }
 
 // getPostListFromMySQL 从MySQL获取帖子列表（利用数据库索引）
+// 总数取Redis中维护的帖子数，不在数据库中COUNT
 // 参数:
 //   - p: 查询参数
 //
 // 返回值:
-//   - data: 帖子详情列表
-//   - err: 可能的错误
-func getPostListFromMySQL(p *models.ParamPostList) (data []*models.PostDetail, err error) {
+//   - *models.PostDetailRes: 帖子详情列表、分页信息和下一页的游标
+//   - error: 可能的错误
+func getPostListFromMySQL(p *models.ParamPostList) (*models.PostDetailRes, error) {
+	total, err := redis.CountPosts(p.CommunityID)
+	if err != nil {
+		zap.L().Error("redis.CountPosts() failed", zap.Error(err))
+		return nil, err
+	}
+	res := &models.PostDetailRes{
+		Page: models.Page{Total: total, Page: p.Page, Size: p.Size},
+	}
+
 	// 从MySQL获取帖子列表
-	posts, err := mysql.GetPostListByOrder(p.Order, p.Page, p.Size, p.CommunityID)
+	posts, err := mysql.GetPostListByOrder(p.Order, p.Page, p.Size, p.CommunityID, p.After)
 	if err != nil {
 		zap.L().Error("mysql.GetPostListByOrder() failed", zap.Error(err))
 		return nil, err
 	}
 
+	res.List = make([]*models.PostDetail, 0, len(posts))
 	if len(posts) == 0 {
-		return make([]*models.PostDetail, 0), nil
+		return res, nil
+	}
+	// 游标取自数据库中的排序字段，需在访问量被Redis中的值覆盖之前记录
+	if int64(len(posts)) == p.Size {
+		last := posts[len(posts)-1]
+		next := &models.PostCursor{Sort: float64(last.CreateTime.Unix()), PostID: last.PostID}
+		if p.Order == models.OrderView {
+			next.Sort = float64(last.ViewCount)
+		}
+		res.NextCursor = encodeCursor(p.Order, next)
 	}
+	ensurePostHTML(posts...)
+	fillPostTags(posts...)
 
 	// 提取帖子ID列表用于批量获取投票数据和访问量
 	postIDs := make([]string, 0, len(posts))
@@ -404,8 +527,13 @@ func getPostListFromMySQL(p *models.ParamPostList) (data []*models.PostDetail, e
 	}
 
 	// 组装帖子详情数据
-	data = make([]*models.PostDetail, 0, len(posts))
+	hidden := getHiddenAuthors(p.ViewerID)
 	for i, post := range posts {
+		// 跳过当前用户屏蔽和拉黑的作者
+		if hidden[post.AuthorID] {
+			continue
+		}
+
 		// 获取用户信息
 		user, err := mysql.GetUserById(post.AuthorID)
 		if err != nil {
@@ -450,10 +578,10 @@ func getPostListFromMySQL(p *models.ParamPostList) (data []*models.PostDetail, e
 			CommunityDetail: community,
 		}
 
-		data = append(data, postDetail)
+		res.List = append(res.List, postDetail)
 	}
 
-	return data, nil
+	return res, nil
 }
 
 // UpdatePost 更新帖子（延迟双删策略）
@@ -480,6 +608,13 @@ func UpdatePost(p *models.UpdatePostForm, userID uint64) error {
 			zap.Int64("post_id", int64(p.PostID)))
 		return mysql.ErrorInvalidID // 使用现有错误，实际应该定义新的权限错误
 	}
+	if err = checkMentions(userID, p.Title, p.Content); err != nil {
+		return err
+	}
+	tags, err := normalizeTags(p.Tags)
+	if err != nil {
+		return err
+	}
 
 	// 2. 第一次删除缓存（立即删除）
 	err = redis.InvalidatePostCache(authorID, p.PostID)
@@ -495,16 +630,26 @@ func UpdatePost(p *models.UpdatePostForm, userID uint64) error {
 		PostID:      p.PostID,
 		Title:       p.Title,
 		Content:     p.Content,
+		ContentHTML: renderContent(p.Content),
 		CommunityID: p.CommunityID,
 	}
 
-	err = mysql.UpdatePost(post)
+	old, err := mysql.UpdatePost(post, userID, p.Reason)
 	if err != nil {
 		zap.L().Error("mysql.UpdatePost() failed",
 			zap.Int64("post_id", int64(p.PostID)),
 			zap.Error(err))
 		return err
 	}
+	if old.CommunityID != p.CommunityID {
+		movePostCommunity(old, p.CommunityID)
+	}
+	if tags != nil {
+		if err = updatePostTags(p.PostID, p.CommunityID, tags); err != nil {
+			return err
+		}
+	}
+	indexPost(post)
 
 	// 4. 延迟第二次删除缓存（延迟双删策略）
 	go func() {
@@ -526,6 +671,20 @@ func UpdatePost(p *models.UpdatePostForm, userID uint64) error {
 	return nil
 }
 
+// movePostCommunity 帖子修改社区后调整Redis中的社区集合与排序缓存，并按新社区的参数重算排序分数
+// 需在修改标签之前调用，此时数据库中还是原来的标签；数据库已生效，Redis调整失败只记录日志
+func movePostCommunity(old *models.Post, communityID uint64) {
+	tags, err := mysql.GetPostTags(old.PostID)
+	if err != nil {
+		zap.L().Error("mysql.GetPostTags() failed", zap.Int64("post_id", int64(old.PostID)), zap.Error(err))
+	}
+	if err = redis.MovePostCommunity(old.PostID, old.CommunityID, communityID, tags); err != nil {
+		zap.L().Error("redis.MovePostCommunity() failed", zap.Int64("post_id", int64(old.PostID)), zap.Error(err))
+		return
+	}
+	refreshPostRanks(&models.Post{PostID: old.PostID, CommunityID: communityID, CreateTime: old.CreateTime})
+}
+
 // UpdatePostWithCacheConsistency 更新帖子（带缓存一致性检查）
 // 参数:
 //   - p: 更新帖子参数
@@ -543,6 +702,13 @@ func UpdatePostWithCacheConsistency(p *models.UpdatePostForm, userID uint64) err
 	if authorID != userID {
 		return mysql.ErrorInvalidID
 	}
+	if err = checkMentions(userID, p.Title, p.Content); err != nil {
+		return err
+	}
+	tags, err := normalizeTags(p.Tags)
+	if err != nil {
+		return err
+	}
 
 	// 2. 第一次删除缓存
 	redis.InvalidatePostCache(authorID, p.PostID)
@@ -552,13 +718,23 @@ func UpdatePostWithCacheConsistency(p *models.UpdatePostForm, userID uint64) err
 		PostID:      p.PostID,
 		Title:       p.Title,
 		Content:     p.Content,
+		ContentHTML: renderContent(p.Content),
 		CommunityID: p.CommunityID,
 	}
 
-	err = mysql.UpdatePost(post)
+	old, err := mysql.UpdatePost(post, userID, p.Reason)
 	if err != nil {
 		return err
 	}
+	if old.CommunityID != p.CommunityID {
+		movePostCommunity(old, p.CommunityID)
+	}
+	if tags != nil {
+		if err = updatePostTags(p.PostID, p.CommunityID, tags); err != nil {
+			return err
+		}
+	}
+	indexPost(post)
 
 	// 4. 异步延迟删除缓存
 	go func() {
@@ -0,0 +1,209 @@
+package logic
+
+import (
+	"errors"
+	"land/dao/mysql"
+	"land/dao/redis"
+	"land/models"
+	"land/settings"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultPostRetention = 30 * 24 * time.Hour // 未配置时已删除帖子的保留期
+	postPurgeBatchSize   = 100                 // 每轮最多清除的帖子数
+)
+
+var (
+	ErrorPostForbidden = errors.New("无权操作该帖子")
+)
+
+// postRetention 已删除帖子的保留期
+func postRetention() time.Duration {
+	if settings.Conf.PostConfig == nil || settings.Conf.PostConfig.DeleteRetention <= 0 {
+		return defaultPostRetention
+	}
+	return time.Duration(settings.Conf.PostConfig.DeleteRetention) * time.Second
+}
+
+// DeletePost 删除帖子，保留期内可以恢复，重复删除视为成功
+// 参数:
+//   - pid: 帖子ID
+//   - operatorID: 当前用户ID
+//   - moderator: 当前用户是否为管理员或版主
+//
+// 返回值:
+//   - error: 帖子不存在返回mysql.ErrorInvalidID，不是作者也不是版主返回ErrorPostForbidden
+func DeletePost(pid, operatorID uint64, moderator bool) error {
+	post, err := mysql.GetPostByIDAnyStatus(pid)
+	if err != nil {
+		return err
+	}
+	if post.Status == models.PostStatusDraft || post.Status == models.PostStatusScheduled {
+		// 未发布的帖子通过草稿接口删除
+		return mysql.ErrorInvalidID
+	}
+	if post.AuthorID != operatorID && !moderator {
+		return ErrorPostForbidden
+	}
+	if post.Status == models.PostStatusDeleted {
+		return nil
+	}
+
+	// 先把Redis中的访问量写回数据库，恢复时以数据库为准
+	viewCount, err := redis.GetPostViewCount(pid)
+	if err != nil {
+		zap.L().Error("redis.GetPostViewCount() failed", zap.Int64("post_id", int64(pid)), zap.Error(err))
+	} else if viewCount > post.ViewCount {
+		if err = mysql.UpdatePostViewCount(pid, viewCount); err != nil {
+			return err
+		}
+	}
+
+	if post.Tags, err = mysql.GetPostTags(pid); err != nil {
+		return err
+	}
+	deleted, err := mysql.SetPostDeleted(pid, operatorID, time.Now())
+	if err != nil || !deleted {
+		return err
+	}
+	zap.L().Info("Post deleted",
+		zap.Int64("post_id", int64(pid)),
+		zap.Int64("operator_id", int64(operatorID)))
+
+	// 数据库状态已生效，读取路径都会过滤已删除的帖子，Redis清理失败只记录日志
+	if err := redis.RemovePost(post); err != nil {
+		zap.L().Error("redis.RemovePost() failed", zap.Int64("post_id", int64(pid)), zap.Error(err))
+	}
+	removePostIndex(pid)
+	return nil
+}
+
+// RestorePost 恢复已删除的帖子，帖子未删除时视为成功
+// 作者只能恢复自己删除的帖子，被版主删除的帖子只能由管理员或版主恢复
+// 参数:
+//   - pid: 帖子ID
+//   - operatorID: 当前用户ID
+//   - moderator: 当前用户是否为管理员或版主
+//
+// 返回值:
+//   - error: 帖子不存在返回mysql.ErrorInvalidID，无权恢复返回ErrorPostForbidden
+func RestorePost(pid, operatorID uint64, moderator bool) error {
+	post, err := mysql.GetPostByIDAnyStatus(pid)
+	if err != nil {
+		return err
+	}
+	if !moderator && (post.AuthorID != operatorID || post.DeleteBy != operatorID) {
+		return ErrorPostForbidden
+	}
+	if post.Status != models.PostStatusDeleted {
+		return nil
+	}
+
+	if post.Tags, err = mysql.GetPostTags(pid); err != nil {
+		return err
+	}
+
+	// 先重建Redis再修改数据库状态：Redis失败时帖子仍是已删除状态，可以重试；
+	// 数据库更新失败时多出的Redis记录会被读取路径过滤
+	// 删除期间的投票记录仍然保留，按其重新计算排序分数
+	up, down, err := redis.GetPostVoteCounts(strconv.FormatUint(pid, 10))
+	if err != nil {
+		zap.L().Error("redis.GetPostVoteCounts() failed", zap.Int64("post_id", int64(pid)), zap.Error(err))
+		return err
+	}
+	if err := redis.RestorePost(post, postRanks(post, up, down)); err != nil {
+		zap.L().Error("redis.RestorePost() failed", zap.Int64("post_id", int64(pid)), zap.Error(err))
+		return err
+	}
+
+	restored, err := mysql.RestorePost(pid)
+	if err != nil || !restored {
+		return err
+	}
+	zap.L().Info("Post restored",
+		zap.Int64("post_id", int64(pid)),
+		zap.Int64("operator_id", int64(operatorID)))
+	indexPost(post)
+	return nil
+}
+
+// PurgeDeletedPosts 彻底删除保留期已满的帖子及其评论、投票与访问记录
+// 返回值:
+//   - int: 本次删除的帖子数
+//   - error: 可能的错误
+func PurgeDeletedPosts() (int, error) {
+	posts, err := mysql.GetPostsToPurge(time.Now().Add(-postRetention()), postPurgeBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	purged := 0
+	for _, post := range posts {
+		if err := mysql.PurgePost(post.PostID); err != nil {
+			zap.L().Error("mysql.PurgePost() failed", zap.Int64("post_id", int64(post.PostID)), zap.Error(err))
+			continue
+		}
+		if err := redis.PurgePost(post.PostID); err != nil {
+			zap.L().Error("redis.PurgePost() failed", zap.Int64("post_id", int64(post.PostID)), zap.Error(err))
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// PostPurgeService 已删除帖子清除服务，定期删除保留期已满的帖子
+type PostPurgeService struct {
+	checkInterval time.Duration // 检查间隔
+	stopChan      chan bool     // 停止信号
+}
+
+// NewPostPurgeService 创建已删除帖子清除服务
+// 参数:
+//   - checkInterval: 检查间隔
+//
+// 返回值:
+//   - *PostPurgeService: 清除服务实例
+func NewPostPurgeService(checkInterval time.Duration) *PostPurgeService {
+	return &PostPurgeService{
+		checkInterval: checkInterval,
+		stopChan:      make(chan bool),
+	}
+}
+
+// Start 启动清除服务
+func (s *PostPurgeService) Start() {
+	go s.purgeLoop()
+	zap.L().Info("PostPurgeService started",
+		zap.Duration("check_interval", s.checkInterval))
+}
+
+// Stop 停止清除服务
+func (s *PostPurgeService) Stop() {
+	close(s.stopChan)
+	zap.L().Info("PostPurgeService stopped")
+}
+
+// purgeLoop 清除循环
+func (s *PostPurgeService) purgeLoop() {
+	ticker := time.NewTicker(s.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			n, err := PurgeDeletedPosts()
+			if err != nil {
+				zap.L().Error("PurgeDeletedPosts() failed", zap.Error(err))
+				continue
+			}
+			if n > 0 {
+				zap.L().Info("Deleted posts purged", zap.Int("count", n))
+			}
+		case <-s.stopChan:
+			return
+		}
+	}
+}
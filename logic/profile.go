@@ -0,0 +1,255 @@
+package logic
+
+import (
+	"errors"
+	"land/dao/mysql"
+	"land/dao/redis"
+	"land/models"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrorInvalidAvatar = errors.New("头像地址必须是http(s)链接")
+)
+
+// getProfileUser 获取用户，不存在时返回mysql.ErrorUserNotExist
+func getProfileUser(userID uint64) (*models.User, error) {
+	user, err := mysql.GetUserById(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.UserID == 0 {
+		return nil, mysql.ErrorUserNotExist
+	}
+	return user, nil
+}
+
+// getUserPostCount 获取作者已发布的帖子数，Redis中的帖子集合未加载时从数据库构建
+func getUserPostCount(userID uint64) (int64, error) {
+	count, ok, err := redis.GetUserPostCount(userID)
+	if err != nil {
+		zap.L().Error("redis.GetUserPostCount() failed", zap.Int64("user_id", int64(userID)), zap.Error(err))
+	}
+	if ok {
+		return count, nil
+	}
+
+	ids, err := mysql.GetPostIDsByAuthor(userID)
+	if err != nil {
+		return 0, err
+	}
+	if err = redis.SetUserPostSet(userID, ids); err != nil {
+		zap.L().Error("redis.SetUserPostSet() failed", zap.Int64("user_id", int64(userID)), zap.Error(err))
+	}
+	return int64(len(ids)), nil
+}
+
+// GetUserProfile 获取用户资料
+// 参数:
+//   - userID: 被查看的用户ID
+//   - self: 是否为本人查看，本人可见邮箱
+//
+// 返回值:
+//   - *models.UserProfile: 用户资料
+//   - error: 用户不存在时返回mysql.ErrorUserNotExist
+func GetUserProfile(userID uint64, self bool) (*models.UserProfile, error) {
+	user, err := getProfileUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	postCount, err := getUserPostCount(userID)
+	if err != nil {
+		return nil, err
+	}
+	commentCount, err := mysql.GetCommentCountByAuthor(userID)
+	if err != nil {
+		return nil, err
+	}
+	follow, err := GetFollowCounts(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := &models.UserProfile{
+		UserID:         user.UserID,
+		Username:       user.Username,
+		DisplayName:    user.DisplayName,
+		Bio:            user.Bio,
+		Gender:         user.Gender,
+		Avatar:         user.Avatar,
+		PostCount:      postCount,
+		CommentCount:   commentCount,
+		FollowerCount:  follow.Followers,
+		FollowingCount: follow.Following,
+		CreateTime:     user.CreateTime,
+	}
+	if self {
+		profile.Email = user.Email
+		profile.EmailVerified = &user.EmailVerified
+	}
+	return profile, nil
+}
+
+// UpdateUserProfile 修改个人资料，只更新请求中出现的字段
+// 参数:
+//   - userID: 用户ID
+//   - p: 资料参数
+//
+// 返回值:
+//   - *models.UserProfile: 更新后的资料
+//   - error: 头像地址非法时返回ErrorInvalidAvatar
+func UpdateUserProfile(userID uint64, p *models.ParamUpdateProfile) (*models.UserProfile, error) {
+	fields := make(map[string]interface{})
+	if p.DisplayName != nil {
+		fields["display_name"] = strings.TrimSpace(*p.DisplayName)
+	}
+	if p.Bio != nil {
+		fields["bio"] = strings.TrimSpace(*p.Bio)
+	}
+	if p.Gender != nil {
+		fields["gender"] = *p.Gender
+	}
+	if p.Avatar != nil {
+		avatar := strings.TrimSpace(*p.Avatar)
+		if avatar != "" {
+			u, err := url.Parse(avatar)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return nil, ErrorInvalidAvatar
+			}
+		}
+		fields["avatar"] = avatar
+	}
+
+	if len(fields) > 0 {
+		if err := mysql.UpdateUserProfile(userID, fields); err != nil {
+			zap.L().Error("mysql.UpdateUserProfile() failed",
+				zap.Int64("user_id", int64(userID)),
+				zap.Error(err))
+			return nil, err
+		}
+	}
+	return GetUserProfile(userID, true)
+}
+
+// GetUserPostList 分页获取用户发布的帖子
+// 参数:
+//   - userID: 作者ID
+//   - page: 页码
+//   - size: 每页数量
+//
+// 返回值:
+//   - *models.PostDetailRes: 帖子列表及分页信息
+//   - error: 用户不存在时返回mysql.ErrorUserNotExist
+func GetUserPostList(userID uint64, page, size int64) (*models.PostDetailRes, error) {
+	user, err := getProfileUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := getUserPostCount(userID)
+	if err != nil {
+		return nil, err
+	}
+	res := &models.PostDetailRes{
+		Page: models.Page{Total: total, Page: page, Size: size},
+		List: make([]*models.PostDetail, 0),
+	}
+	if total == 0 {
+		return res, nil
+	}
+
+	posts, err := mysql.GetPostListByAuthor(userID, page, size)
+	if err != nil {
+		return nil, err
+	}
+	ensurePostHTML(posts...)
+	fillPostTags(posts...)
+	if len(posts) == 0 {
+		return res, nil
+	}
+
+	postIDs := make([]string, 0, len(posts))
+	for _, post := range posts {
+		postIDs = append(postIDs, strconv.FormatUint(post.PostID, 10))
+	}
+	voteData, err := redis.GetPostVoteData(postIDs)
+	if err != nil {
+		zap.L().Error("redis.GetPostVoteData() failed", zap.Error(err))
+		voteData = make([]int64, len(postIDs))
+	}
+	viewCounts, err := redis.GetPostViewCounts(postIDs)
+	if err != nil {
+		zap.L().Error("redis.GetPostViewCounts() failed", zap.Error(err))
+		viewCounts = make([]int64, len(postIDs))
+	}
+
+	// 同一作者的帖子通常集中在少数社区，避免重复查询
+	communities := make(map[uint64]*models.CommunityDetail)
+	for i, post := range posts {
+		community, ok := communities[post.CommunityID]
+		if !ok {
+			community, err = mysql.GetCommunityDetailByID(post.CommunityID)
+			if err != nil {
+				zap.L().Error("mysql.GetCommunityDetailByID() failed",
+					zap.Int64("community_id", int64(post.CommunityID)),
+					zap.Error(err))
+				continue
+			}
+			communities[post.CommunityID] = community
+		}
+		if i < len(viewCounts) {
+			post.ViewCount = viewCounts[i]
+		}
+		var voteNum int64
+		if i < len(voteData) {
+			voteNum = voteData[i]
+		}
+		res.List = append(res.List, &models.PostDetail{
+			AuthorName:      user.Username,
+			VoteNum:         voteNum,
+			Post:            post,
+			CommunityDetail: community,
+		})
+	}
+	return res, nil
+}
+
+// GetUserCommentList 分页获取用户发表的评论
+// 参数:
+//   - userID: 作者ID
+//   - page: 页码
+//   - size: 每页数量
+//
+// 返回值:
+//   - *models.CommentListRes: 评论列表及分页信息
+//   - error: 用户不存在时返回mysql.ErrorUserNotExist
+func GetUserCommentList(userID uint64, page, size int64) (*models.CommentListRes, error) {
+	if _, err := getProfileUser(userID); err != nil {
+		return nil, err
+	}
+
+	total, err := mysql.GetCommentCountByAuthor(userID)
+	if err != nil {
+		return nil, err
+	}
+	res := &models.CommentListRes{
+		Page: models.Page{Total: total, Page: page, Size: size},
+		List: make([]*models.Comment, 0),
+	}
+	if total == 0 {
+		return res, nil
+	}
+
+	comments, err := mysql.GetCommentListByAuthor(userID, page, size)
+	if err != nil {
+		return nil, err
+	}
+	ensureCommentHTML(comments...)
+	res.List = comments
+	return res, nil
+}
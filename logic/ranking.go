@@ -0,0 +1,121 @@
+package logic
+
+import (
+	"land/dao/mysql"
+	"land/dao/redis"
+	"land/models"
+	"land/pkg/ranking"
+	"land/settings"
+	"strconv"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultVoteWindow   = 7 * 24 * 3600 // 默认投票期限（秒）
+	rankingRebuildBatch = 500           // 重算排序分数时每批处理的帖子数
+)
+
+// communityRanking 社区生效的排序配置
+type communityRanking struct {
+	voteWindow int64          // 投票期限（秒）
+	params     ranking.Params // 计算分数的参数
+}
+
+// getCommunityRanking 获取社区的排序配置，社区未配置的项使用全站配置，全站也未配置时使用默认值
+func getCommunityRanking(communityID uint64) communityRanking {
+	cr := communityRanking{
+		voteWindow: defaultVoteWindow,
+		params:     ranking.Params{UpWeight: 1, DownWeight: 1},
+	}
+	conf := settings.Conf.RankingConfig
+	if conf == nil {
+		return cr
+	}
+	applyRankingParams(&cr, conf.RankingParams)
+	for _, c := range conf.Communities {
+		if c != nil && c.CommunityID == communityID {
+			applyRankingParams(&cr, c.RankingParams)
+			break
+		}
+	}
+	return cr
+}
+
+// applyRankingParams 用配置中大于0的项覆盖排序配置
+func applyRankingParams(cr *communityRanking, p settings.RankingParams) {
+	if p.VoteWindow > 0 {
+		cr.voteWindow = int64(p.VoteWindow)
+	}
+	if p.UpWeight > 0 {
+		cr.params.UpWeight = p.UpWeight
+	}
+	if p.DownWeight > 0 {
+		cr.params.DownWeight = p.DownWeight
+	}
+	if p.HotDecay > 0 {
+		cr.params.HotDecay = float64(p.HotDecay)
+	}
+}
+
+// postRanks 按帖子所在社区的参数计算各排序策略的分数
+// 参数:
+//   - post: 帖子，需要community_id和create_time（发布时间）
+//   - up: 赞成票数
+//   - down: 反对票数
+//
+// 返回值:
+//   - map[string]float64: 排序方式到分数的映射
+func postRanks(post *models.Post, up, down int64) map[string]float64 {
+	params := getCommunityRanking(post.CommunityID).params
+	votes := ranking.Votes{Up: up, Down: down}
+	ranks := make(map[string]float64, len(models.RankOrders))
+	for _, order := range models.RankOrders {
+		s, ok := ranking.Get(order)
+		if !ok {
+			continue
+		}
+		ranks[order] = s.Score(votes, post.CreateTime.Unix(), params)
+	}
+	return ranks
+}
+
+// refreshPostRanks 投票变化后重新计算帖子的排序分数，失败只记录日志
+func refreshPostRanks(post *models.Post) {
+	err := redis.UpdatePostRanks(strconv.FormatUint(post.PostID, 10), func(up, down int64) map[string]float64 {
+		return postRanks(post, up, down)
+	})
+	if err != nil {
+		zap.L().Error("redis.UpdatePostRanks() failed", zap.Int64("post_id", int64(post.PostID)), zap.Error(err))
+	}
+}
+
+// RebuildRanking 按投票记录和当前配置重算所有已发布帖子的排序分数，修改排序参数后使用
+// 返回值:
+//   - int: 重算的帖子数
+//   - error: 可能的错误
+func RebuildRanking() (int, error) {
+	var afterID uint64
+	count := 0
+	for {
+		posts, err := mysql.GetPublishedPostsAfter(afterID, rankingRebuildBatch)
+		if err != nil {
+			return count, err
+		}
+		if len(posts) == 0 {
+			return count, nil
+		}
+		for _, post := range posts {
+			pid := strconv.FormatUint(post.PostID, 10)
+			up, down, err := redis.GetPostVoteCounts(pid)
+			if err != nil {
+				return count, err
+			}
+			if err = redis.SetPostRanks(pid, postRanks(post, up, down)); err != nil {
+				return count, err
+			}
+		}
+		count += len(posts)
+		afterID = posts[len(posts)-1].PostID
+	}
+}
@@ -0,0 +1,146 @@
+package logic
+
+import (
+	"land/dao/mysql"
+	"land/models"
+	"land/pkg/diff"
+)
+
+// getRevisionPost 获取要查看修订记录的帖子，已删除帖子的修订记录只对管理员和版主可见
+// 草稿和定时帖子尚未发布，对任何人都视为不存在
+func getRevisionPost(postID uint64, moderator bool) (*models.Post, error) {
+	post, err := mysql.GetPostByIDAnyStatus(postID)
+	if err != nil {
+		return nil, err
+	}
+	switch post.Status {
+	case models.PostStatusPublished:
+	case models.PostStatusDeleted:
+		if !moderator {
+			return nil, mysql.ErrorInvalidID
+		}
+	default:
+		// 未发布的帖子通过草稿接口编辑，不记录修订，作者通过草稿接口查看内容
+		return nil, mysql.ErrorInvalidID
+	}
+	return post, nil
+}
+
+// originalRevision 未编辑过的帖子没有修订记录，用帖子本身作为第1版
+func originalRevision(post *models.Post) *models.PostRevision {
+	return &models.PostRevision{
+		PostID:      post.PostID,
+		Version:     1,
+		Title:       post.Title,
+		Content:     post.Content,
+		CommunityID: post.CommunityID,
+		EditorID:    post.AuthorID,
+		CreateTime:  post.CreateTime,
+	}
+}
+
+// getRevision 获取帖子的指定版本
+func getRevision(post *models.Post, version int) (*models.PostRevision, error) {
+	revision, err := mysql.GetPostRevision(post.PostID, version)
+	if err == mysql.ErrorInvalidID && version == 1 {
+		// 第一次编辑时总会补记第1版，查不到说明帖子从未编辑过
+		return originalRevision(post), nil
+	}
+	return revision, err
+}
+
+// fillEditorNames 填充编辑人用户名，已注销的用户留空
+func fillEditorNames(revisions []*models.PostRevision) {
+	names := make(map[uint64]string)
+	for _, r := range revisions {
+		name, ok := names[r.EditorID]
+		if !ok {
+			if user, err := mysql.GetUserById(r.EditorID); err == nil {
+				name = user.Username
+			}
+			names[r.EditorID] = name
+		}
+		r.EditorName = name
+	}
+}
+
+// GetPostRevisions 分页获取帖子的修订记录
+// 参数:
+//   - postID: 帖子ID
+//   - page: 页码
+//   - size: 每页数量
+//   - moderator: 当前用户是否为管理员或版主
+//
+// 返回值:
+//   - *models.PostRevisionListRes: 修订记录及分页信息，按版本号倒序
+//   - error: 帖子不存在或无权查看时返回mysql.ErrorInvalidID
+func GetPostRevisions(postID uint64, page, size int64, moderator bool) (*models.PostRevisionListRes, error) {
+	post, err := getRevisionPost(postID, moderator)
+	if err != nil {
+		return nil, err
+	}
+	total, err := mysql.CountPostRevisions(postID)
+	if err != nil {
+		return nil, err
+	}
+
+	var revisions []*models.PostRevision
+	if total == 0 {
+		total = 1
+		revisions = make([]*models.PostRevision, 0, 1)
+		if page == 1 {
+			revisions = append(revisions, originalRevision(post))
+		}
+	} else if revisions, err = mysql.GetPostRevisions(postID, page, size); err != nil {
+		return nil, err
+	}
+	fillEditorNames(revisions)
+
+	return &models.PostRevisionListRes{
+		Page: models.Page{Total: total, Page: page, Size: size},
+		List: revisions,
+	}, nil
+}
+
+// ComparePostRevisions 按行比较帖子的两个版本
+// 参数:
+//   - postID: 帖子ID
+//   - p: 要比较的两个版本号
+//   - moderator: 当前用户是否为管理员或版主
+//
+// 返回值:
+//   - *models.PostRevisionDiff: 标题与内容的比较结果
+//   - error: 帖子或版本不存在、无权查看时返回mysql.ErrorInvalidID
+func ComparePostRevisions(postID uint64, p *models.ParamCompareRevision, moderator bool) (*models.PostRevisionDiff, error) {
+	post, err := getRevisionPost(postID, moderator)
+	if err != nil {
+		return nil, err
+	}
+	from, err := getRevision(post, p.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := getRevision(post, p.To)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PostRevisionDiff{
+		PostID:          postID,
+		From:            from.Version,
+		To:              to.Version,
+		FromCommunityID: from.CommunityID,
+		ToCommunityID:   to.CommunityID,
+		Title:           toDiffLines(diff.Lines(from.Title, to.Title)),
+		Content:         toDiffLines(diff.Lines(from.Content, to.Content)),
+	}, nil
+}
+
+// toDiffLines 转换为响应结构
+func toDiffLines(lines []diff.Line) []*models.DiffLine {
+	res := make([]*models.DiffLine, 0, len(lines))
+	for _, l := range lines {
+		res = append(res, &models.DiffLine{Op: string(l.Op), Text: l.Text})
+	}
+	return res
+}
@@ -0,0 +1,52 @@
+package logic
+
+import (
+	"land/dao/mysql"
+	"land/models"
+)
+
+// GetUserRoles 获取用户的全部角色，始终包含默认的user角色
+// 参数:
+//   - userID: 用户ID
+//
+// 返回值:
+//   - []string: 角色列表
+//   - error: 可能的错误
+func GetUserRoles(userID uint64) ([]string, error) {
+	roles, err := mysql.GetUserRoles(userID)
+	if err != nil {
+		return nil, err
+	}
+	return append([]string{models.RoleUser}, roles...), nil
+}
+
+// GrantRole 授予用户角色
+// 新角色在用户下一次登录或刷新令牌后生效
+// 参数:
+//   - userID: 用户ID
+//   - role: 角色
+//
+// 返回值:
+//   - error: 用户不存在时返回mysql.ErrorUserNotExist
+func GrantRole(userID uint64, role string) error {
+	user, err := mysql.GetUserById(userID)
+	if err != nil {
+		return err
+	}
+	if user.UserID == 0 {
+		return mysql.ErrorUserNotExist
+	}
+	return mysql.AddUserRole(userID, role)
+}
+
+// RevokeRole 回收用户角色
+// 已签发的access token在过期前仍携带旧角色，有效期较短
+// 参数:
+//   - userID: 用户ID
+//   - role: 角色
+//
+// 返回值:
+//   - error: 可能的错误
+func RevokeRole(userID uint64, role string) error {
+	return mysql.DeleteUserRole(userID, role)
+}
@@ -0,0 +1,111 @@
+package logic
+
+import (
+	"land/dao/mysql"
+	"land/dao/redis"
+	"land/models"
+	"land/pkg/markdown"
+	"land/pkg/search"
+
+	"go.uber.org/zap"
+)
+
+const (
+	searchTitleWeight  = 3   // 标题中的词按出现3次计
+	searchSaturation   = 1.2 // 词频饱和参数，出现次数越多增益越小
+	searchSnippetWidth = 120 // 内容摘要的字符数
+	searchReindexBatch = 500 // 重建索引时每批读取的帖子数
+)
+
+// postText 帖子内容的纯文本
+func postText(p *models.Post) string {
+	ensurePostHTML(p)
+	return markdown.Text(p.ContentHTML)
+}
+
+// indexPost 写入或更新帖子的搜索索引，失败只记录日志
+// 词的权重为 tf*(k1+1)/(tf+k1)，tf为内容中的次数加上标题中次数的3倍
+func indexPost(p *models.Post) {
+	if err := redis.IndexPost(p.PostID, searchWeights(p)); err != nil {
+		zap.L().Error("redis.IndexPost() failed", zap.Int64("post_id", int64(p.PostID)), zap.Error(err))
+	}
+}
+
+// searchWeights 计算帖子中各个词的索引权重
+func searchWeights(p *models.Post) map[string]float64 {
+	tf := search.Terms(postText(p))
+	for term, n := range search.Terms(p.Title) {
+		tf[term] += n * searchTitleWeight
+	}
+	weights := make(map[string]float64, len(tf))
+	for term, n := range tf {
+		weights[term] = float64(n) * (searchSaturation + 1) / (float64(n) + searchSaturation)
+	}
+	return weights
+}
+
+// removePostIndex 从搜索索引中移除帖子，失败只记录日志
+func removePostIndex(postID uint64) {
+	if err := redis.RemovePostIndex(postID); err != nil {
+		zap.L().Error("redis.RemovePostIndex() failed", zap.Int64("post_id", int64(postID)), zap.Error(err))
+	}
+}
+
+// searchPostList 搜索标题和内容，结果带高亮片段
+// 参数:
+//   - p: 查询参数，search为关键词，可按社区、标签过滤，order为relevance时按相关度排序
+//
+// 返回值:
+//   - *models.PostDetailRes: 帖子详情列表和分页信息，total为命中的帖子数
+//   - error: 可能的错误
+func searchPostList(p *models.ParamPostList) (*models.PostDetailRes, error) {
+	terms := search.QueryTerms(p.Search)
+	if len(terms) == 0 {
+		return &models.PostDetailRes{
+			Page: models.Page{Page: p.Page, Size: p.Size},
+			List: make([]*models.PostDetail, 0),
+		}, nil
+	}
+	res, err := getPostListCommon(func(p *models.ParamPostList) (*models.PostIDPage, error) {
+		return redis.SearchPostIDs(terms, p)
+	}, p)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]*models.PostDetail, 0, len(res.List))
+	for _, d := range res.List {
+		// 复制一份再填充高亮，原对象可能正在异步写入缓存
+		hl := *d
+		hl.Highlight = &models.SearchHighlight{
+			Title:   search.Highlight(d.Title, terms, 0),
+			Content: search.Highlight(postText(d.Post), terms, searchSnippetWidth),
+		}
+		data = append(data, &hl)
+	}
+	res.List = data
+	return res, nil
+}
+
+// RebuildSearchIndex 按数据库中已发布的帖子重建搜索索引
+// 返回值:
+//   - int: 写入索引的帖子数
+//   - error: 可能的错误
+func RebuildSearchIndex() (int, error) {
+	var afterID uint64
+	count := 0
+	for {
+		posts, err := mysql.GetPublishedPostsAfter(afterID, searchReindexBatch)
+		if err != nil {
+			return count, err
+		}
+		if len(posts) == 0 {
+			return count, nil
+		}
+		for _, p := range posts {
+			indexPost(p)
+		}
+		count += len(posts)
+		afterID = posts[len(posts)-1].PostID
+	}
+}
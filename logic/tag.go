@@ -0,0 +1,229 @@
+package logic
+
+import (
+	"errors"
+	"land/dao/mysql"
+	"land/dao/redis"
+	"land/models"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+const (
+	maxTagsPerPost      = 5   // 每个帖子最多的标签数
+	maxTagLength        = 32  // 标签最长字符数
+	defaultSuggestLimit = 10  // 自动补全默认返回的数量
+	tagSuggestCandidate = 100 // 自动补全时按前缀取出的候选标签数
+
+	tagAllowedSymbols = "_+#." // 除文字和数字外允许的字符
+)
+
+var (
+	ErrorInvalidTag  = errors.New("标签只能包含文字、数字和_+#.，最长32个字符")
+	ErrorTooManyTags = errors.New("每个帖子最多5个标签")
+	ErrorTagNotExist = errors.New("标签不存在")
+)
+
+// normalizeTag 规范化标签：去掉开头的#，转为小写，连续的空白和-合并为一个-
+// 参数:
+//   - s: 用户输入的标签
+//
+// 返回值:
+//   - string: 规范化后的标签
+//   - bool: 是否合法
+func normalizeTag(s string) (string, bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "#")
+	var b strings.Builder
+	sep := false
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case unicode.IsSpace(r) || r == '-':
+			sep = true
+			continue
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune(tagAllowedSymbols, r):
+		default:
+			return "", false
+		}
+		if sep && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		sep = false
+		b.WriteRune(r)
+	}
+	name := b.String()
+	if name == "" || utf8.RuneCountInString(name) > maxTagLength {
+		return "", false
+	}
+	return name, true
+}
+
+// normalizeTags 规范化并去重，保持原有顺序
+// 参数:
+//   - tags: 用户输入的标签，nil表示未指定
+//
+// 返回值:
+//   - []string: 规范化后的标签，输入为nil时返回nil
+//   - error: 标签不合法返回ErrorInvalidTag，超过数量限制返回ErrorTooManyTags
+func normalizeTags(tags []string) ([]string, error) {
+	if tags == nil {
+		return nil, nil
+	}
+	names := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		name, ok := normalizeTag(t)
+		if !ok {
+			return nil, ErrorInvalidTag
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	if len(names) > maxTagsPerPost {
+		return nil, ErrorTooManyTags
+	}
+	return names, nil
+}
+
+// fillPostTags 批量填充帖子的标签，查询失败时只记录日志
+func fillPostTags(posts ...*models.Post) {
+	if len(posts) == 0 {
+		return
+	}
+	ids := make([]uint64, 0, len(posts))
+	for _, p := range posts {
+		ids = append(ids, p.PostID)
+	}
+	tags, err := mysql.GetTagsByPostIDs(ids)
+	if err != nil {
+		zap.L().Error("mysql.GetTagsByPostIDs() failed", zap.Error(err))
+		return
+	}
+	for _, p := range posts {
+		p.Tags = tags[p.PostID]
+		if p.Tags == nil {
+			p.Tags = make([]string, 0)
+		}
+	}
+}
+
+// getVotedPostTags 获取用户投过票的帖子的标签，撤回投票时同步调整各标签的分数
+func getVotedPostTags(votes []*models.UserVote) (map[string][]string, error) {
+	ids := make([]uint64, 0, len(votes))
+	for _, v := range votes {
+		if id, err := strconv.ParseUint(v.PostID, 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	tags, err := mysql.GetTagsByPostIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string][]string, len(tags))
+	for id, names := range tags {
+		res[strconv.FormatUint(id, 10)] = names
+	}
+	return res, nil
+}
+
+// updatePostTags 替换已发布帖子的标签并调整各标签的排序
+// 参数:
+//   - postID: 帖子ID
+//   - communityID: 帖子所在社区ID
+//   - tags: 规范化后的标签
+//
+// 返回值:
+//   - error: 可能的错误
+func updatePostTags(postID, communityID uint64, tags []string) error {
+	added, removed, err := mysql.ReplacePostTags(postID, tags)
+	if err != nil {
+		return err
+	}
+	if len(added) == 0 && len(removed) == 0 {
+		return nil
+	}
+	// 数据库已生效，Redis调整失败只记录日志
+	if err = redis.UpdatePostTags(postID, communityID, added, removed); err != nil {
+		zap.L().Error("redis.UpdatePostTags() failed", zap.Int64("post_id", int64(postID)), zap.Error(err))
+	}
+	return nil
+}
+
+// GetTagPostList 获取标签下的帖子列表
+// 参数:
+//   - name: 标签名，会先规范化
+//   - p: 分页与排序参数
+//
+// 返回值:
+//   - *models.PostDetailRes: 帖子列表及分页信息，total为标签下已发布的帖子数
+//   - error: 标签不存在时返回ErrorTagNotExist，游标无效时返回ErrorInvalidCursor
+func GetTagPostList(name string, p *models.ParamPostList) (*models.PostDetailRes, error) {
+	tag, ok := normalizeTag(name)
+	if !ok {
+		return nil, ErrorTagNotExist
+	}
+	exists, err := mysql.TagExists(tag)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrorTagNotExist
+	}
+	p.Tag = tag
+	if p.Order == models.OrderRelevance {
+		p.Order = models.OrderTime
+	}
+	if err = parseCursor(p); err != nil {
+		return nil, err
+	}
+	return getPostListCommon(redis.GetTagPostIDsInOrder, p)
+}
+
+// SuggestTags 标签自动补全，按前缀匹配，已发布帖子多的标签在前
+// 参数:
+//   - p: 前缀与返回数量
+//
+// 返回值:
+//   - []*models.TagCount: 标签及其已发布的帖子数，不含没有已发布帖子的标签
+//   - error: 可能的错误
+func SuggestTags(p *models.ParamTagSuggest) ([]*models.TagCount, error) {
+	res := make([]*models.TagCount, 0)
+	prefix, ok := normalizeTag(p.Prefix)
+	if !ok {
+		return res, nil
+	}
+	limit := p.Limit
+	if limit <= 0 {
+		limit = defaultSuggestLimit
+	}
+
+	names, err := mysql.SearchTags(prefix, tagSuggestCandidate)
+	if err != nil || len(names) == 0 {
+		return res, err
+	}
+	// 帖子数以Redis中标签的排序集合为准，只统计已发布的帖子
+	counts, err := redis.CountTagPosts(names)
+	if err != nil {
+		return nil, err
+	}
+	for i, name := range names {
+		if counts[i] > 0 {
+			res = append(res, &models.TagCount{Name: name, PostCount: counts[i]})
+		}
+	}
+	// 候选已按名称排序，稳定排序保证帖子数相同时按名称排列
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].PostCount > res[j].PostCount
+	})
+	if len(res) > limit {
+		res = res[:limit]
+	}
+	return res, nil
+}
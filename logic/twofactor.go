@@ -0,0 +1,375 @@
+package logic
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"land/dao/mysql"
+	"land/dao/redis"
+	"land/models"
+	"land/pkg/jwt"
+	"land/pkg/totp"
+	"land/settings"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	recoveryCodeCount = 10 // 每次生成的恢复码数量
+	recoveryCodeBytes = 5  // 单个恢复码的随机字节数，编码后为8个字符
+)
+
+var (
+	// ErrorInvalidOTP 验证码或恢复码错误
+	ErrorInvalidOTP = errors.New("验证码错误")
+
+	// ErrorTwoFactorEnabled 已开启两步验证，不能重复绑定
+	ErrorTwoFactorEnabled = errors.New("已开启两步验证")
+
+	// ErrorTwoFactorNotEnabled 未开启两步验证
+	ErrorTwoFactorNotEnabled = errors.New("未开启两步验证")
+
+	// ErrorChallengeInvalid 登录挑战无效、已过期或尝试次数耗尽
+	ErrorChallengeInvalid = errors.New("无效的登录挑战")
+)
+
+// TwoFactorEnabled 判断用户是否已开启两步验证
+// 参数:
+//   - userID: 用户ID
+//
+// 返回值:
+//   - bool: 是否已开启
+//   - error: 可能的错误
+func TwoFactorEnabled(userID uint64) (bool, error) {
+	tf, err := mysql.GetTwoFactor(userID)
+	if err != nil {
+		if errors.Is(err, mysql.ErrorTwoFactorNotEnrolled) {
+			return false, nil
+		}
+		return false, err
+	}
+	return tf.Enabled, nil
+}
+
+// GetTwoFactorStatus 获取两步验证状态
+// 参数:
+//   - userID: 用户ID
+//
+// 返回值:
+//   - *models.TwoFactorStatus: 是否开启及剩余恢复码数量
+//   - error: 可能的错误
+func GetTwoFactorStatus(userID uint64) (*models.TwoFactorStatus, error) {
+	enabled, err := TwoFactorEnabled(userID)
+	if err != nil {
+		return nil, err
+	}
+	status := &models.TwoFactorStatus{Enabled: enabled}
+	if !enabled {
+		return status, nil
+	}
+	status.RemainingRecoveryCodes, err = mysql.CountUnusedRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// EnrollTwoFactor 生成新的TOTP密钥，待用户用验证码确认后才真正启用
+// 参数:
+//   - userID: 用户ID
+//
+// 返回值:
+//   - *models.TwoFactorEnrollment: 密钥与otpauth链接
+//   - error: 已开启时返回ErrorTwoFactorEnabled
+func EnrollTwoFactor(userID uint64) (*models.TwoFactorEnrollment, error) {
+	user, err := mysql.GetUserById(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.UserID == 0 {
+		return nil, mysql.ErrorUserNotExist
+	}
+
+	enabled, err := TwoFactorEnabled(userID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, ErrorTwoFactorEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err = mysql.SaveTwoFactorSecret(userID, secret); err != nil {
+		return nil, err
+	}
+
+	return &models.TwoFactorEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(settings.Conf.Name, user.Username, secret),
+	}, nil
+}
+
+// ConfirmTwoFactor 用认证器生成的验证码确认绑定，成功后启用两步验证并返回恢复码
+// 恢复码只在这里明文返回一次
+// 参数:
+//   - userID: 用户ID
+//   - code: 认证器验证码
+//
+// 返回值:
+//   - []string: 恢复码
+//   - error: 验证码错误时返回ErrorInvalidOTP
+func ConfirmTwoFactor(userID uint64, code string) ([]string, error) {
+	tf, err := mysql.GetTwoFactor(userID)
+	if err != nil {
+		if errors.Is(err, mysql.ErrorTwoFactorNotEnrolled) {
+			return nil, ErrorTwoFactorNotEnabled
+		}
+		return nil, err
+	}
+	if tf.Enabled {
+		return nil, ErrorTwoFactorEnabled
+	}
+
+	if ok, err := checkTOTP(tf, code); err != nil || !ok {
+		if err != nil {
+			return nil, err
+		}
+		return nil, ErrorInvalidOTP
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err = mysql.EnableTwoFactor(userID, hashes); err != nil {
+		return nil, err
+	}
+	zap.L().Info("Two-factor authentication enabled", zap.Int64("user_id", int64(userID)))
+	return codes, nil
+}
+
+// DisableTwoFactor 关闭两步验证，需要提供有效的验证码或恢复码
+// 参数:
+//   - userID: 用户ID
+//   - code: 认证器验证码或恢复码
+//   - ip: 客户端IP，验证码错误时计入防爆破计数
+//
+// 返回值:
+//   - error: 验证码错误时返回ErrorInvalidOTP，被锁定时为*LoginLockedError
+func DisableTwoFactor(userID uint64, code, ip string) error {
+	tf, err := getEnabledTwoFactor(userID)
+	if err != nil {
+		return err
+	}
+	if err = checkTwoFactorCode(tf, code, ip); err != nil {
+		return err
+	}
+	if err = mysql.DisableTwoFactor(userID); err != nil {
+		return err
+	}
+	zap.L().Info("Two-factor authentication disabled", zap.Int64("user_id", int64(userID)))
+	return nil
+}
+
+// RegenerateRecoveryCodes 作废旧恢复码并生成新的一组，需要提供有效的验证码
+// 参数:
+//   - userID: 用户ID
+//   - code: 认证器验证码或恢复码
+//   - ip: 客户端IP，验证码错误时计入防爆破计数
+//
+// 返回值:
+//   - []string: 新的恢复码
+//   - error: 验证码错误时返回ErrorInvalidOTP，被锁定时为*LoginLockedError
+func RegenerateRecoveryCodes(userID uint64, code, ip string) ([]string, error) {
+	tf, err := getEnabledTwoFactor(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkTwoFactorCode(tf, code, ip); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err = mysql.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// CreateLoginChallenge 密码校验通过后为开启两步验证的用户创建短期登录挑战
+// 参数:
+//   - user: 登录用户
+//   - device: 登录设备名称
+//
+// 返回值:
+//   - *models.LoginChallenge: 挑战令牌
+//   - error: 两步验证被锁定时为*LoginLockedError
+func CreateLoginChallenge(user *models.User, device string) (*models.LoginChallenge, error) {
+	// 验证码错误次数过多时不再发放新挑战
+	if err := checkTwoFactorLock(user.UserID, ""); err != nil {
+		return nil, err
+	}
+	token, err := jwt.GenOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	if err = redis.SaveTwoFactorChallenge(jwt.HashToken(token), user.UserID, device); err != nil {
+		return nil, err
+	}
+	return &models.LoginChallenge{
+		TwoFactorRequired: true,
+		ChallengeToken:    token,
+		ExpiresIn:         int64(redis.TwoFactorChallengeTTL.Seconds()),
+	}, nil
+}
+
+// CompleteTwoFactorLogin 校验登录挑战与验证码，通过后签发令牌
+// 参数:
+//   - p: 挑战令牌与验证码
+//   - ip: 客户端IP
+//   - userAgent: 客户端User-Agent
+//
+// 返回值:
+//   - *models.User: 登录用户
+//   - *models.TokenPair: 令牌对
+//   - error: ErrorChallengeInvalid、ErrorInvalidOTP，被锁定时为*LoginLockedError
+func CompleteTwoFactorLogin(p *models.ParamTwoFactorLogin, ip, userAgent string) (*models.User, *models.TokenPair, error) {
+	challengeHash := jwt.HashToken(p.ChallengeToken)
+	userID, device, err := redis.GetTwoFactorChallenge(challengeHash)
+	if err != nil {
+		if errors.Is(err, redis.ErrChallengeNotFound) {
+			return nil, nil, ErrorChallengeInvalid
+		}
+		return nil, nil, err
+	}
+
+	tf, err := getEnabledTwoFactor(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = checkTwoFactorCode(tf, p.Code, ip); err != nil {
+		return nil, nil, err
+	}
+
+	// 挑战只能完成一次，并发提交同一挑战时只有删除成功的一方继续
+	deleted, err := redis.DeleteTwoFactorChallenge(challengeHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !deleted {
+		return nil, nil, ErrorChallengeInvalid
+	}
+
+	user, err := mysql.GetUserById(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user.UserID == 0 {
+		return nil, nil, mysql.ErrorUserNotExist
+	}
+
+	tokens, err := IssueTokenPair(user, device, ip, userAgent)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, tokens, nil
+}
+
+// getEnabledTwoFactor 获取已启用的两步验证配置
+func getEnabledTwoFactor(userID uint64) (*models.UserTwoFactor, error) {
+	tf, err := mysql.GetTwoFactor(userID)
+	if err != nil {
+		if errors.Is(err, mysql.ErrorTwoFactorNotEnrolled) {
+			return nil, ErrorTwoFactorNotEnabled
+		}
+		return nil, err
+	}
+	if !tf.Enabled {
+		return nil, ErrorTwoFactorNotEnabled
+	}
+	return tf, nil
+}
+
+// checkTwoFactorCode 校验验证码并计入防爆破计数：锁定期间直接拒绝，错误时按用户和IP各记一次失败，通过后清零用户的计数
+// 登录、关闭两步验证和重新生成恢复码共用，持有会话也不能无限次猜测验证码
+func checkTwoFactorCode(tf *models.UserTwoFactor, code, ip string) error {
+	if err := checkTwoFactorLock(tf.UserID, ip); err != nil {
+		return err
+	}
+	if err := verifyTwoFactorCode(tf, code); err != nil {
+		if errors.Is(err, ErrorInvalidOTP) {
+			if lockErr := recordTwoFactorFailure(tf.UserID, ip); lockErr != nil {
+				return lockErr
+			}
+		}
+		return err
+	}
+	if err := redis.ResetLoginFailures(models.LockTypeTwoFactor, strconv.FormatUint(tf.UserID, 10)); err != nil {
+		zap.L().Error("redis.ResetLoginFailures() failed", zap.Error(err))
+	}
+	return nil
+}
+
+// verifyTwoFactorCode 校验认证器验证码，不是6位数字时按恢复码处理
+func verifyTwoFactorCode(tf *models.UserTwoFactor, code string) error {
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		ok, err := checkTOTP(tf, code)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrorInvalidOTP
+		}
+		return nil
+	}
+
+	ok, err := mysql.UseRecoveryCode(tf.UserID, jwt.HashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrorInvalidOTP
+	}
+	zap.L().Info("Recovery code used", zap.Int64("user_id", int64(tf.UserID)))
+	return nil
+}
+
+// checkTOTP 校验验证码，同一时间步的验证码只能使用一次
+func checkTOTP(tf *models.UserTwoFactor, code string) (bool, error) {
+	step, ok := totp.Validate(tf.Secret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+	return redis.MarkTOTPStepUsed(tf.UserID, step)
+}
+
+// generateRecoveryCodes 生成恢复码，返回明文（xxxx-xxxx格式）与对应哈希
+func generateRecoveryCodes() (codes []string, hashes []string, err error) {
+	enc := base32.StdEncoding.WithPadding(base32.NoPadding)
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, recoveryCodeBytes)
+		if _, err = rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		raw := strings.ToLower(enc.EncodeToString(b))
+		codes = append(codes, raw[:4]+"-"+raw[4:])
+		hashes = append(hashes, jwt.HashToken(raw))
+	}
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode 去掉分隔符与空白并转为小写，便于用户手动输入
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -1,16 +1,15 @@
 package logic
 
 import (
-	"crypto/md5"
-	"encoding/hex"
+	"errors"
 	"land/dao/mysql"
+	"land/dao/redis"
 	"land/models"
-	"land/pkg/jwt"
+	"land/pkg/password"
 	"land/pkg/snowflake"
-)
+	"land/settings"
 
-const (
-	secret = "0d000721"
+	"go.uber.org/zap"
 )
 
 // SignUp 处理用户注册逻辑
@@ -28,54 +27,92 @@ func SignUp(p *models.SignUpForm) error {
 
 	id := snowflake.GetID()
 
+	hash, err := password.Hash(p.Password)
+	if err != nil {
+		return err
+	}
+
 	// 注册用户
 	user := models.User{
 		UserID:   id,
 		Username: p.UserName,
-		Password: encryptPassword(p.Password),
+		Password: hash,
 		Email:    p.Email,
 	}
 
-	return mysql.InsertUser(&user)
+	if err = mysql.InsertUser(&user); err != nil {
+		return err
+	}
+
+	// 新用户处于未验证状态，发送验证邮件失败不影响注册，可稍后重发
+	if err = SendVerificationEmail(&user); err != nil {
+		zap.L().Error("SendVerificationEmail() failed", zap.Int64("user_id", int64(user.UserID)), zap.Error(err))
+	}
+	return nil
 }
 
 // Login 处理用户登录逻辑
 // 参数:
 //   - p: 用户登录参数
+//   - ip: 客户端IP，用于按IP统计失败次数
 //
 // 返回值:
 //   - *models.User: 登录成功的用户信息
-//   - error: 可能发生的错误
-func Login(p *models.LoginForm) (user *models.User, err error) {
-	if ok := mysql.CheckUserExist(p.UserName); !ok {
-		return nil, mysql.ErrorUserNotExist
+//   - error: 可能发生的错误，被锁定时为*LoginLockedError
+func Login(p *models.LoginForm, ip string) (user *models.User, err error) {
+	// 用户名或IP处于锁定期内，直接拒绝，不再校验密码
+	if err = checkLoginLock(p.UserName, ip); err != nil {
+		return nil, err
 	}
 
-	user = &models.User{
-		Username: p.UserName,
-		Password: encryptPassword(p.Password),
+	user, err = mysql.GetUserByUsername(p.UserName)
+	if err != nil {
+		if errors.Is(err, mysql.ErrorUserNotExist) {
+			if lockErr := recordLoginFailure(p.UserName, ip); lockErr != nil {
+				return nil, lockErr
+			}
+		}
+		return nil, err
 	}
 
 	// 验证用户登录信息
-	if err := mysql.Login(user); err != nil {
+	ok, needRehash, err := password.Verify(p.Password, user.Password, settings.Conf.Secret)
+	if err != nil {
 		return nil, err
 	}
-	// 生成JWT令牌
-	token, err := jwt.GenToken(int64(user.UserID), user.Username)
-	if err == nil {
-		user.Token = token
+	if !ok {
+		if lockErr := recordLoginFailure(p.UserName, ip); lockErr != nil {
+			return nil, lockErr
+		}
+		return nil, mysql.ErrorInvalidPassword
 	}
-	return
+
+	// 登录成功清零该用户名的失败计数，IP维度保留，防止用一个账号洗白IP
+	if err := redis.ResetLoginFailures(models.LockTypeUser, p.UserName); err != nil {
+		zap.L().Error("redis.ResetLoginFailures() failed", zap.Error(err))
+	}
+
+	// 旧格式哈希在登录成功后透明升级
+	if needRehash {
+		upgradePassword(user.UserID, p.Password)
+	}
+
+	return user, nil
 }
 
-// encryptPassword 密码加密
+// upgradePassword 将密码重新哈希为当前格式，失败只记录日志不影响登录
 // 参数:
-//   - oPassword: 原始密码
-//
-// 返回值:
-//   - 加密后的密码
-func encryptPassword(oPassword string) string {
-	h := md5.New()
-	h.Write([]byte(secret))
-	return hex.EncodeToString(h.Sum([]byte(oPassword)))
+//   - userID: 用户ID
+//   - plain: 原始密码
+func upgradePassword(userID uint64, plain string) {
+	hash, err := password.Hash(plain)
+	if err != nil {
+		zap.L().Error("password.Hash() failed", zap.Int64("user_id", int64(userID)), zap.Error(err))
+		return
+	}
+	if err := mysql.UpdateUserPassword(userID, hash); err != nil {
+		zap.L().Error("mysql.UpdateUserPassword() failed", zap.Int64("user_id", int64(userID)), zap.Error(err))
+		return
+	}
+	zap.L().Info("Password hash upgraded", zap.Int64("user_id", int64(userID)))
 }
@@ -1,6 +1,7 @@
 package logic
 
 import (
+	"land/dao/mysql"
 	"land/dao/redis"
 	"land/models"
 	"strconv"
@@ -9,12 +10,13 @@ import (
 )
 
 // VoteForPost 处理用户对帖子的投票
+// 投票期限和各排序策略的参数按帖子所在社区的配置
 // 参数：
 //   - id: 用户ID
 //   - p: 投票参数
 //
 // 返回值：
-//   - error: 可能发生的错误
+//   - error: 帖子不存在返回mysql.ErrorInvalidID，其余见redis.VoteForPost
 func VoteForPost(id uint64, p *models.ParamVoteData) error {
 	// 记录调试日志
 	zap.L().Debug("VoteForPost",
@@ -22,6 +24,26 @@ func VoteForPost(id uint64, p *models.ParamVoteData) error {
 		zap.String("postID", p.PostID),
 		zap.Int8("direction", p.Direction))
 
+	pid, err := strconv.ParseUint(p.PostID, 10, 64)
+	if err != nil {
+		return mysql.ErrorInvalidID
+	}
+	post, err := mysql.GetPostByID(pid)
+	if err != nil {
+		return err
+	}
+
+	// 帖子的标签各自维护分数排序，投票时一并调整
+	tags, err := mysql.GetPostTags(pid)
+	if err != nil {
+		zap.L().Error("mysql.GetPostTags() failed", zap.String("post_id", p.PostID), zap.Error(err))
+	}
+
 	// 调用Redis处理投票
-	return redis.VoteForPost(strconv.Itoa(int(id)), p.PostID, float64(p.Direction))
+	voteWindow := getCommunityRanking(post.CommunityID).voteWindow
+	if err = redis.VoteForPost(strconv.Itoa(int(id)), p.PostID, float64(p.Direction), tags, voteWindow); err != nil {
+		return err
+	}
+	refreshPostRanks(post)
+	return nil
 }
@@ -7,6 +7,9 @@ import (
 	_ "land/docs" // swag init生成的docs包
 	"land/logger"
 	"land/logic"
+	"land/pkg/jwt"
+	"land/pkg/mail"
+	"land/pkg/oidc"
 	"land/pkg/snowflake"
 	"land/routers"
 	"land/settings"
@@ -47,6 +50,21 @@ func main() {
 	}
 	defer redis.Close()
 
+	if err := mail.Init(settings.Conf.MailConfig); err != nil {
+		fmt.Printf("init mail failed,err : %v\n", err)
+		return
+	}
+
+	if err := jwt.InitKeyRing(settings.Conf.AuthConfig); err != nil {
+		fmt.Printf("init jwt key ring failed,err : %v\n", err)
+		return
+	}
+
+	if err := oidc.Init(settings.Conf.OIDCProviders); err != nil {
+		fmt.Printf("init oidc failed,err : %v\n", err)
+		return
+	}
+
 	if err := snowflake.Init("2024-06-07", 1); err != nil {
 		fmt.Printf("init snowflake failed,err : %v\n", err)
 		return
@@ -57,6 +75,26 @@ func main() {
 	syncService.Start()
 	defer syncService.Stop()
 
+	// 启动签名密钥轮换服务
+	keyService := logic.NewKeyRotationService(10 * time.Minute) // 每10分钟检查一次
+	keyService.Start()
+	defer keyService.Stop()
+
+	// 启动注销用户清除服务
+	purgeService := logic.NewAccountPurgeService(time.Hour) // 每小时检查一次
+	purgeService.Start()
+	defer purgeService.Stop()
+
+	// 启动已删除帖子清除服务
+	postPurgeService := logic.NewPostPurgeService(time.Hour) // 每小时检查一次
+	postPurgeService.Start()
+	defer postPurgeService.Stop()
+
+	// 启动定时发布服务，定时信息保存在MySQL中，重启后补发到期的帖子
+	publishService := logic.NewPostPublishService(30 * time.Second) // 每30秒检查一次
+	publishService.Start()
+	defer publishService.Stop()
+
 	// 启动路由
 	r := routers.SetRouter(settings.Conf.Mode)
 
@@ -1,58 +1,95 @@
 package middlewares
 
 import (
+	"errors"
 	"land/controllers"
 	"land/dao/redis"
+	"land/logic"
 	"land/pkg/jwt"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
-// JWTAuth 基于JWT的认证中间件
+// JWTAuth 基于JWT的认证中间件，未携带或携带无效凭证时拒绝请求
 func JWTAuth() func(c *gin.Context) {
 	return func(c *gin.Context) {
-		// 客户端携带Token有三种方式：
-		// 1. 放在请求头（Header）
-		// 2. 放在请求体（Body）
-		// 3. 放在URI
-		// 这里假设Token放在Header的Authorization中，并使用Bearer开头
-		// 例如：Authorization: Bearer xxxxxxx.xxx.xxx 或 X-TOKEN: xxx.xxx.xx
-		// 注意：具体实现方式应根据实际业务情况决定
-		authHeader := c.Request.Header.Get("Authorization")
-		if authHeader == "" {
-			// 如果请求头中没有Authorization字段，则返回需要登录的错误
-			controllers.ResError(c, controllers.CodeNeedLogin)
+		if code := authenticate(c); code != controllers.CodeSuccess {
+			controllers.ResError(c, code)
 			c.Abort()
 			return
 		}
 
-		// 按空格分割Authorization字段的值
-		parts := strings.SplitN(authHeader, " ", 2)
+		// 继续处理请求
+		c.Next()
+		// 注意：在后续的处理请求的函数中，可以通过c.Get(ContextUserIDKey)来获取当前请求的用户信息
+	}
+}
 
-		// parts[1]是获取到的tokenString，使用之前定义好的ParseToken函数来解析它
-		mc, err := jwt.ParseToken(parts[1])
+// OptionalAuth 可选认证中间件，用于游客也能访问的公开接口
+// 携带有效凭证时与JWTAuth一样写入当前用户，未携带或凭证无效时按游客处理，不拒绝请求
+func OptionalAuth() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		authenticate(c)
+		c.Next()
+	}
+}
 
-		if err != nil {
-			// 如果token解析失败，则返回无效的token错误
-			controllers.ResError(c, controllers.CodeInvalidToken)
-			c.Abort()
-			return
-		}
+// authenticate 校验Authorization头中的凭证，通过后将用户信息写入上下文
+// 返回值:
+//   - controllers.ResCode: 成功返回CodeSuccess，未携带凭证返回CodeNeedLogin，凭证无效返回CodeInvalidToken
+func authenticate(c *gin.Context) controllers.ResCode {
+	// 客户端携带Token有三种方式：
+	// 1. 放在请求头（Header）
+	// 2. 放在请求体（Body）
+	// 3. 放在URI
+	// 这里假设Token放在Header的Authorization中，并使用Bearer开头
+	// 例如：Authorization: Bearer xxxxxxx.xxx.xxx 或 X-TOKEN: xxx.xxx.xx
+	// 注意：具体实现方式应根据实际业务情况决定
+	authHeader := c.Request.Header.Get("Authorization")
+	if authHeader == "" {
+		// 如果请求头中没有Authorization字段，则返回需要登录的错误
+		return controllers.CodeNeedLogin
+	}
 
-		// 新增：从Redis校验token是否有效存在
-		redisToken, err := redis.GetJWTToken(mc.UserID)
-		if err != nil || redisToken != parts[1] {
-			controllers.ResError(c, controllers.CodeInvalidToken)
-			c.Abort()
-			return
+	// 按空格分割Authorization字段的值
+	parts := strings.SplitN(authHeader, " ", 2)
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return controllers.CodeInvalidToken
+	}
+
+	// 个人访问令牌不绑定会话，按令牌自身的权限范围授权
+	if logic.IsAccessToken(parts[1]) {
+		auth, err := logic.AuthenticateAccessToken(parts[1], c.ClientIP())
+		if err != nil {
+			if !errors.Is(err, logic.ErrorAccessTokenInvalid) {
+				zap.L().Error("logic.AuthenticateAccessToken() failed", zap.Error(err))
+			}
+			return controllers.CodeInvalidToken
 		}
+		c.Set(controllers.ContextUserIDKey, auth.UserID)
+		c.Set(controllers.ContextRolesKey, auth.Roles)
+		c.Set(controllers.ContextScopesKey, auth.Scopes)
+		return controllers.CodeSuccess
+	}
 
-		// 将当前请求的userID信息保存到请求的上下文c中
-		c.Set(controllers.ContextUserIDKey, mc.UserID)
+	// parts[1]是获取到的tokenString，使用之前定义好的ParseToken函数来解析它
+	mc, err := jwt.ParseToken(parts[1])
+	if err != nil {
+		// 如果token解析失败，则返回无效的token错误
+		return controllers.CodeInvalidToken
+	}
 
-		// 继续处理请求
-		c.Next()
-		// 注意：在后续的处理请求的函数中，可以通过c.Get(ContextUserIDKey)来获取当前请求的用户信息
+	// 从Redis校验会话是否有效：会话被吊销（登出、踢下线或refresh token重复使用）后，其access token立即失效
+	ok, err := redis.TouchSession(uint64(mc.UserID), mc.SessionID, c.ClientIP())
+	if err != nil || !ok {
+		return controllers.CodeInvalidToken
 	}
+
+	// 将当前请求的userID信息保存到请求的上下文c中
+	c.Set(controllers.ContextUserIDKey, uint64(mc.UserID))
+	c.Set(controllers.ContextSessionIDKey, mc.SessionID)
+	c.Set(controllers.ContextRolesKey, mc.Roles)
+	return controllers.CodeSuccess
 }
@@ -0,0 +1,20 @@
+package middlewares
+
+import (
+	"land/controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole 角色校验中间件，需放在JWTAuth之后
+// 当前用户拥有roles中任一角色即可放行
+func RequireRole(roles ...string) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		if !controllers.HasRole(c, roles...) {
+			controllers.ResError(c, controllers.CodeUnauthorized)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,20 @@
+package middlewares
+
+import (
+	"land/controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireScope 权限范围校验中间件，需放在JWTAuth之后
+// 登录会话直接放行；个人访问令牌需拥有scopes中任一权限范围，不传scopes表示只允许登录会话访问
+func RequireScope(scopes ...string) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		if !controllers.HasScope(c, scopes...) {
+			controllers.ResError(c, controllers.CodeInsufficientScope)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,36 @@
+package middlewares
+
+import (
+	"land/controllers"
+	"land/logic"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RequireVerifiedEmail 邮箱验证策略中间件，需放在JWTAuth之后
+// 开启auth.require_verified_email时，未验证邮箱的用户不能执行被保护的操作
+func RequireVerifiedEmail() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		userID, err := controllers.GetCurrentUserID(c)
+		if err != nil {
+			controllers.ResError(c, controllers.CodeNeedLogin)
+			c.Abort()
+			return
+		}
+
+		ok, err := logic.CheckEmailVerified(userID)
+		if err != nil {
+			zap.L().Error("logic.CheckEmailVerified() failed", zap.Error(err))
+			controllers.ResError(c, controllers.CodeServerBusy)
+			c.Abort()
+			return
+		}
+		if !ok {
+			controllers.ResError(c, controllers.CodeEmailNotVerified)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// 个人访问令牌的权限范围
+const (
+	ScopeRead      = "read"       // 读取社区、帖子、评论
+	ScopePostWrite = "post:write" // 发帖、编辑帖子、评论
+	ScopeVote      = "vote"       // 投票
+	ScopeAdminSync = "admin:sync" // 访问量同步等运维接口，需管理员角色
+)
+
+// AccessTokenScopes 全部可申请的权限范围
+var AccessTokenScopes = []string{ScopeRead, ScopePostWrite, ScopeVote, ScopeAdminSync}
+
+// AccessToken 个人访问令牌，供脚本和第三方集成调用API，只保存令牌哈希
+type AccessToken struct {
+	ID           uint64     `json:"id,string"`
+	UserID       uint64     `json:"-"`
+	Name         string     `json:"name"`
+	TokenHash    string     `json:"-"`
+	Scopes       string     `json:"scopes"`      // 以空格分隔的权限范围
+	ExpireTime   *time.Time `json:"expire_time"` // 为空表示永不过期
+	LastUsedTime *time.Time `json:"last_used_time"`
+	LastUsedIP   string     `json:"last_used_ip"`
+	CreateTime   time.Time  `json:"create_time"`
+}
+
+func (t *AccessToken) TableName() string {
+	return "access_token"
+}
+
+// AccessTokenAuth 个人访问令牌认证通过后的身份信息
+type AccessTokenAuth struct {
+	TokenID uint64
+	UserID  uint64
+	Scopes  []string
+	Roles   []string
+}
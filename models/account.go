@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// AnonymizedContent 注销用户的帖子与评论被替换成的内容
+const AnonymizedContent = "[deleted]"
+
+// UserVote 用户的一条投票记录
+type UserVote struct {
+	PostID    string `json:"post_id"`
+	Direction int8   `json:"direction"` // 1赞成，-1反对
+}
+
+// UserDataExport 个人数据导出
+type UserDataExport struct {
+	ExportTime time.Time       `json:"export_time"`
+	Profile    *UserProfile    `json:"profile"`
+	Identities []*UserIdentity `json:"identities"`
+	Posts      []*Post         `json:"posts"`
+	Comments   []*Comment      `json:"comments"`
+	Votes      []*UserVote     `json:"votes"`
+}
+
+// AccountDeletion 注销结果
+type AccountDeletion struct {
+	PurgeTime time.Time `json:"purge_time"` // 个人信息彻底删除的时间
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// 用户关系类型
+const (
+	RelationBlock uint8 = 1 // 拉黑：对方不能评论我的帖子、回复我的评论或@我，双方的内容互不可见
+	RelationMute  uint8 = 2 // 屏蔽：只是不再看到对方的帖子和评论，对方不受影响
+)
+
+// UserBlock 拉黑/屏蔽关系，user对target生效
+type UserBlock struct {
+	ID         uint64    `json:"-"`
+	UserID     uint64    `json:"-"`
+	TargetID   uint64    `json:"target_id,string"`
+	Kind       uint8     `json:"kind"`
+	CreateTime time.Time `json:"create_time"`
+}
+
+func (b *UserBlock) TableName() string {
+	return "user_block"
+}
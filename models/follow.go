@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// UserFollow 关注关系，follower关注followee
+type UserFollow struct {
+	ID         uint64    `json:"-"`
+	FollowerID uint64    `json:"follower_id,string"`
+	FolloweeID uint64    `json:"followee_id,string"`
+	CreateTime time.Time `json:"create_time"`
+}
+
+func (f *UserFollow) TableName() string {
+	return "user_follow"
+}
+
+// UserBrief 用户列表中展示的简要信息
+type UserBrief struct {
+	UserID      uint64 `json:"user_id,string"`
+	Username    string `json:"username"`
+	DisplayName string `json:"display_name"`
+	Avatar      string `json:"avatar"`
+}
+
+// UserListRes 用户分页列表
+type UserListRes struct {
+	Page Page         `json:"page"`
+	List []*UserBrief `json:"list"`
+}
+
+// FollowCounts 关注数与粉丝数
+type FollowCounts struct {
+	Followers int64 `json:"followers"`
+	Following int64 `json:"following"`
+}
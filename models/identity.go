@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// UserIdentity 第三方登录身份，将提供方的subject映射到本站用户
+type UserIdentity struct {
+	ID         uint64    `json:"-"`
+	UserID     uint64    `json:"-"`
+	Provider   string    `json:"provider"` // 提供方名称，对应配置中的oidc.name
+	Subject    string    `json:"subject"`  // 提供方内的用户唯一标识（sub）
+	Email      string    `json:"email"`    // 绑定时提供方返回的邮箱
+	CreateTime time.Time `json:"create_time"`
+}
+
+func (i *UserIdentity) TableName() string {
+	return "user_identity"
+}
+
+// OIDCAuthState 发起第三方登录时保存的状态，回调时取回
+type OIDCAuthState struct {
+	Provider   string // 提供方名称
+	Verifier   string // PKCE code_verifier
+	Nonce      string // ID Token中的nonce
+	Device     string // 登录设备名称
+	LinkUserID uint64 // 非0时表示已登录用户绑定账号，而不是登录
+}
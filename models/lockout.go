@@ -0,0 +1,15 @@
+package models
+
+const (
+	LockTypeUser      = "user" // 按用户名锁定
+	LockTypeIP        = "ip"   // 按客户端IP锁定
+	LockTypeTwoFactor = "2fa"  // 按用户ID锁定两步验证
+)
+
+// LoginLock 登录锁定记录，供管理员查看
+type LoginLock struct {
+	Type     string `json:"type"`      // 锁定维度：user/ip/2fa
+	Target   string `json:"target"`    // 用户名、IP或用户ID
+	Failures int64  `json:"failures"`  // 当前窗口内的失败次数
+	ExpireIn int64  `json:"expire_in"` // 剩余锁定秒数
+}
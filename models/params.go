@@ -1,11 +1,24 @@
 package models
 
+import "time"
+
 const (
 	OrderTime  = "time"
 	OrderScore = "score"
 	OrderView  = "view" // 按访问量排序
+
+	OrderRelevance = "relevance" // 按搜索相关度排序，只在搜索时有效
+
+	// 以下排序按投票计算，策略见 pkg/ranking
+	OrderHot           = "hot"           // 热门：票数取对数并随发布时间衰减
+	OrderBest          = "best"          // 最佳：赞成比例的Wilson置信区间下限
+	OrderControversial = "controversial" // 争议：赞成与反对票接近且票数多
+	OrderTop           = "top"           // 最多赞：净票数
 )
 
+// RankOrders 按排序策略计算分数的排序方式，每种在Redis中维护一个分数有序集合
+var RankOrders = []string{OrderHot, OrderBest, OrderControversial, OrderTop}
+
 // type RegisterForm struct {
 // 	Username        string `form:"username" binding:"required"`
 // 	Password        string `form:"password" binding:"required"`
@@ -16,15 +29,80 @@ const (
 // }
 
 type SignUpForm struct {
-	UserName   string `json:"username" binding:"required"`
+	UserName   string `json:"username" binding:"required,username"`
 	Password   string `json:"password" binding:"required"`
 	RePassword string `json:"re_password" binding:"required,eqfield=Password"`
-	Email      string `json:"email"`
+	Email      string `json:"email" binding:"required,email"`
 }
 
 type LoginForm struct {
 	UserName string `form:"username" binding:"required"`
 	Password string `form:"password" binding:"required"`
+	Device   string `json:"device" form:"device"` // 设备名，可选，用于会话列表展示
+}
+
+type ParamRefreshToken struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// 授予角色参数
+type ParamRole struct {
+	Role string `json:"role" binding:"required,oneof=admin moderator"`
+}
+
+// 申请重置密码参数
+type ParamForgotPassword struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// 重置密码参数
+type ParamResetPassword struct {
+	Token      string `json:"token" binding:"required"`
+	Password   string `json:"password" binding:"required"`
+	RePassword string `json:"re_password" binding:"required,eqfield=Password"`
+}
+
+// 解除登录锁定参数
+type ParamUnlockLogin struct {
+	Type   string `json:"type" binding:"required,oneof=user ip 2fa"` // 锁定维度
+	Target string `json:"target" binding:"required"`                 // 用户名、IP或用户ID
+}
+
+// 两步验证码参数，code可以是认证器验证码或恢复码
+type ParamTwoFactorCode struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// 两步验证登录参数
+type ParamTwoFactorLogin struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+// 第三方登录回调参数
+type ParamOIDCCallback struct {
+	Code  string `form:"code" binding:"required"`
+	State string `form:"state" binding:"required"`
+}
+
+// 创建个人访问令牌参数
+type ParamCreateAccessToken struct {
+	Name       string   `json:"name" binding:"required,max=64"`
+	Scopes     []string `json:"scopes" binding:"required,min=1,dive,oneof=read post:write vote admin:sync"`
+	ExpireDays int      `json:"expire_days" binding:"omitempty,min=1,max=365"` // 有效天数，不传表示永不过期
+}
+
+// 修改个人资料参数，未传的字段保持不变
+type ParamUpdateProfile struct {
+	DisplayName *string `json:"display_name" binding:"omitempty,max=32"`
+	Bio         *string `json:"bio" binding:"omitempty,max=256"`
+	Gender      *uint8  `json:"gender" binding:"omitempty,oneof=0 1 2"`
+	Avatar      *string `json:"avatar" binding:"omitempty,max=512"` // http(s)图片地址，传空字符串清除头像
+}
+
+// 注销账号参数，设置过密码的账号需要再次输入密码
+type ParamDeleteAccount struct {
+	Password string `json:"password"`
 }
 
 type ParamVoteData struct {
@@ -35,17 +113,60 @@ type ParamVoteData struct {
 // 获取帖子列表参数
 type ParamPostList struct {
 	CommunityID uint64 `json:"community_id" form:"community_id"`
-	Page        int64  `json:"page" form:"page" binding:"min=1"`                   // 页码，最小为1
-	Size        int64  `json:"size" form:"size" binding:"min=1,max=100"`           // 每页大小，1-100
-	Order       string `json:"order" form:"order" binding:"oneof=time score view"` // 排序方式：time(时间), score(分数), view(访问量)
-	Search      string `json:"search" form:"search"`
-	UseIndex    bool   `json:"use_index" form:"use_index"` // 是否使用MySQL索引优化（默认true）
+	Page        int64  `json:"page" form:"page" binding:"min=1"`                                                        // 页码，最小为1
+	Size        int64  `json:"size" form:"size" binding:"min=1,max=100"`                                                // 每页大小，1-100
+	Order       string `json:"order" form:"order" binding:"oneof=time score view relevance hot best controversial top"` // 排序方式：time(时间), score(分数), view(访问量), relevance(相关度)，以及hot/best/controversial/top
+	Search      string `json:"search" form:"search" binding:"max=100"`                                                  // 搜索关键词，匹配标题和内容
+	Tag         string `json:"tag" form:"tag"`                                                                          // 按标签过滤
+	Cursor      string `json:"cursor" form:"cursor"`                                                                    // 上一页返回的next_cursor，指定后忽略page
+	UseIndex    bool   `json:"use_index" form:"use_index"`                                                              // 是否使用MySQL索引优化（默认true）
+
+	ViewerID uint64      `json:"-" form:"-"` // 当前用户ID，游客为0，用于过滤拉黑/屏蔽的作者
+	After    *PostCursor `json:"-" form:"-"` // 由cursor解析出的位置
+}
+
+// 创建帖子参数，draft为true时保存为草稿，指定publish_time时定时发布
+type ParamCreatePost struct {
+	Title       string     `json:"title" binding:"required"`
+	Content     string     `json:"content" binding:"required,max=65535"`
+	CommunityID uint64     `json:"community_id" binding:"required"`
+	Draft       bool       `json:"draft"`
+	PublishTime *time.Time `json:"publish_time"` // RFC3339格式，必须晚于当前时间
+	Tags        []string   `json:"tags"`         // 标签，最多5个
+}
+
+// 修改草稿参数，指定publish_time时转为定时发布，否则保存为草稿
+type ParamUpdateDraft struct {
+	Title       string     `json:"title" binding:"required"`
+	Content     string     `json:"content" binding:"required,max=65535"`
+	CommunityID uint64     `json:"community_id" binding:"required"`
+	PublishTime *time.Time `json:"publish_time"`
+	Tags        []string   `json:"tags"` // 不传时保持不变，传空数组时清空
+}
+
+// 标签自动补全参数
+type ParamTagSuggest struct {
+	Prefix string `form:"q" binding:"required,max=32"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=20"` // 默认10
+}
+
+// Markdown预览参数
+type ParamPreview struct {
+	Content string `json:"content" binding:"required,max=65535"`
 }
 
 // 更新帖子参数
 type UpdatePostForm struct {
-	PostID      uint64 `json:"post_id" binding:"required"`
-	Title       string `json:"title" binding:"required"`
-	Content     string `json:"content" binding:"required"`
-	CommunityID uint64 `json:"community_id" binding:"required"`
+	PostID      uint64   `json:"post_id" binding:"required"`
+	Title       string   `json:"title" binding:"required"`
+	Content     string   `json:"content" binding:"required,max=65535"`
+	CommunityID uint64   `json:"community_id" binding:"required"`
+	Reason      string   `json:"reason" binding:"max=200"` // 编辑原因，记入修订记录
+	Tags        []string `json:"tags"`                     // 不传时保持不变，传空数组时清空
+}
+
+// 比较修订版本参数
+type ParamCompareRevision struct {
+	From int `form:"from" binding:"required,min=1"`
+	To   int `form:"to" binding:"required,min=1"`
 }
@@ -2,17 +2,32 @@ package models
 
 import "time"
 
+// 帖子状态
+const (
+	PostStatusPublished uint8 = 0 // 正常
+	PostStatusDeleted   uint8 = 1 // 已删除，保留期满后彻底删除
+	PostStatusDraft     uint8 = 2 // 草稿，只有作者可见
+	PostStatusScheduled uint8 = 3 // 定时发布，到期后由发布服务发布
+)
+
 type Post struct {
-	ID          uint64    `json:"id"`
-	PostID      uint64    `json:"post_id"`
-	AuthorID    uint64    `json:"author_id"`
-	CommunityID uint64    `json:"community_id"`
-	Title       string    `json:"title"`
-	Content     string    `json:"content"`
-	Status      uint8     `json:"status"`
-	ViewCount   int64     `json:"view_count"` // 访问量
-	CreateTime  time.Time `json:"create_time"`
-	UpdateTime  time.Time `json:"update_time"`
+	ID          uint64     `json:"id"`
+	PostID      uint64     `json:"post_id"`
+	AuthorID    uint64     `json:"author_id"`
+	CommunityID uint64     `json:"community_id"`
+	Title       string     `json:"title"`
+	Content     string     `json:"content"`      // Markdown原文
+	ContentHTML string     `json:"content_html"` // 渲染并过滤后的HTML
+	Status      uint8      `json:"status"`
+	ViewCount   int64      `json:"view_count"` // 访问量
+	CreateTime  time.Time  `json:"create_time"`
+	UpdateTime  time.Time  `json:"update_time"`
+	EditTime    *time.Time `json:"edit_time,omitempty"`    // 最后编辑时间，未编辑过为空
+	PublishTime *time.Time `json:"publish_time,omitempty"` // 定时发布时间，发布后为实际发布时间
+	Tags        []string   `json:"tags" gorm:"-"`          // 标签，保存在post_tag表
+
+	DeleteTime *time.Time `json:"-"` // 删除时间
+	DeleteBy   uint64     `json:"-"` // 删除人，作者只能恢复自己删除的帖子
 }
 
 func (p *Post) TableName() string {
@@ -25,6 +40,14 @@ type PostDetail struct {
 	VoteNum          int64              `json:"vote_num"`
 	*Post                               // 嵌入帖子基本信息
 	*CommunityDetail `json:"community"` // 嵌入社区信息
+
+	Highlight *SearchHighlight `json:"highlight,omitempty"` // 搜索时的高亮片段
+}
+
+// 搜索结果的高亮片段，命中部分用<em>包裹，其余内容已转义
+type SearchHighlight struct {
+	Title   string `json:"title"`
+	Content string `json:"content"`
 }
 
 type Page struct {
@@ -34,6 +57,27 @@ type Page struct {
 }
 
 type PostDetailRes struct {
-	Page Page          `json:"page"`
-	List []*PostDetail `json:"list"`
+	Page       Page          `json:"page"`
+	List       []*PostDetail `json:"list"`
+	NextCursor string        `json:"next_cursor,omitempty"` // 支持游标分页的列表返回，没有更多时为空
+}
+
+// 游标分页的位置：排序键与帖子ID，翻页时从该位置之后开始
+// 排序键为发布时间戳、访问量、分数或搜索相关度，与排序方式对应
+type PostCursor struct {
+	Sort   float64
+	PostID uint64
+}
+
+// 按排序集合分页查询帖子ID的结果
+type PostIDPage struct {
+	IDs   []string
+	Next  *PostCursor // 下一页的游标位置，不足一页时为nil
+	Total int64       // 集合中的帖子总数
+}
+
+// 草稿与定时帖子列表
+type DraftListRes struct {
+	Page Page    `json:"page"`
+	List []*Post `json:"list"`
 }
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// 帖子修订记录，每次编辑写入一版，第一次编辑时补记原始内容作为第1版
+type PostRevision struct {
+	ID          uint64    `json:"-"`
+	PostID      uint64    `json:"post_id"`
+	Version     int       `json:"version"` // 版本号，从1开始递增
+	Title       string    `json:"title"`
+	Content     string    `json:"content"`
+	CommunityID uint64    `json:"community_id"`
+	EditorID    uint64    `json:"editor_id"`
+	EditorName  string    `json:"editor_name" gorm:"-"`
+	Reason      string    `json:"reason"` // 编辑原因
+	CreateTime  time.Time `json:"create_time"`
+}
+
+func (r *PostRevision) TableName() string {
+	return "post_revision"
+}
+
+// 修订记录分页列表
+type PostRevisionListRes struct {
+	Page Page            `json:"page"`
+	List []*PostRevision `json:"list"`
+}
+
+// 按行比较结果中的一行，op为equal/insert/delete
+type DiffLine struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// 两个版本的比较结果
+type PostRevisionDiff struct {
+	PostID          uint64      `json:"post_id"`
+	From            int         `json:"from"`
+	To              int         `json:"to"`
+	FromCommunityID uint64      `json:"from_community_id"`
+	ToCommunityID   uint64      `json:"to_community_id"`
+	Title           []*DiffLine `json:"title"`
+	Content         []*DiffLine `json:"content"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+const (
+	RoleAdmin     = "admin"     // 管理员：可调用全部管理接口并授予/回收角色
+	RoleModerator = "moderator" // 版主：可管理帖子缓存等内容相关操作
+	RoleUser      = "user"      // 普通用户：所有登录用户默认拥有，不落库
+)
+
+// UserRole 用户角色关联表，一个用户可拥有多个角色
+type UserRole struct {
+	ID         uint64    `json:"-"`
+	UserID     uint64    `json:"user_id"`
+	Role       string    `json:"role"`
+	CreateTime time.Time `json:"create_time"`
+}
+
+func (r *UserRole) TableName() string {
+	return "user_role"
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// Session 登录会话，每次登录产生一个，refresh token轮换时沿用同一会话
+type Session struct {
+	SessionID  string    `json:"session_id"`
+	UserID     uint64    `json:"-"`
+	DeviceName string    `json:"device_name"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	CreateTime time.Time `json:"create_time"`
+	LastSeen   time.Time `json:"last_seen"`
+	Current    bool      `json:"current"` // 是否为发起请求的会话
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Tag 标签，名称为规范化后的结果
+type Tag struct {
+	ID         uint64    `json:"-"`
+	Name       string    `json:"name"`
+	CreateTime time.Time `json:"-"`
+}
+
+func (t *Tag) TableName() string {
+	return "tag"
+}
+
+// PostTag 帖子与标签的关联，按ID顺序即为作者填写的顺序
+type PostTag struct {
+	ID         uint64    `json:"-"`
+	PostID     uint64    `json:"post_id"`
+	TagID      uint64    `json:"tag_id"`
+	CreateTime time.Time `json:"create_time"`
+}
+
+func (t *PostTag) TableName() string {
+	return "post_tag"
+}
+
+// TagCount 标签及其已发布帖子数
+type TagCount struct {
+	Name      string `json:"name"`
+	PostCount int64  `json:"post_count"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// UserTwoFactor 用户两步验证（TOTP）配置
+type UserTwoFactor struct {
+	ID         uint64    `json:"-"`
+	UserID     uint64    `json:"user_id"`
+	Secret     string    `json:"-"`       // Base32密钥
+	Enabled    bool      `json:"enabled"` // 确认绑定后才启用
+	CreateTime time.Time `json:"create_time"`
+	UpdateTime time.Time `json:"update_time"`
+}
+
+func (t *UserTwoFactor) TableName() string {
+	return "user_two_factor"
+}
+
+// RecoveryCode 两步验证恢复码，只保存哈希，每个只能使用一次
+type RecoveryCode struct {
+	ID         uint64     `json:"-"`
+	UserID     uint64     `json:"-"`
+	CodeHash   string     `json:"-"`
+	Used       bool       `json:"used"`
+	CreateTime time.Time  `json:"create_time"`
+	UsedTime   *time.Time `json:"used_time"`
+}
+
+func (r *RecoveryCode) TableName() string {
+	return "user_recovery_code"
+}
+
+// TwoFactorEnrollment 绑定认证器时返回的信息
+type TwoFactorEnrollment struct {
+	Secret          string `json:"secret"`           // 手动输入用的密钥
+	ProvisioningURI string `json:"provisioning_uri"` // 扫码用的otpauth链接
+}
+
+// TwoFactorStatus 两步验证状态
+type TwoFactorStatus struct {
+	Enabled                bool  `json:"enabled"`
+	RemainingRecoveryCodes int64 `json:"remaining_recovery_codes"`
+}
+
+// LoginChallenge 开启两步验证的用户密码校验通过后返回的挑战
+type LoginChallenge struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	ChallengeToken    string `json:"challenge_token"`
+	ExpiresIn         int64  `json:"expires_in"`
+}
@@ -1,13 +1,61 @@
 package models
 
+import "time"
+
+// 性别
+const (
+	GenderUnknown uint8 = iota
+	GenderMale
+	GenderFemale
+)
+
+// UsernameCharset 用户名允许的字符（正则字符类内容）：字母、数字、下划线和连字符
+// 注册校验、第三方登录生成用户名与@提及都使用该字符集，保证每个用户都能被完整地@到
+const UsernameCharset = `\p{L}\p{N}_\-`
+
 type User struct {
 	UserID   uint64 `json:"user_id"`  // 用户ID
 	Username string `json:"username"` // 用户名
-	Password string `json:"password"` // 密码
+	Password string `json:"-"`        // 密码哈希，任何接口都不返回
 	Email    string `json:"email"`    // 邮箱
-	Token    string `gorm:"-"`        // 用户令牌
+
+	EmailVerified bool `json:"email_verified"` // 邮箱是否已验证
+
+	DisplayName string    `json:"display_name"` // 昵称，为空时展示用户名
+	Bio         string    `json:"bio"`          // 个人简介
+	Gender      uint8     `json:"gender"`       // 性别：0未知，1男，2女
+	Avatar      string    `json:"avatar"`       // 头像地址
+	CreateTime  time.Time `json:"create_time"`  // 注册时间
+	UpdateTime  time.Time `json:"update_time"`  // 资料更新时间
+
+	DeleteTime *time.Time `json:"-"` // 注销时间，宽限期满后删除整行
 }
 
 func (u *User) TableName() string {
 	return "user"
 }
+
+// TokenPair 登录/刷新后下发的令牌对
+type TokenPair struct {
+	AccessToken  string `json:"token"`         // 短期access token（JWT）
+	RefreshToken string `json:"refresh_token"` // 不透明的refresh token
+	ExpiresIn    int64  `json:"expires_in"`    // access token有效期（秒）
+}
+
+// UserProfile 用户公开资料
+// 邮箱只在查看自己的资料时返回
+type UserProfile struct {
+	UserID         uint64    `json:"user_id,string"`
+	Username       string    `json:"username"`
+	DisplayName    string    `json:"display_name"`
+	Bio            string    `json:"bio"`
+	Gender         uint8     `json:"gender"`
+	Avatar         string    `json:"avatar"`
+	PostCount      int64     `json:"post_count"`
+	CommentCount   int64     `json:"comment_count"`
+	FollowerCount  int64     `json:"follower_count"`
+	FollowingCount int64     `json:"following_count"`
+	CreateTime     time.Time `json:"create_time"`
+	Email          string    `json:"email,omitempty"`
+	EmailVerified  *bool     `json:"email_verified,omitempty"`
+}
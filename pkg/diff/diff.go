@@ -0,0 +1,154 @@
+package diff
+
+import "strings"
+
+// 基于Myers算法的按行比较，输出最短编辑脚本
+
+// Op 行的编辑操作
+type Op string
+
+const (
+	OpEqual  Op = "equal"  // 两个版本都有
+	OpInsert Op = "insert" // 只在新版本中
+	OpDelete Op = "delete" // 只在旧版本中
+)
+
+// Line 比较结果中的一行
+type Line struct {
+	Op   Op
+	Text string
+}
+
+// Lines 按行比较两段文本
+// 参数：
+//   - a: 旧文本
+//   - b: 新文本
+//
+// 返回：
+//   - []Line: 按顺序排列的比较结果，两段文本都为空时返回空切片
+func Lines(a, b string) []Line {
+	return diff(splitLines(a), splitLines(b))
+}
+
+// splitLines 拆分为行，统一换行符，忽略末尾的换行
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// maxEditDistance 编辑距离上限，超过时不再求最短编辑脚本
+// 保存的回溯记录随编辑距离平方增长，限制后最多占用几MB内存
+const maxEditDistance = 1000
+
+// diff 计算a到b的最短编辑脚本
+// 先去掉相同的开头和结尾，中间部分编辑距离超过maxEditDistance时整体按删除旧行、插入新行输出
+func diff(a, b []string) []Line {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	lines := make([]Line, 0, len(a)+len(b)-prefix-suffix)
+	for _, s := range a[:prefix] {
+		lines = append(lines, Line{Op: OpEqual, Text: s})
+	}
+	ma, mb := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if middle := myers(ma, mb); middle != nil {
+		lines = append(lines, middle...)
+	} else {
+		for _, s := range ma {
+			lines = append(lines, Line{Op: OpDelete, Text: s})
+		}
+		for _, s := range mb {
+			lines = append(lines, Line{Op: OpInsert, Text: s})
+		}
+	}
+	for _, s := range a[len(a)-suffix:] {
+		lines = append(lines, Line{Op: OpEqual, Text: s})
+	}
+	return lines
+}
+
+// myers 用Myers算法计算a到b的最短编辑脚本，编辑距离超过maxEditDistance时返回nil
+// v[offset+k]记录第k条对角线上走得最远的x，第d轮开始前保存对角线-d-1到d+1的部分用于回溯
+func myers(a, b []string) []Line {
+	n, m := len(a), len(b)
+	max := n + m
+	if max == 0 {
+		return []Line{}
+	}
+	if max > maxEditDistance {
+		max = maxEditDistance
+	}
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	trace := make([][]int, 0, 8)
+
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // 向下：插入b中的一行
+			} else {
+				x = v[offset+k-1] + 1 // 向右：删除a中的一行
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(a, b, trace)
+			}
+		}
+	}
+	return nil
+}
+
+// backtrack 从终点沿保存的记录倒推编辑路径，trace[d][d+1+k]为第d轮开始前第k条对角线的x
+func backtrack(a, b []string, trace [][]int) []Line {
+	x, y := len(a), len(b)
+	lines := make([]Line, 0, x+y)
+
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		offset := d + 1
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			lines = append(lines, Line{Op: OpEqual, Text: a[x-1]})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				lines = append(lines, Line{Op: OpInsert, Text: b[y-1]})
+			} else {
+				lines = append(lines, Line{Op: OpDelete, Text: a[x-1]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+		lines[i], lines[j] = lines[j], lines[i]
+	}
+	return lines
+}
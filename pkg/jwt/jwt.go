@@ -1,16 +1,29 @@
 package jwt
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"land/settings"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
-	"github.com/spf13/viper"
 )
 
-// TokenExpiredDuration 定义了token的过期时间，设置为30天
-const TokenExpiredDuration = time.Hour * 24 * 30
+const (
+	// DefaultAccessExpire 未配置auth.jwt_expire时access token的过期时间
+	DefaultAccessExpire = 15 * time.Minute
+
+	// DefaultRefreshExpire 未配置auth.refresh_expire时refresh token的过期时间
+	DefaultRefreshExpire = 30 * 24 * time.Hour
+
+	refreshTokenBytes = 32 // refresh token随机字节数
+
+	audienceEmailVerify = "email_verify" // 邮箱验证令牌的受众
+)
 
 // mySecret 是用于签名的密钥
 // var mySecret = []byte("0d000721")
@@ -20,34 +33,59 @@ const TokenExpiredDuration = time.Hour * 24 * 30
 // 我们这里需要额外记录UserID和Username字段，所以要自定义结构体
 // 如果想要保存更多信息，都可以添加到这个结构体中
 type MyClaims struct {
-	UserID   int64  `json:"user_id"`
-	Username string `json:"username"`
+	UserID    int64    `json:"user_id"`
+	Username  string   `json:"username"`
+	SessionID string   `json:"sid"`             // 所属登录会话，会话吊销后token随之失效
+	Roles     []string `json:"roles,omitempty"` // 用户角色
 	jwt.StandardClaims
 }
 
-// GenToken 生成JWT
+// AccessExpire 获取access token的有效期
+func AccessExpire() time.Duration {
+	if settings.Conf.AuthConfig == nil || settings.Conf.Expire <= 0 {
+		return DefaultAccessExpire
+	}
+	return time.Duration(settings.Conf.Expire) * time.Second
+}
+
+// RefreshExpire 获取refresh token的有效期
+func RefreshExpire() time.Duration {
+	if settings.Conf.AuthConfig == nil || settings.Conf.RefreshExpire <= 0 {
+		return DefaultRefreshExpire
+	}
+	return time.Duration(settings.Conf.RefreshExpire) * time.Second
+}
+
+// GenToken 生成短期有效的access token（JWT）
 // 参数：
 //   - userID: 用户ID
 //   - username: 用户名
+//   - sessionID: 登录会话ID
+//   - roles: 用户角色
 //
 // 返回：
 //   - string: 生成的token字符串
 //   - error: 可能发生的错误
-func GenToken(userID int64, username string) (string, error) {
+func GenToken(userID int64, username, sessionID string, roles []string) (string, error) {
 	// 创建一个我们自己的声明数据
 	c := MyClaims{
 		userID,
 		username, // 自定义字段
+		sessionID,
+		roles,
 		jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(
-				time.Duration(viper.GetInt("auth.jwt_expire")) * time.Hour).Unix(), // 过期时间
-			Issuer: "jesse", // 签发人
+			ExpiresAt: time.Now().Add(AccessExpire()).Unix(), // 过期时间
+			Issuer:    "jesse",                               // 签发人
 		},
 	}
-	// 使用指定的签名方法创建签名对象
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, c)
-	// 使用指定的secret签名并获得完整的编码后的字符串token
-	return token.SignedString([]byte(settings.Conf.Secret))
+	// 使用密钥环中的当前密钥签名，kid写入头部供验证方选择公钥
+	key, err := ring.signer()
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(signingMethod(key.Alg), c)
+	token.Header["kid"] = key.Kid
+	return token.SignedString(key.Private)
 }
 
 // ParseToken 解析JWT
@@ -61,13 +99,113 @@ func ParseToken(tokenString string) (*MyClaims, error) {
 	// 解析token
 	var mc = new(MyClaims)
 	token, err := jwt.ParseWithClaims(tokenString, mc, func(token *jwt.Token) (i interface{}, err error) {
+		kid, _ := token.Header["kid"].(string)
+		key, err := ring.verifier(kid)
+		if err != nil {
+			return nil, err
+		}
+		// 算法必须与密钥一致，拒绝HS256等对称算法与alg篡改
+		if token.Method.Alg() != key.Alg {
+			return nil, fmt.Errorf("unexpected signing method: %s", token.Method.Alg())
+		}
+		return key.Private.Public(), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid { // 校验token
+		return nil, errors.New("无效的token")
+	}
+	// 带受众的是其他用途的令牌（如邮箱验证），不能当作登录凭证
+	if mc.Audience != "" {
+		return nil, errors.New("无效的token")
+	}
+	return mc, nil
+}
+
+// signingMethod 根据算法名返回签名方法
+func signingMethod(alg string) jwt.SigningMethod {
+	if alg == AlgEdDSA {
+		return SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+// EmailClaims 邮箱验证令牌的声明
+// 邮箱验证令牌只由本服务签发和校验，仍使用auth.secret做HS256签名
+type EmailClaims struct {
+	UserID int64  `json:"user_id"`
+	Email  string `json:"email"`
+	jwt.StandardClaims
+}
+
+// GenEmailToken 生成邮箱验证链接中的签名令牌
+// 参数：
+//   - userID: 用户ID
+//   - email: 待验证的邮箱
+//   - expire: 有效期
+//
+// 返回：
+//   - string: 生成的token字符串
+//   - error: 可能发生的错误
+func GenEmailToken(userID int64, email string, expire time.Duration) (string, error) {
+	c := EmailClaims{
+		userID,
+		email,
+		jwt.StandardClaims{
+			Audience:  audienceEmailVerify,
+			ExpiresAt: time.Now().Add(expire).Unix(),
+			Issuer:    "jesse",
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, c)
+	return token.SignedString([]byte(settings.Conf.Secret))
+}
+
+// ParseEmailToken 解析邮箱验证令牌
+// 参数：
+//   - tokenString: 待解析的token字符串
+//
+// 返回：
+//   - *EmailClaims: 解析后的声明结构体指针
+//   - error: 签名错误、过期或受众不符时返回错误
+func ParseEmailToken(tokenString string) (*EmailClaims, error) {
+	var ec = new(EmailClaims)
+	token, err := jwt.ParseWithClaims(tokenString, ec, func(token *jwt.Token) (i interface{}, err error) {
+		// 邮件令牌用HMAC签名，拒绝alg篡改为其他算法
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %s", token.Method.Alg())
+		}
 		return []byte(settings.Conf.Secret), nil
 	})
 	if err != nil {
 		return nil, err
 	}
-	if token.Valid { // 校验token
-		return mc, nil
+	if !token.Valid || !ec.VerifyAudience(audienceEmailVerify, true) {
+		return nil, errors.New("无效的token")
+	}
+	return ec, nil
+}
+
+// GenOpaqueToken 生成不透明的随机令牌（refresh token、会话ID等）
+// 返回：
+//   - string: URL安全的随机字符串
+//   - error: 可能发生的错误
+func GenOpaqueToken() (string, error) {
+	b := make([]byte, refreshTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
 	}
-	return nil, errors.New("无效的token")
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken 计算不透明令牌的哈希，服务端只保存哈希
+// 参数：
+//   - token: 原始令牌
+//
+// 返回：
+//   - string: 十六进制SHA-256摘要
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
@@ -0,0 +1,399 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"land/settings"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// access token使用非对称密钥签名，验证方只需公钥（见/.well-known/jwks.json），无法自行签发
+// 密钥以PKCS#8 PEM保存在auth.key_dir下，文件名为kid；多实例部署时该目录需共享
+
+const (
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+
+	// DefaultKeyRotation 未配置auth.key_rotation时的密钥轮换周期
+	DefaultKeyRotation = 30 * 24 * time.Hour
+
+	defaultKeyDir    = "conf/keys"
+	rsaKeyBits       = 2048
+	keyReloadMinGap  = 10 * time.Second // 遇到未知kid时两次重新加载密钥目录的最小间隔
+	keySignerMaxAge  = time.Minute      // 签名前密钥目录的最长缓存时间，超过后重新加载以切换到其他实例生成的新密钥
+	keyRetireLeeway  = time.Minute      // 旧密钥在最后一个token过期后额外保留的时间
+	pemCreatedHeader = "Created"
+)
+
+var (
+	ErrUnknownKey = errors.New("未知的签名密钥")
+
+	ring = &keyRing{}
+)
+
+// signingKey 签名密钥
+type signingKey struct {
+	Kid     string
+	Alg     string
+	Private crypto.Signer
+	Created time.Time
+}
+
+// keyRing 签名密钥环，keys按创建时间升序排列，最后一个为当前签名密钥
+type keyRing struct {
+	mu         sync.RWMutex
+	dir        string
+	alg        string
+	rotation   time.Duration
+	keys       []*signingKey
+	lastReload time.Time
+}
+
+// JSONWebKey JWKS中的公钥
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JSONWebKeySet JWKS
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// InitKeyRing 加载签名密钥，目录为空或当前密钥需要轮换时生成新密钥
+// 参数：
+//   - cfg: 认证配置，使用signing_alg、key_dir、key_rotation
+//
+// 返回：
+//   - error: 可能发生的错误
+func InitKeyRing(cfg *settings.AuthConfig) error {
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+
+	ring.dir, ring.alg, ring.rotation = defaultKeyDir, AlgRS256, DefaultKeyRotation
+	if cfg != nil {
+		if cfg.KeyDir != "" {
+			ring.dir = cfg.KeyDir
+		}
+		if cfg.SigningAlg != "" {
+			ring.alg = cfg.SigningAlg
+		}
+		if cfg.KeyRotation > 0 {
+			ring.rotation = time.Duration(cfg.KeyRotation) * time.Second
+		}
+	}
+	if ring.alg != AlgRS256 && ring.alg != AlgEdDSA {
+		return fmt.Errorf("unsupported signing_alg: %s", ring.alg)
+	}
+	if err := os.MkdirAll(ring.dir, 0700); err != nil {
+		return err
+	}
+
+	if err := ring.reload(); err != nil {
+		return err
+	}
+	if _, err := ring.rotateIfDue(); err != nil {
+		return err
+	}
+
+	fmt.Println("jwt key ring init success, kid:", ring.current().Kid)
+	return nil
+}
+
+// RotateKeyIfDue 当前密钥超过轮换周期或算法与配置不一致时生成新密钥，并清理已过保留期的旧密钥
+// 返回：
+//   - string: 新密钥的kid，未轮换时为空
+//   - error: 可能发生的错误
+func RotateKeyIfDue() (string, error) {
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+
+	// 先加载其他实例可能已生成的新密钥
+	if err := ring.reload(); err != nil {
+		return "", err
+	}
+	kid, err := ring.rotateIfDue()
+	if err != nil {
+		return "", err
+	}
+	ring.prune()
+	return kid, nil
+}
+
+// RotateKey 立即生成新的签名密钥，旧密钥在其签发的token过期前仍可验签
+// 返回：
+//   - string: 新密钥的kid
+//   - error: 可能发生的错误
+func RotateKey() (string, error) {
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+
+	key, err := ring.generate()
+	if err != nil {
+		return "", err
+	}
+	zap.L().Info("jwt signing key rotated manually", zap.String("kid", key.Kid), zap.String("alg", key.Alg))
+	return key.Kid, nil
+}
+
+// JWKS 返回当前有效的全部公钥
+func JWKS() *JSONWebKeySet {
+	ring.mu.RLock()
+	defer ring.mu.RUnlock()
+
+	set := &JSONWebKeySet{Keys: make([]JSONWebKey, 0, len(ring.keys))}
+	for i := len(ring.keys) - 1; i >= 0; i-- {
+		set.Keys = append(set.Keys, ring.keys[i].jwk())
+	}
+	return set
+}
+
+// current 返回当前签名密钥，调用方需持有锁
+func (r *keyRing) current() *signingKey {
+	if len(r.keys) == 0 {
+		return nil
+	}
+	return r.keys[len(r.keys)-1]
+}
+
+// signer 返回当前签名密钥，密钥目录缓存超过keySignerMaxAge时先重新加载
+// 多实例共享密钥目录，其他实例轮换后本实例最多再用旧密钥签名keySignerMaxAge
+func (r *keyRing) signer() (*signingKey, error) {
+	r.mu.RLock()
+	key := r.current()
+	stale := time.Since(r.lastReload) > keySignerMaxAge
+	r.mu.RUnlock()
+
+	if stale {
+		r.mu.Lock()
+		if time.Since(r.lastReload) > keySignerMaxAge {
+			if err := r.reload(); err != nil {
+				zap.L().Error("reload jwt keys failed", zap.Error(err))
+			}
+		}
+		key = r.current()
+		r.mu.Unlock()
+	}
+	if key == nil {
+		return nil, errors.New("jwt key ring not initialized")
+	}
+	return key, nil
+}
+
+// verifier 根据kid查找验签密钥，未找到时重新加载一次密钥目录
+func (r *keyRing) verifier(kid string) (*signingKey, error) {
+	r.mu.RLock()
+	key := r.find(kid)
+	stale := time.Since(r.lastReload) > keyReloadMinGap
+	r.mu.RUnlock()
+	if key != nil {
+		return key, nil
+	}
+	if !stale || kid == "" {
+		return nil, ErrUnknownKey
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.reload(); err != nil {
+		zap.L().Error("reload jwt keys failed", zap.Error(err))
+	}
+	if key = r.find(kid); key == nil {
+		return nil, ErrUnknownKey
+	}
+	return key, nil
+}
+
+func (r *keyRing) find(kid string) *signingKey {
+	for _, k := range r.keys {
+		if k.Kid == kid {
+			return k
+		}
+	}
+	return nil
+}
+
+// rotateIfDue 调用方需持有写锁
+func (r *keyRing) rotateIfDue() (string, error) {
+	cur := r.current()
+	if cur != nil && cur.Alg == r.alg && time.Since(cur.Created) < r.rotation {
+		return "", nil
+	}
+	key, err := r.generate()
+	if err != nil {
+		return "", err
+	}
+	zap.L().Info("jwt signing key rotated", zap.String("kid", key.Kid), zap.String("alg", key.Alg))
+	return key.Kid, nil
+}
+
+// prune 删除已退役且其签发的token都已过期的密钥，调用方需持有写锁
+// 下一个密钥创建后，其他实例最多再用旧密钥签名keySignerMaxAge，之后再经过一个access token有效期全部过期
+func (r *keyRing) prune() {
+	retireAfter := keySignerMaxAge + AccessExpire() + keyRetireLeeway
+	keep := r.keys[:0]
+	for i, k := range r.keys {
+		if i < len(r.keys)-1 && time.Since(r.keys[i+1].Created) > retireAfter {
+			if err := os.Remove(filepath.Join(r.dir, k.Kid+".pem")); err != nil && !os.IsNotExist(err) {
+				zap.L().Error("remove retired jwt key failed", zap.String("kid", k.Kid), zap.Error(err))
+			}
+			zap.L().Info("jwt signing key retired", zap.String("kid", k.Kid))
+			continue
+		}
+		keep = append(keep, k)
+	}
+	r.keys = keep
+}
+
+// generate 生成新密钥并写入目录，调用方需持有写锁
+func (r *keyRing) generate() (*signingKey, error) {
+	var priv crypto.Signer
+	var err error
+	switch r.alg {
+	case AlgEdDSA:
+		_, priv, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		priv, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &signingKey{Alg: r.alg, Private: priv, Created: time.Now()}
+	key.Kid = thumbprint(key.jwk())
+
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return nil, err
+	}
+	block := &pem.Block{
+		Type:    "PRIVATE KEY",
+		Headers: map[string]string{pemCreatedHeader: key.Created.UTC().Format(time.RFC3339)},
+		Bytes:   der,
+	}
+	if err = os.WriteFile(filepath.Join(r.dir, key.Kid+".pem"), pem.EncodeToMemory(block), 0600); err != nil {
+		return nil, err
+	}
+
+	r.keys = append(r.keys, key)
+	return key, nil
+}
+
+// reload 从目录加载全部密钥，调用方需持有写锁
+func (r *keyRing) reload() error {
+	files, err := filepath.Glob(filepath.Join(r.dir, "*.pem"))
+	if err != nil {
+		return err
+	}
+
+	keys := make([]*signingKey, 0, len(files))
+	for _, f := range files {
+		key, err := loadKey(f)
+		if err != nil {
+			zap.L().Error("load jwt key failed", zap.String("file", f), zap.Error(err))
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Created.Before(keys[j].Created) })
+
+	r.keys, r.lastReload = keys, time.Now()
+	return nil
+}
+
+// loadKey 读取PKCS#8 PEM格式的私钥
+func loadKey(file string) (*signingKey, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid pem")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &signingKey{Kid: strings.TrimSuffix(filepath.Base(file), ".pem")}
+	switch priv := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Alg, key.Private = AlgRS256, priv
+	case ed25519.PrivateKey:
+		key.Alg, key.Private = AlgEdDSA, priv
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+
+	key.Created, err = time.Parse(time.RFC3339, block.Headers[pemCreatedHeader])
+	if err != nil {
+		info, statErr := os.Stat(file)
+		if statErr != nil {
+			return nil, statErr
+		}
+		key.Created = info.ModTime()
+	}
+	return key, nil
+}
+
+// jwk 返回公钥的JWK表示
+func (k *signingKey) jwk() JSONWebKey {
+	j := JSONWebKey{Kid: k.Kid, Use: "sig", Alg: k.Alg}
+	switch pub := k.Private.Public().(type) {
+	case *rsa.PublicKey:
+		j.Kty = "RSA"
+		j.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		j.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case ed25519.PublicKey:
+		j.Kty = "OKP"
+		j.Crv = "Ed25519"
+		j.X = base64.RawURLEncoding.EncodeToString(pub)
+	}
+	return j
+}
+
+// thumbprint 计算RFC 7638 JWK指纹作为kid
+func thumbprint(j JSONWebKey) string {
+	var members interface{}
+	switch j.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{j.E, j.Kty, j.N}
+	default:
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{j.Crv, j.Kty, j.X}
+	}
+	b, _ := json.Marshal(members)
+	sum := sha256.Sum256(b)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
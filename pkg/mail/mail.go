@@ -0,0 +1,65 @@
+package mail
+
+import (
+	"fmt"
+	"land/settings"
+)
+
+// Sender 邮件发送器，便于在SMTP、本地文件、日志等实现之间切换
+type Sender interface {
+	Send(to, subject, body string) error
+}
+
+// sender 当前使用的发送器
+var sender Sender
+
+// Init 根据配置初始化邮件发送器
+// 参数:
+//   - cfg: 邮件配置，driver可选smtp/file/log，未配置时使用log
+//
+// 返回:
+//   - error: 可能发生的错误
+func Init(cfg *settings.MailConfig) (err error) {
+	if cfg == nil {
+		sender = &LogSender{}
+		return nil
+	}
+
+	switch cfg.Driver {
+	case "smtp":
+		sender = &SMTPSender{
+			Host:     cfg.Host,
+			Port:     cfg.Port,
+			Username: cfg.Username,
+			Password: cfg.Password,
+			From:     cfg.From,
+		}
+	case "file":
+		sender, err = NewFileSender(cfg.Dir, cfg.From)
+	case "log", "":
+		sender = &LogSender{}
+	default:
+		return fmt.Errorf("unknown mail driver: %s", cfg.Driver)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("mail init success, driver:", cfg.Driver)
+	return nil
+}
+
+// Send 发送邮件
+// 参数:
+//   - to: 收件人地址
+//   - subject: 主题
+//   - body: 纯文本正文
+//
+// 返回:
+//   - error: 可能发生的错误
+func Send(to, subject, body string) error {
+	if sender == nil {
+		sender = &LogSender{}
+	}
+	return sender.Send(to, subject, body)
+}
@@ -0,0 +1,97 @@
+package mail
+
+import (
+	"fmt"
+	"mime"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// SMTPSender 通过SMTP服务器发送邮件
+type SMTPSender struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// Send 实现Sender接口
+func (s *SMTPSender) Send(to, subject, body string) error {
+	addr := fmt.Sprintf("%s:%d", s.Host, s.Port)
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+	return smtp.SendMail(addr, auth, s.From, []string{to}, buildMessage(s.From, to, subject, body))
+}
+
+// FileSender 将邮件写入本地目录，便于本地开发时查看
+type FileSender struct {
+	Dir  string
+	From string
+}
+
+// NewFileSender 创建文件发送器，目录不存在时自动创建
+// 参数:
+//   - dir: 邮件保存目录
+//   - from: 发件人地址
+//
+// 返回:
+//   - *FileSender: 文件发送器
+//   - error: 可能发生的错误
+func NewFileSender(dir, from string) (*FileSender, error) {
+	if dir == "" {
+		dir = "log/mail"
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileSender{Dir: dir, From: from}, nil
+}
+
+// Send 实现Sender接口，每封邮件保存为一个.eml文件
+func (s *FileSender) Send(to, subject, body string) error {
+	name := fmt.Sprintf("%s_%s.eml", time.Now().Format("20060102_150405.000000"), sanitizeFileName(to))
+	return os.WriteFile(filepath.Join(s.Dir, name), buildMessage(s.From, to, subject, body), 0o644)
+}
+
+// LogSender 只把邮件内容写入日志
+type LogSender struct{}
+
+// Send 实现Sender接口
+func (s *LogSender) Send(to, subject, body string) error {
+	zap.L().Info("mail sent to log",
+		zap.String("to", to),
+		zap.String("subject", subject),
+		zap.String("body", body))
+	return nil
+}
+
+// buildMessage 组装RFC 5322格式的纯文本邮件
+func buildMessage(from, to, subject, body string) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + to + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("UTF-8", subject) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(body)
+	return []byte(b.String())
+}
+
+// sanitizeFileName 把邮箱地址转换成安全的文件名
+func sanitizeFileName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r == '@' {
+			return '_'
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,42 @@
+package markdown
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+// 帖子与评论使用Markdown编写：CommonMark + GFM（表格、删除线、任务列表、自动链接）
+// 渲染结果再经过白名单过滤，原始HTML一律不输出
+
+var md = goldmark.New(
+	goldmark.WithExtensions(
+		extension.NewTable(extension.WithTableCellAlignMethod(extension.TableCellAlignAttribute)),
+		extension.Strikethrough,
+		extension.Linkify,
+		extension.TaskList,
+	),
+	goldmark.WithRendererOptions(
+		html.WithHardWraps(), // 单个换行也换行，符合论坛用户的习惯
+	),
+)
+
+// Render 将Markdown渲染为安全的HTML
+// 参数：
+//   - src: Markdown原文
+//
+// 返回：
+//   - string: 过滤后的HTML，原文为空时返回空字符串
+//   - error: 可能发生的错误
+func Render(src string) (string, error) {
+	if src == "" {
+		return "", nil
+	}
+	var buf bytes.Buffer
+	if err := md.Convert([]byte(src), &buf); err != nil {
+		return "", err
+	}
+	return Sanitize(buf.String()), nil
+}
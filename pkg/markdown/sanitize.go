@@ -0,0 +1,167 @@
+package markdown
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// allowedTags 允许输出的标签及其允许的属性
+var allowedTags = map[string]map[string]bool{
+	"p": {}, "br": {}, "hr": {},
+	"h1": {}, "h2": {}, "h3": {}, "h4": {}, "h5": {}, "h6": {},
+	"strong": {}, "em": {}, "del": {}, "code": {"class": true}, "pre": {},
+	"blockquote": {}, "ul": {}, "ol": {"start": true}, "li": {},
+	"a":     {"href": true, "title": true},
+	"img":   {"src": true, "alt": true, "title": true},
+	"table": {}, "thead": {}, "tbody": {}, "tr": {},
+	"th":    {"align": true},
+	"td":    {"align": true},
+	"input": {"type": true, "checked": true, "disabled": true},
+}
+
+// droppedTags 连同内容一起丢弃的标签
+var droppedTags = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true, "embed": true,
+	"textarea": true, "title": true, "noscript": true, "template": true, "svg": true, "math": true,
+}
+
+// voidTags 没有结束标签的元素
+var voidTags = map[string]bool{"br": true, "hr": true, "img": true, "input": true}
+
+var (
+	languageClass = regexp.MustCompile(`^language-[A-Za-z0-9_+#.-]+$`)
+	alignValue    = regexp.MustCompile(`^(left|center|right)$`)
+	startValue    = regexp.MustCompile(`^[0-9]{1,9}$`)
+)
+
+// Sanitize 按白名单过滤HTML
+// 不在白名单中的标签只保留文本，脚本等危险标签连同内容一起丢弃，
+// 链接只允许http/https/mailto和相对地址，并统一加上rel="nofollow ugc noopener"
+// 参数：
+//   - s: 待过滤的HTML
+//
+// 返回：
+//   - string: 过滤后的HTML
+func Sanitize(s string) string {
+	var b strings.Builder
+	z := html.NewTokenizer(strings.NewReader(s))
+	open := make([]string, 0, 16) // 已输出且未闭合的标签
+	skip := 0                     // 处于丢弃标签内部的层数
+
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		tok := z.Token()
+
+		switch tt {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if droppedTags[tok.Data] {
+				if tt == html.StartTagToken {
+					skip++
+				}
+				continue
+			}
+			attrs, ok := allowedTags[tok.Data]
+			if skip > 0 || !ok || (tok.Data == "input" && !isCheckbox(tok)) {
+				continue
+			}
+			b.WriteString(renderStartTag(tok, attrs))
+			if !voidTags[tok.Data] && tt == html.StartTagToken {
+				open = append(open, tok.Data)
+			}
+		case html.EndTagToken:
+			if droppedTags[tok.Data] {
+				if skip > 0 {
+					skip--
+				}
+				continue
+			}
+			if skip > 0 {
+				continue
+			}
+			// 只闭合确实打开过的标签，中间未闭合的一并闭合
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] != tok.Data {
+					continue
+				}
+				for j := len(open) - 1; j >= i; j-- {
+					b.WriteString("</" + open[j] + ">")
+				}
+				open = open[:i]
+				break
+			}
+		case html.TextToken:
+			if skip == 0 {
+				b.WriteString(html.EscapeString(tok.Data))
+			}
+		}
+		// 注释和DOCTYPE直接丢弃
+	}
+
+	for i := len(open) - 1; i >= 0; i-- {
+		b.WriteString("</" + open[i] + ">")
+	}
+	return b.String()
+}
+
+// isCheckbox 只允许任务列表的复选框
+func isCheckbox(tok html.Token) bool {
+	for _, attr := range tok.Attr {
+		if attr.Key == "type" {
+			return attr.Val == "checkbox"
+		}
+	}
+	return false
+}
+
+// renderStartTag 只输出允许的属性
+func renderStartTag(tok html.Token, allowed map[string]bool) string {
+	var b strings.Builder
+	b.WriteString("<" + tok.Data)
+	for _, attr := range tok.Attr {
+		if attr.Namespace != "" || !allowed[attr.Key] || !validAttr(tok.Data, attr.Key, attr.Val) {
+			continue
+		}
+		b.WriteString(" " + attr.Key + `="` + html.EscapeString(attr.Val) + `"`)
+	}
+	if tok.Data == "a" {
+		b.WriteString(` rel="nofollow ugc noopener"`)
+	}
+	b.WriteString(">")
+	return b.String()
+}
+
+// validAttr 校验属性值
+func validAttr(tag, key, val string) bool {
+	switch key {
+	case "href", "src":
+		return safeURL(val)
+	case "class":
+		return languageClass.MatchString(val)
+	case "align":
+		return alignValue.MatchString(val)
+	case "start":
+		return startValue.MatchString(val)
+	case "type":
+		return tag == "input" && val == "checkbox"
+	}
+	return true
+}
+
+// safeURL 只允许http/https/mailto和相对地址
+func safeURL(raw string) bool {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https", "mailto":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package markdown
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// blockTags 块级元素，提取文本时前后用空白分隔
+var blockTags = map[string]bool{
+	"p": true, "br": true, "hr": true, "pre": true, "blockquote": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"ul": true, "ol": true, "li": true, "table": true, "tr": true, "th": true, "td": true,
+}
+
+// Text 提取渲染结果中的纯文本，用于搜索索引和摘要
+// 参数：
+//   - s: Render输出的HTML
+//
+// 返回：
+//   - string: 纯文本，连续空白合并为一个空格
+func Text(s string) string {
+	var b strings.Builder
+	z := html.NewTokenizer(strings.NewReader(s))
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			return strings.Join(strings.Fields(b.String()), " ")
+		case html.TextToken:
+			b.Write(z.Text())
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
+			name, _ := z.TagName()
+			if blockTags[string(name)] {
+				b.WriteByte(' ')
+			}
+		}
+	}
+}
@@ -0,0 +1,517 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"land/settings"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// 本地模拟IdP：提供发现文档、授权端点、令牌端点和JWKS，校验PKCE并签发ID Token
+
+const (
+	testClientID    = "land-test"
+	testRedirectURL = "http://localhost:8080/auth/oidc/mock/callback"
+)
+
+// signingKey IdP的签名密钥
+type signingKey struct {
+	kid string
+	key *rsa.PrivateKey
+}
+
+// authRequest 授权端点收到的请求，兑换授权码时校验
+type authRequest struct {
+	challenge string
+	nonce     string
+	redirect  string
+}
+
+type mockIdP struct {
+	t   *testing.T
+	srv *httptest.Server
+
+	mu            sync.Mutex
+	keys          []signingKey            // JWKS中公布的公钥
+	signer        signingKey              // 签发ID Token使用的密钥，可以不在JWKS中
+	codes         map[string]*authRequest // 已发放的授权码
+	claims        func(c jwt.MapClaims)   // 签发前修改ID Token声明
+	method        jwt.SigningMethod       // 签名算法，默认RS256
+	hmacKey       []byte                  // method为HMAC时使用的密钥
+	discoveryHits int
+	jwksHits      int
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	t.Helper()
+	idp := &mockIdP{t: t, codes: map[string]*authRequest{}}
+	idp.signer = idp.newKey("key-1")
+	idp.keys = []signingKey{idp.signer}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(discoveryPath, idp.handleDiscovery)
+	mux.HandleFunc("/authorize", idp.handleAuthorize)
+	mux.HandleFunc("/token", idp.handleToken)
+	mux.HandleFunc("/jwks", idp.handleJWKS)
+	idp.srv = httptest.NewServer(mux)
+	t.Cleanup(idp.srv.Close)
+	return idp
+}
+
+func (idp *mockIdP) newKey(kid string) signingKey {
+	idp.t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		idp.t.Fatalf("rsa.GenerateKey() failed: %v", err)
+	}
+	return signingKey{kid: kid, key: key}
+}
+
+// provider 指向模拟IdP的提供方
+func (idp *mockIdP) provider() *Provider {
+	return &Provider{Name: "mock", cfg: &settings.OIDCProviderConfig{
+		Name:        "mock",
+		Issuer:      idp.srv.URL,
+		ClientID:    testClientID,
+		RedirectURL: testRedirectURL,
+	}}
+}
+
+func (idp *mockIdP) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	idp.mu.Lock()
+	idp.discoveryHits++
+	idp.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 idp.srv.URL,
+		"authorization_endpoint": idp.srv.URL + "/authorize",
+		"token_endpoint":         idp.srv.URL + "/token",
+		"jwks_uri":               idp.srv.URL + "/jwks",
+	})
+}
+
+// handleAuthorize 模拟用户在IdP登录并同意授权，重定向回客户端
+func (idp *mockIdP) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("response_type") != "code" || q.Get("client_id") != testClientID ||
+		q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		http.Error(w, "invalid_request", http.StatusBadRequest)
+		return
+	}
+	code := randomString(idp.t)
+	idp.mu.Lock()
+	idp.codes[code] = &authRequest{
+		challenge: q.Get("code_challenge"),
+		nonce:     q.Get("nonce"),
+		redirect:  q.Get("redirect_uri"),
+	}
+	idp.mu.Unlock()
+
+	v := url.Values{}
+	v.Set("code", code)
+	v.Set("state", q.Get("state"))
+	http.Redirect(w, r, q.Get("redirect_uri")+"?"+v.Encode(), http.StatusFound)
+}
+
+// handleToken 校验授权码、redirect_uri与PKCE，签发ID Token；授权码只能使用一次
+func (idp *mockIdP) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	idp.mu.Lock()
+	req, ok := idp.codes[r.PostForm.Get("code")]
+	delete(idp.codes, r.PostForm.Get("code"))
+	idp.mu.Unlock()
+	if !ok || r.PostForm.Get("grant_type") != "authorization_code" ||
+		r.PostForm.Get("client_id") != testClientID || r.PostForm.Get("redirect_uri") != req.redirect {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != req.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error":             "invalid_grant",
+			"error_description": "PKCE verification failed",
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{
+		"access_token": "access",
+		"token_type":   "Bearer",
+		"id_token":     idp.idToken(req.nonce),
+	})
+}
+
+func (idp *mockIdP) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	idp.jwksHits++
+	keys := make([]map[string]string, 0, len(idp.keys))
+	for _, k := range idp.keys {
+		keys = append(keys, map[string]string{
+			"kty": "RSA",
+			"kid": k.kid,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(k.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.key.E)).Bytes()),
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"keys": keys})
+}
+
+// idToken 按当前配置签发ID Token
+func (idp *mockIdP) idToken(nonce string) string {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	now := time.Now().Unix()
+	claims := jwt.MapClaims{
+		"iss":   idp.srv.URL,
+		"sub":   "mock-user-1",
+		"aud":   testClientID,
+		"exp":   now + 300,
+		"iat":   now,
+		"nonce": nonce,
+		"email": "alice@example.com",
+	}
+	if idp.claims != nil {
+		idp.claims(claims)
+	}
+	method := idp.method
+	if method == nil {
+		method = jwt.SigningMethodRS256
+	}
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = idp.signer.kid
+	var key interface{} = idp.signer.key
+	if _, ok := method.(*jwt.SigningMethodHMAC); ok {
+		key = idp.hmacKey
+	}
+	s, err := token.SignedString(key)
+	if err != nil {
+		idp.t.Fatalf("SignedString() failed: %v", err)
+	}
+	return s
+}
+
+// login 走一遍浏览器侧的流程：生成授权地址，访问授权端点，从重定向中取出code与state
+func (idp *mockIdP) login(p *Provider, state, nonce, challenge string) (code string) {
+	idp.t.Helper()
+	authURL, err := p.AuthCodeURL(state, nonce, challenge)
+	if err != nil {
+		idp.t.Fatalf("AuthCodeURL() failed: %v", err)
+	}
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		idp.t.Fatalf("GET authorize failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		idp.t.Fatalf("authorize status = %d, want %d", resp.StatusCode, http.StatusFound)
+	}
+	loc, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		idp.t.Fatalf("parse Location failed: %v", err)
+	}
+	if got := loc.Query().Get("state"); got != state {
+		idp.t.Fatalf("callback state = %q, want %q", got, state)
+	}
+	return loc.Query().Get("code")
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func randomString(t *testing.T) string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatalf("rand.Read() failed: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func TestDiscovery(t *testing.T) {
+	idp := newMockIdP(t)
+	p := idp.provider()
+
+	authURL, err := p.AuthCodeURL("state-1", "nonce-1", "challenge-1")
+	if err != nil {
+		t.Fatalf("AuthCodeURL() failed: %v", err)
+	}
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("parse auth url failed: %v", err)
+	}
+	if got := u.Scheme + "://" + u.Host + u.Path; got != idp.srv.URL+"/authorize" {
+		t.Errorf("authorization endpoint = %q, want %q", got, idp.srv.URL+"/authorize")
+	}
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             testClientID,
+		"redirect_uri":          testRedirectURL,
+		"scope":                 "openid email profile",
+		"state":                 "state-1",
+		"nonce":                 "nonce-1",
+		"code_challenge":        "challenge-1",
+		"code_challenge_method": "S256",
+	}
+	for k, v := range want {
+		if got := u.Query().Get(k); got != v {
+			t.Errorf("%s = %q, want %q", k, got, v)
+		}
+	}
+
+	// 发现文档在有效期内只请求一次
+	if _, err = p.AuthCodeURL("state-2", "nonce-2", "challenge-2"); err != nil {
+		t.Fatalf("AuthCodeURL() failed: %v", err)
+	}
+	if idp.discoveryHits != 1 {
+		t.Errorf("discovery requested %d times, want 1", idp.discoveryHits)
+	}
+}
+
+func TestDiscoveryIssuerMismatch(t *testing.T) {
+	idp := newMockIdP(t)
+	p := idp.provider()
+	p.cfg.Issuer = idp.srv.URL + "/other"
+
+	// 发现文档从配置的issuer下读取，模拟IdP在该路径下没有文档
+	if _, err := p.AuthCodeURL("state", "nonce", "challenge"); err == nil {
+		t.Fatal("AuthCodeURL() with wrong issuer succeeded")
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{
+			"issuer":                 "https://evil.example.com",
+			"authorization_endpoint": idp.srv.URL + "/authorize",
+			"token_endpoint":         idp.srv.URL + "/token",
+			"jwks_uri":               idp.srv.URL + "/jwks",
+		})
+	}))
+	defer srv.Close()
+	p.cfg.Issuer = srv.URL
+	if _, err := p.AuthCodeURL("state", "nonce", "challenge"); err == nil || !strings.Contains(err.Error(), "issuer mismatch") {
+		t.Fatalf("AuthCodeURL() error = %v, want issuer mismatch", err)
+	}
+}
+
+func TestExchangePKCE(t *testing.T) {
+	idp := newMockIdP(t)
+	p := idp.provider()
+
+	verifier, challenge, err := NewPKCE()
+	if err != nil {
+		t.Fatalf("NewPKCE() failed: %v", err)
+	}
+	code := idp.login(p, "state-1", "nonce-1", challenge)
+
+	token, err := p.Exchange(code, verifier, "nonce-1")
+	if err != nil {
+		t.Fatalf("Exchange() failed: %v", err)
+	}
+	if token.Subject != "mock-user-1" || token.Email != "alice@example.com" || token.Nonce != "nonce-1" {
+		t.Errorf("Exchange() = %+v, unexpected claims", token)
+	}
+
+	// 授权码只能兑换一次
+	if _, err = p.Exchange(code, verifier, "nonce-1"); !errors.Is(err, ErrExchangeFailed) {
+		t.Errorf("reused code error = %v, want ErrExchangeFailed", err)
+	}
+}
+
+func TestExchangeWrongVerifier(t *testing.T) {
+	idp := newMockIdP(t)
+	p := idp.provider()
+
+	_, challenge, err := NewPKCE()
+	if err != nil {
+		t.Fatalf("NewPKCE() failed: %v", err)
+	}
+	otherVerifier, _, err := NewPKCE()
+	if err != nil {
+		t.Fatalf("NewPKCE() failed: %v", err)
+	}
+	code := idp.login(p, "state-1", "nonce-1", challenge)
+
+	if _, err = p.Exchange(code, otherVerifier, "nonce-1"); !errors.Is(err, ErrExchangeFailed) {
+		t.Fatalf("Exchange() error = %v, want ErrExchangeFailed", err)
+	}
+}
+
+func TestExchangeRejectsIDToken(t *testing.T) {
+	tests := []struct {
+		name  string
+		nonce string // Exchange时传入的nonce，为空时与授权请求一致
+		setup func(idp *mockIdP)
+	}{
+		{
+			name: "signature from unknown key",
+			setup: func(idp *mockIdP) {
+				// kid相同，但用不在JWKS中的私钥签名
+				forged := idp.newKey(idp.signer.kid)
+				idp.signer = forged
+			},
+		},
+		{
+			name: "hmac signed with public key",
+			setup: func(idp *mockIdP) {
+				idp.method = jwt.SigningMethodHS256
+				idp.hmacKey = idp.signer.key.PublicKey.N.Bytes()
+			},
+		},
+		{
+			name: "issuer mismatch",
+			setup: func(idp *mockIdP) {
+				idp.claims = func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }
+			},
+		},
+		{
+			name: "audience mismatch",
+			setup: func(idp *mockIdP) {
+				idp.claims = func(c jwt.MapClaims) { c["aud"] = "other-client" }
+			},
+		},
+		{
+			name: "multiple audiences without azp",
+			setup: func(idp *mockIdP) {
+				idp.claims = func(c jwt.MapClaims) { c["aud"] = []string{testClientID, "other-client"} }
+			},
+		},
+		{
+			name: "azp mismatch",
+			setup: func(idp *mockIdP) {
+				idp.claims = func(c jwt.MapClaims) {
+					c["aud"] = []string{testClientID, "other-client"}
+					c["azp"] = "other-client"
+				}
+			},
+		},
+		{
+			name:  "nonce mismatch",
+			nonce: "replayed-nonce",
+		},
+		{
+			name: "expired",
+			setup: func(idp *mockIdP) {
+				idp.claims = func(c jwt.MapClaims) {
+					c["iat"] = time.Now().Add(-time.Hour).Unix()
+					c["exp"] = time.Now().Add(-10 * time.Minute).Unix()
+				}
+			},
+		},
+		{
+			name: "missing subject",
+			setup: func(idp *mockIdP) {
+				idp.claims = func(c jwt.MapClaims) { delete(c, "sub") }
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := newMockIdP(t)
+			p := idp.provider()
+			if tt.setup != nil {
+				tt.setup(idp)
+			}
+			verifier, challenge, err := NewPKCE()
+			if err != nil {
+				t.Fatalf("NewPKCE() failed: %v", err)
+			}
+			code := idp.login(p, "state-1", "nonce-1", challenge)
+			nonce := tt.nonce
+			if nonce == "" {
+				nonce = "nonce-1"
+			}
+			if _, err = p.Exchange(code, verifier, nonce); !errors.Is(err, ErrInvalidIDToken) {
+				t.Fatalf("Exchange() error = %v, want ErrInvalidIDToken", err)
+			}
+		})
+	}
+}
+
+func TestExchangeAcceptsMatchingAzp(t *testing.T) {
+	idp := newMockIdP(t)
+	p := idp.provider()
+	idp.claims = func(c jwt.MapClaims) {
+		c["aud"] = []string{testClientID, "other-client"}
+		c["azp"] = testClientID
+	}
+	verifier, challenge, err := NewPKCE()
+	if err != nil {
+		t.Fatalf("NewPKCE() failed: %v", err)
+	}
+	code := idp.login(p, "state-1", "nonce-1", challenge)
+	if _, err = p.Exchange(code, verifier, "nonce-1"); err != nil {
+		t.Fatalf("Exchange() failed: %v", err)
+	}
+}
+
+func TestJWKSRefreshOnUnknownKid(t *testing.T) {
+	idp := newMockIdP(t)
+	p := idp.provider()
+
+	exchange := func() error {
+		verifier, challenge, err := NewPKCE()
+		if err != nil {
+			t.Fatalf("NewPKCE() failed: %v", err)
+		}
+		code := idp.login(p, "state", "nonce", challenge)
+		_, err = p.Exchange(code, verifier, "nonce")
+		return err
+	}
+
+	if err := exchange(); err != nil {
+		t.Fatalf("Exchange() failed: %v", err)
+	}
+	if err := exchange(); err != nil {
+		t.Fatalf("Exchange() failed: %v", err)
+	}
+	if idp.jwksHits != 1 {
+		t.Fatalf("jwks requested %d times, want 1 while kid is cached", idp.jwksHits)
+	}
+
+	// IdP轮换密钥，新kid不在缓存中
+	idp.mu.Lock()
+	idp.signer = idp.newKey("key-2")
+	idp.keys = append(idp.keys, idp.signer)
+	idp.mu.Unlock()
+
+	// 距上次刷新不足jwksMinRefresh时不重复请求，防止伪造kid的请求打满IdP
+	if err := exchange(); !errors.Is(err, ErrInvalidIDToken) {
+		t.Fatalf("Exchange() error = %v, want ErrInvalidIDToken before refresh interval", err)
+	}
+	if idp.jwksHits != 1 {
+		t.Fatalf("jwks requested %d times, want 1 within refresh interval", idp.jwksHits)
+	}
+
+	p.mu.Lock()
+	p.keysTime = time.Now().Add(-2 * jwksMinRefresh)
+	p.mu.Unlock()
+	if err := exchange(); err != nil {
+		t.Fatalf("Exchange() after key rotation failed: %v", err)
+	}
+	if idp.jwksHits != 2 {
+		t.Fatalf("jwks requested %d times, want 2 after unknown kid", idp.jwksHits)
+	}
+}
@@ -0,0 +1,340 @@
+package oidc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// IDToken ID Token中用到的声明
+type IDToken struct {
+	Issuer            string   `json:"iss"`
+	Subject           string   `json:"sub"`
+	Audience          audience `json:"aud"`
+	AuthorizedParty   string   `json:"azp"`
+	ExpiresAt         int64    `json:"exp"`
+	IssuedAt          int64    `json:"iat"`
+	Nonce             string   `json:"nonce"`
+	Email             string   `json:"email"`
+	EmailVerified     bool     `json:"email_verified"`
+	Name              string   `json:"name"`
+	PreferredUsername string   `json:"preferred_username"`
+}
+
+// Valid 校验时间相关声明，实现jwt.Claims
+func (t *IDToken) Valid() error {
+	now := time.Now().Unix()
+	if t.ExpiresAt == 0 || now > t.ExpiresAt+clockSkewLeeway {
+		return fmt.Errorf("id token expired")
+	}
+	if t.IssuedAt > now+clockSkewLeeway {
+		return fmt.Errorf("id token issued in the future")
+	}
+	return nil
+}
+
+// audience aud声明可以是字符串或字符串数组
+type audience []string
+
+func (a *audience) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*a = audience{s}
+		return nil
+	}
+	var arr []string
+	if err := json.Unmarshal(b, &arr); err != nil {
+		return err
+	}
+	*a = arr
+	return nil
+}
+
+func (a audience) contains(v string) bool {
+	for _, s := range a {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenResponse 令牌端点的响应
+type tokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// Exchange 用授权码兑换令牌并校验ID Token
+// 参数：
+//   - code: 回调中的授权码
+//   - verifier: PKCE code_verifier
+//   - nonce: 授权请求中的nonce
+//
+// 返回：
+//   - *IDToken: 校验通过的ID Token声明
+//   - error: 兑换失败返回ErrExchangeFailed，校验失败返回ErrInvalidIDToken
+func (p *Provider) Exchange(code, verifier, nonce string) (*IDToken, error) {
+	meta, err := p.discover()
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.RedirectURL())
+	form.Set("client_id", p.cfg.ClientID)
+	form.Set("code_verifier", verifier)
+	if p.cfg.ClientSecret != "" {
+		form.Set("client_secret", p.cfg.ClientSecret)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	tr := new(tokenResponse)
+	if err = json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(tr); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExchangeFailed, err)
+	}
+	if resp.StatusCode != http.StatusOK || tr.Error != "" {
+		return nil, fmt.Errorf("%w: %s %s", ErrExchangeFailed, tr.Error, tr.ErrorDescription)
+	}
+	if tr.IDToken == "" {
+		return nil, fmt.Errorf("%w: missing id_token", ErrExchangeFailed)
+	}
+
+	return p.verify(tr.IDToken, nonce)
+}
+
+// verify 校验ID Token的签名、签发方、受众与nonce
+func (p *Provider) verify(raw, nonce string) (*IDToken, error) {
+	meta, err := p.discover()
+	if err != nil {
+		return nil, err
+	}
+
+	claims := new(IDToken)
+	_, err = jwt.ParseWithClaims(raw, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, err := p.key(kid)
+		if err != nil {
+			return nil, err
+		}
+		// 签名算法必须与公钥类型一致，防止算法混淆攻击
+		switch key.(type) {
+		case *rsa.PublicKey:
+			if _, ok := t.Method.(*jwt.SigningMethodRSA); !ok {
+				return nil, fmt.Errorf("unexpected signing method: %s", t.Method.Alg())
+			}
+		case *ecdsa.PublicKey:
+			if _, ok := t.Method.(*jwt.SigningMethodECDSA); !ok {
+				return nil, fmt.Errorf("unexpected signing method: %s", t.Method.Alg())
+			}
+		}
+		return key, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	if claims.Issuer != meta.Issuer {
+		return nil, fmt.Errorf("%w: issuer mismatch", ErrInvalidIDToken)
+	}
+	if !claims.Audience.contains(p.cfg.ClientID) {
+		return nil, fmt.Errorf("%w: audience mismatch", ErrInvalidIDToken)
+	}
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != p.cfg.ClientID {
+		return nil, fmt.Errorf("%w: azp mismatch", ErrInvalidIDToken)
+	}
+	if claims.Nonce != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidIDToken)
+	}
+	return claims, nil
+}
+
+// discover 获取发现文档，带缓存
+func (p *Provider) discover() (*discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.meta != nil && time.Since(p.metaTime) < discoveryTTL {
+		return p.meta, nil
+	}
+
+	meta := new(discovery)
+	if err := getJSON(strings.TrimRight(p.cfg.Issuer, "/")+discoveryPath, meta); err != nil {
+		return nil, err
+	}
+	if meta.Issuer != strings.TrimRight(p.cfg.Issuer, "/") && meta.Issuer != p.cfg.Issuer {
+		return nil, fmt.Errorf("oidc discovery issuer mismatch: %s", meta.Issuer)
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" || meta.JWKSURI == "" {
+		return nil, fmt.Errorf("oidc discovery document incomplete")
+	}
+
+	p.meta, p.metaTime = meta, time.Now()
+	return meta, nil
+}
+
+// jwk JWKS中的单个公钥
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// key 根据kid获取验签公钥，未知kid时刷新一次JWKS以支持IdP轮换密钥
+func (p *Provider) key(kid string) (interface{}, error) {
+	meta, err := p.discover()
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if k, ok := p.lookup(kid, meta.JWKSURI); ok {
+		return k, nil
+	}
+	if p.keysURI == meta.JWKSURI && time.Since(p.keysTime) < jwksMinRefresh {
+		return nil, fmt.Errorf("unknown key id: %s", kid)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err = getJSON(meta.JWKSURI, &set); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		pub, err := k.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = pub
+	}
+	p.keys, p.keysTime, p.keysURI = keys, time.Now(), meta.JWKSURI
+
+	if k, ok := p.lookup(kid, meta.JWKSURI); ok {
+		return k, nil
+	}
+	return nil, fmt.Errorf("unknown key id: %s", kid)
+}
+
+// lookup 在已缓存的公钥中查找，ID Token未带kid且只有一个公钥时直接使用
+func (p *Provider) lookup(kid, jwksURI string) (interface{}, bool) {
+	if p.keysURI != jwksURI {
+		return nil, false
+	}
+	if k, ok := p.keys[kid]; ok {
+		return k, true
+	}
+	if kid == "" && len(p.keys) == 1 {
+		for _, k := range p.keys {
+			return k, true
+		}
+	}
+	return nil, false
+}
+
+// publicKey 将JWK转换为RSA或ECDSA公钥
+func (k *jwk) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve: %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type: %s", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// getJSON 请求IdP并解析JSON响应
+func getJSON(u string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: unexpected status %d", u, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v)
+}
@@ -0,0 +1,119 @@
+package password
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// 哈希格式采用PHC字符串格式，带算法与参数版本，便于后续升级参数：
+//   $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>
+// 旧版MD5格式没有前缀，校验通过后由调用方重新哈希并回写数据库
+
+const (
+	algArgon2id = "argon2id"
+
+	argonMemory  = 64 * 1024 // 内存开销（KiB）
+	argonTime    = 3         // 迭代次数
+	argonThreads = 2         // 并行度
+	argonKeyLen  = 32        // 输出长度
+	saltLen      = 16        // 每个用户独立的盐长度
+)
+
+var (
+	// ErrInvalidHash 表示哈希字符串格式无法识别
+	ErrInvalidHash = errors.New("无法识别的密码哈希格式")
+
+	// ErrIncompatibleVersion 表示argon2版本不兼容
+	ErrIncompatibleVersion = errors.New("不兼容的argon2版本")
+)
+
+// Hash 使用argon2id和随机盐对密码进行哈希
+// 参数:
+//   - plain: 原始密码
+//
+// 返回值:
+//   - string: 编码后的哈希字符串
+//   - error: 可能发生的错误
+func Hash(plain string) (string, error) {
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(plain), salt, argonTime, argonMemory, argonThreads, argonKeyLen)
+
+	return fmt.Sprintf("$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		algArgon2id, argon2.Version, argonMemory, argonTime, argonThreads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// Verify 校验密码是否与哈希匹配
+// 参数:
+//   - plain: 原始密码
+//   - encoded: 数据库中保存的哈希
+//   - legacySecret: 旧版MD5哈希使用的静态盐（auth.secret）
+//
+// 返回值:
+//   - ok: 密码是否匹配
+//   - needRehash: 匹配成功但哈希为旧格式或参数过时，需要重新哈希
+//   - err: 哈希格式错误等
+func Verify(plain, encoded, legacySecret string) (ok bool, needRehash bool, err error) {
+	if !strings.HasPrefix(encoded, "$") {
+		// 旧版MD5格式
+		ok = subtle.ConstantTimeCompare([]byte(legacyHash(plain, legacySecret)), []byte(encoded)) == 1
+		return ok, ok, nil
+	}
+
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != algArgon2id {
+		return false, false, ErrInvalidHash
+	}
+
+	var version int
+	if _, err = fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return false, false, ErrInvalidHash
+	}
+	if version != argon2.Version {
+		return false, false, ErrIncompatibleVersion
+	}
+
+	var memory, iterations uint32
+	var threads uint8
+	if _, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &iterations, &threads); err != nil {
+		return false, false, ErrInvalidHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, false, ErrInvalidHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return false, false, ErrInvalidHash
+	}
+
+	other := argon2.IDKey([]byte(plain), salt, iterations, memory, threads, uint32(len(key)))
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		return false, false, nil
+	}
+
+	// 参数低于当前配置时，登录成功后顺便升级
+	needRehash = memory < argonMemory || iterations < argonTime || threads < argonThreads || len(key) < argonKeyLen
+	return true, needRehash, nil
+}
+
+// legacyHash 旧版MD5+静态盐算法，仅用于迁移期间校验
+func legacyHash(plain, secret string) string {
+	h := md5.New()
+	h.Write([]byte(secret))
+	return hex.EncodeToString(h.Sum([]byte(plain)))
+}
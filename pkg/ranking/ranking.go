@@ -0,0 +1,132 @@
+package ranking
+
+import "math"
+
+// 帖子排序策略：根据赞成票、反对票和发布时间计算帖子在排序集合中的分数，分数越大越靠前
+// hot/best/controversial 参考 Reddit 的排序算法，top 为加权后的净票数
+
+// Votes 帖子的投票统计
+type Votes struct {
+	Up   int64 // 赞成票数
+	Down int64 // 反对票数
+}
+
+// Params 计算分数的参数，可按社区配置
+type Params struct {
+	UpWeight   float64 // 每张赞成票的权重
+	DownWeight float64 // 每张反对票的权重
+	HotDecay   float64 // hot排序的时间衰减（秒）：晚发布这么久，需要多10倍的净票数才能排在同一位置
+}
+
+// Strategy 排序策略
+type Strategy interface {
+	// Name 策略名称，同时作为帖子列表的order参数
+	Name() string
+	// Score 计算帖子的分数
+	Score(v Votes, publishTime int64, p Params) float64
+}
+
+var strategies = map[string]Strategy{}
+
+// Register 注册排序策略，同名的策略会被替换
+// 参数：
+//   - s: 排序策略
+func Register(s Strategy) {
+	strategies[s.Name()] = s
+}
+
+// Get 按名称获取排序策略
+// 参数：
+//   - name: 策略名称
+//
+// 返回：
+//   - Strategy: 排序策略
+//   - bool: 是否存在
+func Get(name string) (Strategy, bool) {
+	s, ok := strategies[name]
+	return s, ok
+}
+
+func init() {
+	Register(Hot{})
+	Register(Best{})
+	Register(Controversial{})
+	Register(Top{})
+}
+
+// weighted 加权后的赞成票与反对票
+func weighted(v Votes, p Params) (up, down float64) {
+	return float64(v.Up) * p.UpWeight, float64(v.Down) * p.DownWeight
+}
+
+const (
+	hotEpoch        = 1577836800 // 2020-01-01 00:00:00 UTC，发布时间从这里算起，避免分数过大损失精度
+	defaultHotDecay = 45000      // 未配置时hot排序的时间衰减，12.5小时
+	wilsonZ         = 1.96       // Wilson区间的z值，对应95%置信度
+)
+
+// Hot 热门：净票数取对数，加上随发布时间线性增长的部分
+// 新帖子天然比旧帖子分数高，分数只在投票时变化，不需要定时重算
+type Hot struct{}
+
+func (Hot) Name() string { return "hot" }
+
+func (Hot) Score(v Votes, publishTime int64, p Params) float64 {
+	up, down := weighted(v, p)
+	s := up - down
+	order := math.Log10(math.Max(math.Abs(s), 1))
+	sign := 0.0
+	if s > 0 {
+		sign = 1
+	} else if s < 0 {
+		sign = -1
+	}
+	decay := p.HotDecay
+	if decay <= 0 {
+		decay = defaultHotDecay
+	}
+	return sign*order + float64(publishTime-hotEpoch)/decay
+}
+
+// Best 最佳：赞成比例的Wilson置信区间下限，票数少时比例不可信，分数偏低
+type Best struct{}
+
+func (Best) Name() string { return "best" }
+
+func (Best) Score(v Votes, _ int64, p Params) float64 {
+	up, down := weighted(v, p)
+	n := up + down
+	if n <= 0 {
+		return 0
+	}
+	phat := up / n
+	z2 := wilsonZ * wilsonZ
+	return (phat + z2/(2*n) - wilsonZ*math.Sqrt((phat*(1-phat)+z2/(4*n))/n)) / (1 + z2/n)
+}
+
+// Controversial 争议：赞成与反对越接近、总票数越多，分数越高；只有一方的票时为0
+type Controversial struct{}
+
+func (Controversial) Name() string { return "controversial" }
+
+func (Controversial) Score(v Votes, _ int64, p Params) float64 {
+	up, down := weighted(v, p)
+	if up <= 0 || down <= 0 {
+		return 0
+	}
+	balance := down / up
+	if up < down {
+		balance = up / down
+	}
+	return math.Pow(up+down, balance)
+}
+
+// Top 最多赞：加权后的净票数
+type Top struct{}
+
+func (Top) Name() string { return "top" }
+
+func (Top) Score(v Votes, _ int64, p Params) float64 {
+	up, down := weighted(v, p)
+	return up - down
+}
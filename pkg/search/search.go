@@ -0,0 +1,211 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// 中文没有空格分词，按字切分为单字和相邻两字（bigram），英文和数字按词切分并转为小写
+// 索引时单字与两字都写入，查询时长度大于1的中文片段只用两字，
+// 多个词之间取交集，相当于要求所有相邻两字都出现，接近短语匹配
+
+const maxWordLength = 32 // 超过该长度的英文词不索引
+
+// segment 查询或文本中的一个片段：一段连续的汉字或一个词
+type segment struct {
+	text []rune
+	han  bool
+}
+
+// isHan 是否为汉字
+func isHan(r rune) bool {
+	return unicode.Is(unicode.Han, r)
+}
+
+// isWordRune 是否为词的组成字符
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// isWordPart 是否为英文词的组成字符，汉字单独成段，不算在词内
+func isWordPart(r rune) bool {
+	return isWordRune(r) && !isHan(r)
+}
+
+// segments 将文本切分为连续汉字片段和词，统一转为小写
+func segments(s string) []segment {
+	var segs []segment
+	var cur []rune
+	curHan := false
+	flush := func() {
+		if len(cur) > 0 {
+			segs = append(segs, segment{text: cur, han: curHan})
+			cur = nil
+		}
+	}
+	for _, r := range s {
+		r = unicode.ToLower(r)
+		switch {
+		case isHan(r):
+			if !curHan {
+				flush()
+			}
+			curHan = true
+			cur = append(cur, r)
+		case isWordRune(r):
+			if curHan {
+				flush()
+			}
+			curHan = false
+			cur = append(cur, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return segs
+}
+
+// Terms 统计文本中各索引词出现的次数
+// 参数：
+//   - s: 纯文本
+//
+// 返回：
+//   - map[string]int: 索引词到出现次数的映射
+func Terms(s string) map[string]int {
+	terms := make(map[string]int)
+	for _, seg := range segments(s) {
+		if !seg.han {
+			if len(seg.text) <= maxWordLength {
+				terms[string(seg.text)]++
+			}
+			continue
+		}
+		for i := range seg.text {
+			terms[string(seg.text[i])]++
+			if i+1 < len(seg.text) {
+				terms[string(seg.text[i:i+2])]++
+			}
+		}
+	}
+	return terms
+}
+
+// QueryTerms 把搜索关键词切分为查询词，已去重
+// 参数：
+//   - q: 搜索关键词
+//
+// 返回：
+//   - []string: 查询词，关键词中没有文字时为空
+func QueryTerms(q string) []string {
+	seen := make(map[string]bool)
+	terms := make([]string, 0)
+	add := func(t string) {
+		if !seen[t] {
+			seen[t] = true
+			terms = append(terms, t)
+		}
+	}
+	for _, seg := range segments(q) {
+		switch {
+		case !seg.han:
+			if len(seg.text) <= maxWordLength {
+				add(string(seg.text))
+			}
+		case len(seg.text) == 1:
+			add(string(seg.text))
+		default:
+			for i := 0; i+1 < len(seg.text); i++ {
+				add(string(seg.text[i : i+2]))
+			}
+		}
+	}
+	return terms
+}
+
+// Highlight 在文本中标出查询词，截取第一个命中位置附近的片段
+// 返回的是HTML：原文已转义，命中部分用<em>包裹
+// 参数：
+//   - s: 纯文本
+//   - terms: QueryTerms返回的查询词
+//   - width: 片段最多包含的字符数，0表示不截取
+//
+// 返回：
+//   - string: 高亮后的片段，没有命中时返回开头的width个字符
+func Highlight(s string, terms []string, width int) string {
+	text := []rune(s)
+	lower := make([]rune, len(text))
+	for i, r := range text {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	// 标出所有命中的字符，英文词只匹配完整的词
+	hit := make([]bool, len(text))
+	first := -1
+	for _, t := range terms {
+		term := []rune(t)
+		word := !isHan(term[0])
+		for i := 0; i+len(term) <= len(lower); i++ {
+			if !equalRunes(lower[i:i+len(term)], term) {
+				continue
+			}
+			if word && ((i > 0 && isWordPart(lower[i-1])) ||
+				(i+len(term) < len(lower) && isWordPart(lower[i+len(term)]))) {
+				continue
+			}
+			for j := i; j < i+len(term); j++ {
+				hit[j] = true
+			}
+			if first < 0 || i < first {
+				first = i
+			}
+		}
+	}
+
+	start, end := 0, len(text)
+	if width > 0 && len(text) > width {
+		if first > width/4 {
+			start = first - width/4
+		}
+		if start+width > len(text) {
+			start = len(text) - width
+		}
+		end = start + width
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	for i := start; i < end; {
+		j := i
+		for j < end && hit[j] == hit[i] {
+			j++
+		}
+		part := html.EscapeString(string(text[i:j]))
+		if hit[i] {
+			b.WriteString("<em>" + part + "</em>")
+		} else {
+			b.WriteString(part)
+		}
+		i = j
+	}
+	if end < len(text) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+// equalRunes 比较两个字符切片是否相同
+func equalRunes(a, b []rune) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
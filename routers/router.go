@@ -4,6 +4,7 @@ import (
 	"land/controllers"
 	"land/logger"
 	"land/middlewares"
+	"land/models"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -19,11 +20,57 @@ func SetRouter(mode string) *gin.Engine {
 		logger.GinRecovery(true),
 		middlewares.RateLimit(2*time.Second, 50))
 
+	r.GET("/.well-known/jwks.json", controllers.JWKSHandler) // 签名公钥
+
 	auth := r.Group("/auth")
 	{
-		auth.POST("/login", controllers.LoginHandler)     // 登录
-		auth.POST("/register", controllers.SignUpHandler) // 注册
-		auth.POST("/logout", middlewares.JWTAuth(), controllers.LogoutHandler)
+		auth.POST("/login", controllers.LoginHandler)                    // 登录
+		auth.POST("/login/2fa", controllers.TwoFactorLoginHandler)       // 两步验证登录
+		auth.POST("/register", controllers.SignUpHandler)                // 注册
+		auth.POST("/refresh", controllers.RefreshTokenHandler)           // 刷新令牌
+		auth.POST("/password/forgot", controllers.ForgotPasswordHandler) // 忘记密码
+		auth.POST("/password/reset", controllers.ResetPasswordHandler)   // 重置密码
+		auth.GET("/verify", controllers.VerifyEmailHandler)              // 验证邮箱
+
+		// 需要登录会话的认证接口，不接受个人访问令牌
+		session := auth.Group("", middlewares.JWTAuth(), middlewares.RequireScope())
+		session.POST("/verify/resend", controllers.ResendVerifyEmailHandler) // 重发验证邮件
+		session.POST("/logout", controllers.LogoutHandler)                   // 登出
+
+		// 第三方登录（OpenID Connect）
+		auth.GET("/oidc/providers", controllers.OIDCProvidersHandler)         // 提供方列表
+		auth.GET("/oidc/:provider/login", controllers.OIDCLoginHandler)       // 发起登录
+		auth.GET("/oidc/:provider/callback", controllers.OIDCCallbackHandler) // 登录/绑定回调
+	}
+
+	// 公开接口：游客可直接访问，携带有效token时识别当前用户（用于访问量防刷等）
+	// 个人访问令牌需带read权限范围
+	public := r.Group("/api/v1", middlewares.OptionalAuth(), middlewares.RequireScope(models.ScopeRead))
+	{
+		// 社区相关
+		public.GET("/community", controllers.CommunityListController)       // 获取社区列表
+		public.GET("/community/:id", controllers.CommunityDetailController) // 获取社区详情
+
+		// 帖子相关
+		public.GET("/post", controllers.GetPostListController)                            // 获取帖子列表
+		public.GET("/post/:id", controllers.PostDetailController)                         // 获取帖子详情
+		public.GET("/post/:id/revisions", controllers.PostRevisionListHandler)            // 帖子修订记录
+		public.GET("/post/:id/revisions/compare", controllers.ComparePostRevisionHandler) // 比较两个版本
+		public.GET("/posts2/", controllers.GetPostListHandler2)                           // 根据时间或分数获取帖子列表（优化版）
+
+		// 标签相关
+		public.GET("/tags", controllers.SuggestTagsHandler)       // 标签自动补全
+		public.GET("/tags/:name", controllers.TagPostListHandler) // 标签下的帖子
+
+		// 评论相关
+		public.GET("/comment", controllers.CommentListHandler) // 评论列表
+
+		// 用户资料
+		public.GET("/users/:id", controllers.UserProfileHandler)              // 用户公开资料
+		public.GET("/users/:id/posts", controllers.UserPostListHandler)       // 用户发布的帖子
+		public.GET("/users/:id/comments", controllers.UserCommentListHandler) // 用户发表的评论
+		public.GET("/users/:id/followers", controllers.FollowerListHandler)   // 粉丝列表
+		public.GET("/users/:id/following", controllers.FollowingListHandler)  // 关注列表
 	}
 
 	// 为后续路由启用JWT验证中间件
@@ -31,29 +78,90 @@ func SetRouter(mode string) *gin.Engine {
 	v1.Use(middlewares.JWTAuth())
 
 	{
-		// 社区相关
-		v1.GET("/community", controllers.CommunityListController)       // 获取社区列表
-		v1.GET("/community/:id", controllers.CommunityDetailController) // 获取社区详情
+		// 个人访问令牌只能访问声明了对应权限范围的接口，账号与管理类接口只允许登录会话访问
+		write := v1.Group("", middlewares.RequireScope(models.ScopePostWrite))
+		account := v1.Group("", middlewares.RequireScope())
 
 		// 帖子相关
-		v1.GET("/post", controllers.GetPostListController)                           // 获取帖子列表
-		v1.GET("/post/:id", controllers.PostDetailController)                        // 获取帖子详情
-		v1.POST("/post", controllers.CreatePostController)                           // 创建帖子
-		v1.PUT("/post", controllers.UpdatePostController)                            // 更新帖子（延迟双删）
-		v1.PUT("/post/consistency", controllers.UpdatePostWithConsistencyController) // 更新帖子（强一致性）
-		v1.POST("/vote", controllers.PostVoteController)                             // 帖子投票
-		v1.GET("/posts2/", controllers.GetPostListHandler2)                          // 根据时间或分数获取帖子列表（优化版）
+		write.POST("/post", middlewares.RequireVerifiedEmail(), controllers.CreatePostController) // 创建帖子
+		write.POST("/post/preview", controllers.PreviewPostHandler)                               // 预览Markdown渲染结果
+		write.PUT("/post", controllers.UpdatePostController)                                      // 更新帖子（延迟双删）
+		write.PUT("/post/consistency", controllers.UpdatePostWithConsistencyController)           // 更新帖子（强一致性）
+		write.DELETE("/post/:id", controllers.DeletePostHandler)                                  // 删除帖子（作者或版主）
+		write.POST("/post/:id/restore", controllers.RestorePostHandler)                           // 恢复已删除的帖子
+
+		// 草稿与定时发布
+		write.GET("/drafts", controllers.DraftListHandler)                                                     // 我的草稿与定时帖子
+		write.PUT("/drafts/:id", controllers.UpdateDraftHandler)                                               // 修改草稿或定时
+		write.DELETE("/drafts/:id", controllers.DeleteDraftHandler)                                            // 删除草稿
+		write.POST("/drafts/:id/publish", middlewares.RequireVerifiedEmail(), controllers.PublishDraftHandler) // 立即发布草稿
+
+		v1.POST("/vote", middlewares.RequireScope(models.ScopeVote),
+			middlewares.RequireVerifiedEmail(), controllers.PostVoteController) // 帖子投票
+
+		// 个人资料
+		account.GET("/users/me", controllers.MyProfileHandler)             // 我的资料
+		account.PUT("/users/me", controllers.UpdateProfileHandler)         // 修改资料
+		account.DELETE("/users/me", controllers.DeleteAccountHandler)      // 注销账号
+		account.GET("/users/me/export", controllers.ExportUserDataHandler) // 导出个人数据
+
+		// 关注相关
+		account.POST("/users/:id/follow", controllers.FollowHandler)                                  // 关注用户
+		account.DELETE("/users/:id/follow", controllers.UnfollowHandler)                              // 取消关注
+		v1.GET("/feed", middlewares.RequireScope(models.ScopeRead), controllers.FollowingFeedHandler) // 关注时间线
+
+		// 拉黑/屏蔽
+		account.POST("/users/:id/block", controllers.BlockHandler)     // 拉黑用户
+		account.DELETE("/users/:id/block", controllers.UnblockHandler) // 解除拉黑
+		account.POST("/users/:id/mute", controllers.MuteHandler)       // 屏蔽用户
+		account.DELETE("/users/:id/mute", controllers.UnmuteHandler)   // 解除屏蔽
+		account.GET("/users/me/blocks", controllers.BlockListHandler)  // 拉黑列表
+		account.GET("/users/me/mutes", controllers.MuteListHandler)    // 屏蔽列表
+
+		// 会话相关
+		account.GET("/sessions", controllers.SessionListHandler)          // 会话列表
+		account.DELETE("/sessions/:id", controllers.RevokeSessionHandler) // 吊销指定会话
+		account.DELETE("/sessions", controllers.LogoutAllHandler)         // 退出所有设备
+
+		// 个人访问令牌
+		account.GET("/tokens", controllers.AccessTokenListHandler)          // 访问令牌列表
+		account.POST("/tokens", controllers.CreateAccessTokenHandler)       // 创建访问令牌
+		account.DELETE("/tokens/:id", controllers.RevokeAccessTokenHandler) // 吊销访问令牌
+
+		// 两步验证相关
+		account.GET("/2fa", controllers.TwoFactorStatusHandler)                         // 两步验证状态
+		account.POST("/2fa/enroll", controllers.TwoFactorEnrollHandler)                 // 绑定认证器
+		account.POST("/2fa/confirm", controllers.TwoFactorConfirmHandler)               // 确认绑定并启用
+		account.POST("/2fa/disable", controllers.TwoFactorDisableHandler)               // 关闭两步验证
+		account.POST("/2fa/recovery-codes", controllers.RegenerateRecoveryCodesHandler) // 重新生成恢复码
+
+		// 第三方账号绑定
+		account.GET("/oidc/identities", controllers.OIDCIdentitiesHandler) // 已绑定的第三方账号
+		account.POST("/oidc/:provider/link", controllers.OIDCLinkHandler)  // 绑定第三方账号
+		account.DELETE("/oidc/:provider", controllers.OIDCUnlinkHandler)   // 解绑第三方账号
 
 		// 评论相关
-		v1.POST("/comment", controllers.CommentHandler)    // 评论
-		v1.GET("/comment", controllers.CommentListHandler) // 评论列表
+		write.POST("/comment", middlewares.RequireVerifiedEmail(), controllers.CommentHandler) // 评论
 
 		// 管理相关
-		v1.POST("/sync/viewcounts", controllers.SyncViewCountsHandler)  // 手动同步访问量
-		v1.POST("/init/viewzset", controllers.InitPostViewZSetHandler)  // 初始化访问量有序集合
-		v1.GET("/test/random-ttl", controllers.TestRandomTTLHandler)    // 测试随机TTL功能
-		v1.DELETE("/post/:id/cache", controllers.ClearPostCacheHandler) // 清除指定帖子缓存
-		v1.DELETE("/post/cache", controllers.ClearAllPostCacheHandler)  // 清除所有帖子缓存
+		sync := v1.Group("", middlewares.RequireRole(models.RoleAdmin), middlewares.RequireScope(models.ScopeAdminSync))
+		sync.POST("/sync/viewcounts", controllers.SyncViewCountsHandler) // 手动同步访问量
+		sync.POST("/init/viewzset", controllers.InitPostViewZSetHandler) // 初始化访问量有序集合
+		sync.POST("/init/search", controllers.RebuildSearchIndexHandler) // 重建搜索索引
+		sync.POST("/init/ranking", controllers.RebuildRankingHandler)    // 重算排序分数
+
+		admin := account.Group("", middlewares.RequireRole(models.RoleAdmin))
+		admin.GET("/test/random-ttl", controllers.TestRandomTTLHandler)             // 测试随机TTL功能
+		admin.GET("/admin/users/:id/roles", controllers.UserRolesHandler)           // 查看用户角色
+		admin.POST("/admin/users/:id/roles", controllers.GrantRoleHandler)          // 授予角色
+		admin.DELETE("/admin/users/:id/roles/:role", controllers.RevokeRoleHandler) // 回收角色
+		admin.GET("/admin/lockouts", controllers.LoginLockListHandler)              // 登录锁定列表
+		admin.POST("/admin/lockouts/unlock", controllers.UnlockLoginHandler)        // 解除登录锁定
+		admin.POST("/admin/keys/rotate", controllers.RotateSigningKeyHandler)       // 轮换签名密钥
+
+		moderator := account.Group("", middlewares.RequireRole(models.RoleAdmin, models.RoleModerator))
+		moderator.DELETE("/post/:id/cache", controllers.ClearPostCacheHandler) // 清除指定帖子缓存
+		moderator.DELETE("/post/cache", controllers.ClearAllPostCacheHandler)  // 清除所有帖子缓存
 	}
 
 	r.NoRoute(func(c *gin.Context) {
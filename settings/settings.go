@@ -19,16 +19,73 @@ type AppConfig struct {
 	StartTime string `mapstructure:"start_time"` // 启动时间
 	MachineID int64  `mapstructure:"machine_id"` // 机器ID
 	Port      int    `mapstructure:"port"`       // 端口号
+	SiteURL   string `mapstructure:"site_url"`   // 前端站点地址，用于生成邮件中的链接
 
 	*LogConfig   `mapstructure:"log"`   // 日志配置
 	*MysqlConfig `mapstructure:"mysql"` // mysql配置
 	*RedisConfig `mapstructure:"redis"` // redis配置
 	*AuthConfig  `mapstructure:"auth"`  // 认证配置
+	*MailConfig  `mapstructure:"mail"`  // 邮件配置
+	*PostConfig  `mapstructure:"post"`  // 帖子配置
+
+	*RankingConfig `mapstructure:"ranking"` // 排序策略配置
+
+	OIDCProviders []*OIDCProviderConfig `mapstructure:"oidc"` // 第三方OpenID Connect登录
 }
 
 type AuthConfig struct {
-	Secret string `mapstructure:"secret"`     // 密钥
-	Expire int    `mapstructure:"jwt_expire"` // 过期时间
+	Secret        string `mapstructure:"secret"`         // 密钥
+	Expire        int    `mapstructure:"jwt_expire"`     // access token过期时间（秒）
+	RefreshExpire int    `mapstructure:"refresh_expire"` // refresh token过期时间（秒）
+
+	RequireVerifiedEmail bool `mapstructure:"require_verified_email"` // 邮箱验证前禁止发帖、评论和投票
+
+	SigningAlg  string `mapstructure:"signing_alg"`  // access token签名算法：RS256/EdDSA
+	KeyDir      string `mapstructure:"key_dir"`      // 签名密钥目录，多实例部署需共享
+	KeyRotation int    `mapstructure:"key_rotation"` // 签名密钥轮换周期（秒）
+
+	DeleteGrace int `mapstructure:"delete_grace"` // 注销账号后保留个人信息的宽限期（秒），期满后彻底删除
+}
+
+type PostConfig struct {
+	DeleteRetention int `mapstructure:"delete_retention"` // 已删除帖子的保留期（秒），期满后彻底删除
+}
+
+type RankingConfig struct {
+	RankingParams `mapstructure:",squash"` // 全站默认的排序参数
+
+	Communities []*CommunityRankingConfig `mapstructure:"communities"` // 按社区覆盖，未配置或为0的项使用全站默认值
+}
+
+type RankingParams struct {
+	VoteWindow int     `mapstructure:"vote_window"` // 投票期限（秒），从发布时间算起
+	UpWeight   float64 `mapstructure:"up_weight"`   // 每张赞成票的权重
+	DownWeight float64 `mapstructure:"down_weight"` // 每张反对票的权重
+	HotDecay   int     `mapstructure:"hot_decay"`   // hot排序的时间衰减（秒）：晚发布这么久，需要多10倍的净票数才能排在同一位置
+}
+
+type CommunityRankingConfig struct {
+	CommunityID   uint64 `mapstructure:"community_id"` // 社区ID
+	RankingParams `mapstructure:",squash"`
+}
+
+type MailConfig struct {
+	Driver   string `mapstructure:"driver"`   // 发送方式：smtp/file/log
+	Host     string `mapstructure:"host"`     // SMTP主机地址
+	Port     int    `mapstructure:"port"`     // SMTP端口
+	Username string `mapstructure:"username"` // SMTP用户名
+	Password string `mapstructure:"password"` // SMTP密码
+	From     string `mapstructure:"from"`     // 发件人地址
+	Dir      string `mapstructure:"dir"`      // file方式下邮件保存目录
+}
+
+type OIDCProviderConfig struct {
+	Name         string   `mapstructure:"name"`          // 提供方名称，用于路由 /auth/oidc/:name
+	Issuer       string   `mapstructure:"issuer"`        // 签发方地址，据此读取 /.well-known/openid-configuration
+	ClientID     string   `mapstructure:"client_id"`     // 客户端ID
+	ClientSecret string   `mapstructure:"client_secret"` // 客户端密钥，公共客户端可留空
+	Scopes       []string `mapstructure:"scopes"`        // 申请的scope，缺省为openid email profile
+	RedirectURL  string   `mapstructure:"redirect_url"`  // 回调地址，缺省为 {site_url}/auth/oidc/{name}/callback
 }
 
 type LogConfig struct {